@@ -0,0 +1,219 @@
+// Command packctl runs pack calculations from the command line, without the API or a database.
+//
+// Usage:
+//
+//	packctl range -from 1 -to 10000 [-sizes 250,500,1000,2000,5000] [-format csv|ndjson]
+//	packctl recommend -orders orders.csv -max-sizes 5 [-candidates 250,500,750] [-top 5]
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"packify/internal/export"
+	"packify/pkg/calculator"
+)
+
+// defaultPackSizes are the pack sizes the database is seeded with
+const defaultPackSizes = "250,500,1000,2000,5000"
+
+// commands are the subcommands of packctl by name
+var commands = map[string]func(args []string) error{
+	"range":     runRange,
+	"recommend": runRecommend,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	if err := run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "packctl %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: packctl <command> [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Commands:")
+	fmt.Fprintln(os.Stderr, "  range      write the optimal packs of every order in a range of quantities")
+	fmt.Fprintln(os.Stderr, "  recommend  recommend the pack sizes that pack past orders best")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Run packctl <command> -h for the flags of a command.")
+}
+
+// optionFlags are the calculation option flags every command accepts, empty flags keep the defaults
+type optionFlags struct {
+	tieBreak     string
+	distinctRule string
+	fulfilment   string
+	tolerance    string
+}
+
+// register adds the option flags to a flag set
+func (f *optionFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.tieBreak, "tie-break", "", "tie-break policy, see calculator.TieBreaks")
+	fs.StringVar(&f.distinctRule, "distinct-rule", "", "fewest distinct pack sizes rule, see calculator.DistinctRules")
+	fs.StringVar(&f.fulfilment, "fulfilment", "", "over-ship or under-ship")
+	fs.StringVar(&f.tolerance, "tolerance", "", `excess tolerance in items or as a percentage, e.g. "50" or "5%"`)
+}
+
+// parseSizes parses comma separated pack sizes
+func parseSizes(value string) ([]int, error) {
+	var sizes []int
+	for _, field := range strings.Split(value, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("invalid pack size %q", field)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// options parses the option flags over the default options
+func (f *optionFlags) options() (calculator.Options, error) {
+	options := calculator.DefaultOptions()
+	var err error
+
+	if f.tieBreak != "" {
+		if options.TieBreak, err = calculator.ParseTieBreak(f.tieBreak); err != nil {
+			return calculator.Options{}, err
+		}
+	}
+	if f.distinctRule != "" {
+		if options.DistinctRule, err = calculator.ParseDistinctRule(f.distinctRule); err != nil {
+			return calculator.Options{}, err
+		}
+	}
+	if f.fulfilment != "" {
+		if options.Fulfilment, err = calculator.ParseFulfilment(f.fulfilment); err != nil {
+			return calculator.Options{}, err
+		}
+	}
+	if f.tolerance != "" {
+		if options.Tolerance, err = calculator.ParseTolerance(f.tolerance); err != nil {
+			return calculator.Options{}, err
+		}
+	}
+	return options, nil
+}
+
+// runRange writes a range table to stdout, like GET /api/calculate/range
+func runRange(args []string) error {
+	fs := flag.NewFlagSet("range", flag.ExitOnError)
+	var optionFlags optionFlags
+	optionFlags.register(fs)
+	sizes := fs.String("sizes", defaultPackSizes, "comma separated pack sizes")
+	from := fs.Int("from", 1, "smallest order quantity")
+	to := fs.Int("to", 0, "largest order quantity")
+	format := fs.String("format", export.FormatCSV, "output format, csv or ndjson")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	packSizes, err := parseSizes(*sizes)
+	if err != nil {
+		return err
+	}
+	options, err := optionFlags.options()
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	writer, err := export.NewRangeWriter(out, *format, packSizes)
+	if err != nil {
+		return err
+	}
+
+	itemsOrdered := *from
+	err = calculator.CalculateRange(*from, *to, packSizes, options, func(result calculator.PackResult) error {
+		err := writer.Write(itemsOrdered, result)
+		itemsOrdered++
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// runRecommend recommends pack sets for the orders of a CSV file, like POST /api/pack-sizes/recommend
+func runRecommend(args []string) error {
+	fs := flag.NewFlagSet("recommend", flag.ExitOnError)
+	var optionFlags optionFlags
+	optionFlags.register(fs)
+	ordersPath := fs.String("orders", "-", `CSV of order quantities, one per line or in an items_ordered or quantity column, "-" for stdin`)
+	maxSizes := fs.Int("max-sizes", 5, "largest number of pack sizes in a set")
+	candidates := fs.String("candidates", "", "comma separated sizes to choose from, empty for the most frequent order quantities")
+	top := fs.Int("top", 5, "number of sets to recommend")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	options, err := optionFlags.options()
+	if err != nil {
+		return err
+	}
+	var candidateSizes []int
+	if *candidates != "" {
+		if candidateSizes, err = parseSizes(*candidates); err != nil {
+			return err
+		}
+	}
+
+	var in io.Reader = os.Stdin
+	if *ordersPath != "-" {
+		file, err := os.Open(*ordersPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+	orders, err := export.ReadOrders(in)
+	if err != nil {
+		return err
+	}
+
+	recommendation, err := calculator.RecommendPackSets(orders, *maxSizes, candidateSizes, *top, options)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d orders, %d pack sets evaluated from %d candidate sizes\n",
+		recommendation.Orders, recommendation.Evaluated, len(recommendation.Candidates))
+	if recommendation.Truncated {
+		fmt.Println("The search stopped early to stay within its work budget, fewer orders or candidates search further")
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RANK\tPACK SIZES\tEXCESS\tSHORT\tPACKS\tEXACT ORDERS")
+	for i, set := range recommendation.Sets {
+		sizes := make([]string, len(set.PackSizes))
+		for j, size := range set.PackSizes {
+			sizes[j] = strconv.Itoa(size)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d\t%d\n",
+			i+1, strings.Join(sizes, ","), set.ExcessItems, set.ShortItems, set.TotalPacks, set.ExactOrders)
+	}
+	return w.Flush()
+}
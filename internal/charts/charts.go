@@ -0,0 +1,166 @@
+// Package charts renders simple SVG charts on the server, so pages can show them without a charting script.
+// Charts are styled by the chart-* classes of the stylesheet.
+package charts
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+)
+
+// Point is a labelled value of a chart
+type Point struct {
+	Label string
+	Value float64
+}
+
+// Chart dimensions in SVG user units, the SVG scales to the width of its container
+const (
+	width        = 640
+	height       = 240
+	marginLeft   = 64
+	marginRight  = 16
+	marginTop    = 16
+	marginBottom = 32
+	gridLines    = 4
+	maxXLabels   = 8
+	shareRow     = 28 // Height of a bar of a share chart
+	shareLabel   = 120
+)
+
+// Columns renders the points as a column chart, with values formatted by format, e.g. "%.0f"
+func Columns(title string, points []Point, format string) template.HTML {
+	var b strings.Builder
+	top := axes(&b, title, points, format)
+
+	band := plotWidth() / float64(max(len(points), 1))
+	for i, point := range points {
+		barHeight := point.Value / top * plotHeight()
+		fmt.Fprintf(&b, `<rect class="chart-bar" x="%.1f" y="%.1f" width="%.1f" height="%.1f"><title>%s: %s</title></rect>`,
+			marginLeft+float64(i)*band+band*0.15, marginTop+plotHeight()-barHeight, band*0.7, barHeight,
+			template.HTMLEscapeString(point.Label), fmt.Sprintf(format, point.Value))
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// Line renders the points as a line chart, with values formatted by format, e.g. "%.1f%%"
+func Line(title string, points []Point, format string) template.HTML {
+	var b strings.Builder
+	top := axes(&b, title, points, format)
+
+	band := plotWidth() / float64(max(len(points), 1))
+	coordinates := make([]string, len(points))
+	for i, point := range points {
+		coordinates[i] = fmt.Sprintf("%.1f,%.1f", marginLeft+(float64(i)+0.5)*band,
+			marginTop+plotHeight()-point.Value/top*plotHeight())
+	}
+	fmt.Fprintf(&b, `<polyline class="chart-line" points="%s"/>`, strings.Join(coordinates, " "))
+	for i, point := range points {
+		x, y, _ := strings.Cut(coordinates[i], ",")
+		fmt.Fprintf(&b, `<circle class="chart-point" cx="%s" cy="%s" r="3"><title>%s: %s</title></circle>`,
+			x, y, template.HTMLEscapeString(point.Label), fmt.Sprintf(format, point.Value))
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// Shares renders the points as horizontal bars labelled with their share of the total
+func Shares(title string, points []Point) template.HTML {
+	total := 0.0
+	for _, point := range points {
+		total += point.Value
+	}
+
+	chartHeight := marginTop + max(len(points), 1)*shareRow
+	var b strings.Builder
+	open(&b, title, chartHeight)
+	if total == 0 {
+		fmt.Fprintf(&b, `<text class="chart-label" x="%d" y="%d">No data</text>`, marginLeft, marginTop+shareRow/2)
+	}
+
+	barSpace := float64(width - shareLabel - marginRight - 96) // Room for the share after the bar
+	for i, point := range points {
+		if total == 0 {
+			break
+		}
+		share := point.Value / total
+		y := marginTop + i*shareRow
+		fmt.Fprintf(&b, `<text class="chart-label" x="%d" y="%d" text-anchor="end">%s</text>`,
+			shareLabel-8, y+shareRow/2+4, template.HTMLEscapeString(point.Label))
+		fmt.Fprintf(&b, `<rect class="chart-bar" x="%d" y="%d" width="%.1f" height="%d"><title>%s: %.0f</title></rect>`,
+			shareLabel, y+4, share*barSpace, shareRow-8, template.HTMLEscapeString(point.Label), point.Value)
+		fmt.Fprintf(&b, `<text class="chart-label" x="%.1f" y="%d">%.1f%% (%.0f)</text>`,
+			float64(shareLabel)+share*barSpace+6, y+shareRow/2+4, share*100, point.Value)
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// open starts an SVG of the chart width
+func open(b *strings.Builder, title string, chartHeight int) {
+	fmt.Fprintf(b, `<svg class="chart" viewBox="0 0 %d %d" role="img" aria-label="%s"><title>%s</title>`,
+		width, chartHeight, template.HTMLEscapeString(title), template.HTMLEscapeString(title))
+}
+
+// axes starts an SVG with the value grid and the labels of the points, and returns the value at the top of the grid
+func axes(b *strings.Builder, title string, points []Point, format string) float64 {
+	open(b, title, height)
+
+	largest := 0.0
+	for _, point := range points {
+		largest = max(largest, point.Value)
+	}
+	// Grid lines fall on round values, and on whole numbers when values are formatted without decimals
+	step := niceCeiling(largest / gridLines)
+	if strings.Contains(format, ".0f") {
+		step = max(step, 1)
+	}
+	top := step * gridLines
+
+	for i := range gridLines + 1 {
+		value := top * float64(i) / gridLines
+		y := marginTop + plotHeight() - plotHeight()*float64(i)/gridLines
+		fmt.Fprintf(b, `<line class="chart-grid" x1="%d" y1="%.1f" x2="%d" y2="%.1f"/>`,
+			marginLeft, y, width-marginRight, y)
+		fmt.Fprintf(b, `<text class="chart-label" x="%d" y="%.1f" text-anchor="end">%s</text>`,
+			marginLeft-6, y+4, template.HTMLEscapeString(fmt.Sprintf(format, value)))
+	}
+
+	// Label at most maxXLabels points so labels do not overlap
+	band := plotWidth() / float64(max(len(points), 1))
+	labelStep := max((len(points)+maxXLabels-1)/maxXLabels, 1)
+	for i := 0; i < len(points); i += labelStep {
+		fmt.Fprintf(b, `<text class="chart-label" x="%.1f" y="%d" text-anchor="middle">%s</text>`,
+			marginLeft+(float64(i)+0.5)*band, height-marginBottom/2+4, template.HTMLEscapeString(points[i].Label))
+	}
+	return top
+}
+
+// niceCeiling rounds a value up to 1, 2 or 5 times a power of ten
+func niceCeiling(value float64) float64 {
+	if value <= 0 {
+		return 0.25
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(value)))
+	for _, factor := range []float64{1, 2, 5, 10} {
+		if value <= factor*magnitude {
+			return factor * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// plotWidth is the width of the plot area between the value labels and the right margin
+func plotWidth() float64 {
+	return width - marginLeft - marginRight
+}
+
+// plotHeight is the height of the plot area between the top margin and the point labels
+func plotHeight() float64 {
+	return height - marginTop - marginBottom
+}
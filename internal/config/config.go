@@ -10,8 +10,9 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
+	Database   DatabaseConfig
+	Server     ServerConfig
+	Calculator CalculatorConfig
 }
 
 // DatabaseConfig holds database connection details
@@ -28,6 +29,16 @@ type ServerConfig struct {
 	Port int
 }
 
+// CalculatorConfig holds pack calculation settings
+type CalculatorConfig struct {
+	TieBreak     string // Default tie-break policy, see calculator.TieBreaks
+	DistinctRule string // Default position of the fewest distinct pack sizes rule, see calculator.DistinctRules
+	Fulfilment   string // Default fulfilment mode, see calculator.Fulfilments
+	Tolerance    string // Default excess tolerance in items or as a percentage, see calculator.ParseTolerance
+	Packaging    string // Default packaging hierarchy such as "case:10,pallet:40", empty for loose packs
+	Carrier      string // Default carrier profile shipments are planned for
+}
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig() *Config {
 	err := godotenv.Load()
@@ -49,6 +60,14 @@ func LoadConfig() *Config {
 		Server: ServerConfig{
 			Port: appPort,
 		},
+		Calculator: CalculatorConfig{
+			TieBreak:     getEnv("TIE_BREAK", "larger-packs"),
+			DistinctRule: getEnv("DISTINCT_RULE", "ignore"),
+			Fulfilment:   getEnv("FULFILMENT", "over-ship"),
+			Tolerance:    getEnv("TOLERANCE", "0"),
+			Packaging:    getEnv("PACKAGING", ""),
+			Carrier:      getEnv("CARRIER", "standard"),
+		},
 	}
 }
 
@@ -0,0 +1,65 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"packify/internal/models"
+)
+
+// Dashboard aggregates that can be exported as CSV
+const (
+	DatasetDaily      = "daily"
+	DatasetPacks      = "packs"
+	DatasetAlgorithms = "algorithms"
+)
+
+// ValidateDataset checks that a dashboard dataset exists, an empty dataset is the daily one
+func ValidateDataset(dataset string) error {
+	switch dataset {
+	case "", DatasetDaily, DatasetPacks, DatasetAlgorithms:
+		return nil
+	default:
+		return fmt.Errorf("unknown dataset %q, expected %s, %s or %s", dataset, DatasetDaily, DatasetPacks, DatasetAlgorithms)
+	}
+}
+
+// WriteDailyCalculations writes the calculations of every day as CSV
+func WriteDailyCalculations(w io.Writer, days []models.DailyCalculations) error {
+	rows := [][]string{{"day", "orders", "items_ordered", "excess_items", "short_items", "excess_percent"}}
+	for _, day := range days {
+		rows = append(rows, []string{
+			day.Day,
+			strconv.Itoa(day.Orders),
+			strconv.FormatFloat(day.ItemsOrdered, 'f', -1, 64),
+			strconv.Itoa(day.ExcessItems),
+			strconv.Itoa(day.ShortItems),
+			strconv.FormatFloat(day.ExcessPercent(), 'f', 4, 64),
+		})
+	}
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+// WritePackUsage writes the packs shipped of every size as CSV
+func WritePackUsage(w io.Writer, usage []models.PackUsage) error {
+	rows := [][]string{{"size", "packs", "orders"}}
+	for _, pack := range usage {
+		rows = append(rows, []string{
+			strconv.Itoa(pack.Size),
+			strconv.FormatFloat(pack.Packs, 'f', -1, 64),
+			strconv.Itoa(pack.Orders),
+		})
+	}
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+// WriteAlgorithmUsage writes the calculations run with every algorithm as CSV
+func WriteAlgorithmUsage(w io.Writer, usage []models.AlgorithmUsage) error {
+	rows := [][]string{{"algorithm", "orders"}}
+	for _, algorithm := range usage {
+		rows = append(rows, []string{algorithm.Algorithm, strconv.Itoa(algorithm.Orders)})
+	}
+	return csv.NewWriter(w).WriteAll(rows)
+}
@@ -0,0 +1,52 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ReadOrders reads order quantities from CSV, one order per line. With a header the quantities are read
+// from its items_ordered or quantity column, so range tables and order exports can be uploaded as they are;
+// without one from the first column.
+func ReadOrders(body io.Reader) ([]int, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid orders CSV: %v", err)
+	}
+
+	column, first := 0, 0
+	if len(records) > 0 {
+		if _, err := strconv.Atoi(records[0][0]); err != nil {
+			first = 1
+			column = -1
+			for i, name := range records[0] {
+				if name := strings.ToLower(strings.TrimSpace(name)); name == "items_ordered" || name == "quantity" {
+					column = i
+					break
+				}
+			}
+			if column < 0 {
+				return nil, fmt.Errorf("orders CSV header must have an items_ordered or quantity column")
+			}
+		}
+	}
+
+	var orders []int
+	for line, record := range records[first:] {
+		if column >= len(record) {
+			return nil, fmt.Errorf("missing quantity on line %d", line+first+1)
+		}
+		itemsOrdered, err := strconv.Atoi(strings.TrimSpace(record[column]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity %q on line %d", record[column], line+first+1)
+		}
+		orders = append(orders, itemsOrdered)
+	}
+	return orders, nil
+}
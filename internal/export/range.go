@@ -0,0 +1,140 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"packify/pkg/calculator"
+)
+
+// Formats a range table can be written in
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// RangeWriter writes the packs of one order per row
+type RangeWriter interface {
+	// Write writes the packs of an order
+	Write(itemsOrdered int, result calculator.PackResult) error
+	// Flush writes any buffered rows
+	Flush() error
+}
+
+// ContentType returns the MIME type of a format, an empty format is CSV
+func ContentType(format string) (string, error) {
+	switch format {
+	case "", FormatCSV:
+		return "text/csv; charset=utf-8", nil
+	case FormatNDJSON:
+		return "application/x-ndjson", nil
+	default:
+		return "", fmt.Errorf("unknown format %q, expected %s or %s", format, FormatCSV, FormatNDJSON)
+	}
+}
+
+// NewRangeWriter creates a writer for a format, an empty format is CSV. CSV has a column for the packs
+// of every size, largest first.
+func NewRangeWriter(w io.Writer, format string, packSizes []int) (RangeWriter, error) {
+	if _, err := ContentType(format); err != nil {
+		return nil, err
+	}
+
+	if format == FormatNDJSON {
+		buffered := bufio.NewWriter(w)
+		return &ndjsonRangeWriter{buffered: buffered, encoder: json.NewEncoder(buffered)}, nil
+	}
+	sizes := append([]int(nil), packSizes...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	return &csvRangeWriter{writer: csv.NewWriter(w), packSizes: sizes}, nil
+}
+
+// csvRangeWriter writes a header and a row per order
+type csvRangeWriter struct {
+	writer    *csv.Writer
+	packSizes []int
+	started   bool
+}
+
+func (w *csvRangeWriter) Write(itemsOrdered int, result calculator.PackResult) error {
+	if !w.started {
+		header := []string{"items_ordered", "total_packs", "total_items", "excess_items", "short_items", "distinct_pack_types"}
+		for _, size := range w.packSizes {
+			header = append(header, "packs_"+strconv.Itoa(size))
+		}
+		if err := w.writer.Write(header); err != nil {
+			return err
+		}
+		w.started = true
+	}
+
+	row := []string{
+		strconv.Itoa(itemsOrdered),
+		strconv.Itoa(result.TotalPacks),
+		strconv.Itoa(result.TotalItems),
+		strconv.Itoa(result.ExcessItems),
+		strconv.Itoa(result.ShortItems),
+		strconv.Itoa(result.DistinctPackTypes),
+	}
+	for _, size := range w.packSizes {
+		row = append(row, strconv.Itoa(result.PackCounts[size]))
+	}
+	return w.writer.Write(row)
+}
+
+func (w *csvRangeWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// ndjsonRangeWriter writes a JSON object per order and line
+type ndjsonRangeWriter struct {
+	buffered *bufio.Writer
+	encoder  *json.Encoder
+}
+
+// rangeRow is a row of a range table, formatted like a calculation of the API
+type rangeRow struct {
+	ItemsOrdered      int        `json:"itemsOrdered"`
+	Packs             []packInfo `json:"packs"`
+	TotalPacks        int        `json:"totalPacks"`
+	TotalItems        int        `json:"totalItems"`
+	ExcessItems       int        `json:"excessItems"`
+	ShortItems        int        `json:"shortItems"`
+	DistinctPackTypes int        `json:"distinctPackTypes"`
+}
+
+type packInfo struct {
+	Size  int `json:"size"`
+	Count int `json:"count"`
+}
+
+func (w *ndjsonRangeWriter) Write(itemsOrdered int, result calculator.PackResult) error {
+	row := rangeRow{
+		ItemsOrdered:      itemsOrdered,
+		Packs:             []packInfo{},
+		TotalPacks:        result.TotalPacks,
+		TotalItems:        result.TotalItems,
+		ExcessItems:       result.ExcessItems,
+		ShortItems:        result.ShortItems,
+		DistinctPackTypes: result.DistinctPackTypes,
+	}
+	sizes := make([]int, 0, len(result.PackCounts))
+	for size := range result.PackCounts {
+		sizes = append(sizes, size)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	for _, size := range sizes {
+		row.Packs = append(row.Packs, packInfo{Size: size, Count: result.PackCounts[size]})
+	}
+	return w.encoder.Encode(row)
+}
+
+func (w *ndjsonRangeWriter) Flush() error {
+	return w.buffered.Flush()
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+
+	"packify/internal/models"
+	"packify/pkg/calculator"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Number of next best packings returned when top is not given, and the most that can be requested
+const (
+	defaultAlternatives = 5
+	maxAlternatives     = 50
+)
+
+type AlternativesRequest struct {
+	ItemsOrdered int  `query:"items"`
+	Top          *int `query:"top"`
+	OptionsRequest
+}
+
+// AlternativesResponse Format alternatives
+type AlternativesResponse struct {
+	ItemsOrdered int                 `json:"itemsOrdered"`
+	Optimal      []CalculateResponse `json:"optimal"`
+	NextBest     []CalculateResponse `json:"nextBest"`
+	Truncated    bool                `json:"truncated"`
+}
+
+// CalculateAlternatives returns all optimal packings for an order plus the next best ones
+func (h *Handler) CalculateAlternatives(c echo.Context) error {
+	// Parse request
+
+	req := new(AlternativesRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+
+	// Validate request
+	if req.ItemsOrdered <= 0 {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Items ordered must be greater than 0"))
+	}
+
+	topK := defaultAlternatives
+	if req.Top != nil {
+		topK = *req.Top
+	}
+	if topK < 0 || topK > maxAlternatives {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Top must be between 0 and 50"))
+	}
+
+	options, err := h.resolveOptions(req.OptionsRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	alternatives, err := h.PackService.CalculateAlternatives(req.ItemsOrdered, topK, options)
+	if err != nil {
+		return c.JSON(calculationStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, AlternativesResponse{
+		ItemsOrdered: req.ItemsOrdered,
+		Optimal:      newCalculateResponses(alternatives.Optimal),
+		NextBest:     newCalculateResponses(alternatives.NextBest),
+		Truncated:    alternatives.Truncated,
+	})
+}
+
+// newCalculateResponses formats a list of packings
+func newCalculateResponses(results []calculator.PackResult) []CalculateResponse {
+	responses := []CalculateResponse{}
+	for i := range results {
+		responses = append(responses, newCalculateResponse(&results[i]))
+	}
+	return responses
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+
+	"packify/internal/models"
+	"packify/pkg/calculator"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AmendRequest struct {
+	// Packs is the original packing of the order, including packs already sealed
+	Packs []PackInfo `json:"packs"`
+	// ItemsOrdered is the amended quantity
+	ItemsOrdered int `json:"itemsOrdered"`
+	// Calculation options overriding the configured ones
+	OptionsRequest
+}
+
+// AmendmentResponse Format amendment
+type AmendmentResponse struct {
+	CalculateResponse
+	Keep      []PackInfo `json:"keep"`
+	Add       []PackInfo `json:"add"`
+	Remove    []PackInfo `json:"remove"`
+	Rework    int        `json:"rework"`    // Packs added plus packs removed
+	Truncated bool       `json:"truncated"` // Whether only the first optimal packings were compared
+}
+
+// newAmendmentResponse formats an amendment for the API
+func newAmendmentResponse(amendment *calculator.Amendment) AmendmentResponse {
+	return AmendmentResponse{
+		CalculateResponse: newCalculateResponse(&amendment.Result),
+		Keep:              orEmpty(newPackInfos(amendment.Keep)),
+		Add:               orEmpty(newPackInfos(amendment.Add)),
+		Remove:            orEmpty(newPackInfos(amendment.Remove)),
+		Rework:            amendment.Rework,
+		Truncated:         amendment.Truncated,
+	}
+}
+
+// orEmpty returns an empty list instead of nil, so it is formatted as [] rather than null
+func orEmpty(packs []PackInfo) []PackInfo {
+	if packs == nil {
+		return []PackInfo{}
+	}
+	return packs
+}
+
+// AmendPacks calculates the packs to add and remove when the quantity of a packed order changes
+func (h *Handler) AmendPacks(c echo.Context) error {
+	req := new(AmendRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+
+	if req.ItemsOrdered <= 0 {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Items ordered must be greater than 0"))
+	}
+	original := make(map[int]int, len(req.Packs))
+	for _, pack := range req.Packs {
+		if pack.Size <= 0 || pack.Count < 0 {
+			return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Packs must have a positive size and a count that is not negative"))
+		}
+		original[pack.Size] += pack.Count
+	}
+	options, err := h.resolveOptions(req.OptionsRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	amendment, err := h.PackService.AmendPacks(original, req.ItemsOrdered, options)
+	if err != nil {
+		return c.JSON(calculationStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, newAmendmentResponse(amendment))
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+
+	"packify/internal/models"
+	"packify/pkg/calculator"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AnalysisRequest struct {
+	// To is the largest order the packings are calculated for, 0 for the default range
+	To int `query:"to"`
+	OptionsRequest
+}
+
+// AnalysisResponse Format pack set analysis
+type AnalysisResponse struct {
+	PackSizes      []int       `json:"packSizes"`
+	GCD            int         `json:"gcd"`
+	LargestInexact int         `json:"largestInexact"` // Largest multiple of gcd that never packs exactly, 0 if none
+	ExactThreshold int         `json:"exactThreshold"` // Every multiple of gcd from here on packs exactly
+	InexactCount   int         `json:"inexactCount"`   // Multiples of gcd that never pack exactly
+	Range          int         `json:"range"`          // Orders from 1 to range were calculated
+	ExactOrders    int         `json:"exactOrders"`
+	Usage          []UsageInfo `json:"usage"`
+	UnusedSizes    []int       `json:"unusedSizes"`
+	MaxExcess      int         `json:"maxExcess"`
+	MaxExcessItems int         `json:"maxExcessItems"` // Smallest order with the worst excess
+	MeanExcess     float64     `json:"meanExcess"`
+}
+
+// UsageInfo is how many orders of the range use a pack size
+type UsageInfo struct {
+	Size   int `json:"size"`
+	Orders int `json:"orders"`
+}
+
+// newAnalysisResponse formats a pack set analysis for the API
+func newAnalysisResponse(analysis *calculator.PackSetAnalysis) AnalysisResponse {
+	response := AnalysisResponse{
+		PackSizes:      analysis.PackSizes,
+		GCD:            analysis.GCD,
+		LargestInexact: analysis.LargestInexact,
+		ExactThreshold: analysis.ExactThreshold,
+		InexactCount:   analysis.InexactCount,
+		Range:          analysis.Range,
+		ExactOrders:    analysis.ExactOrders,
+		Usage:          make([]UsageInfo, 0, len(analysis.PackSizes)),
+		UnusedSizes:    analysis.UnusedSizes,
+		MaxExcess:      analysis.MaxExcess,
+		MaxExcessItems: analysis.MaxExcessItems,
+		MeanExcess:     analysis.MeanExcess,
+	}
+	for _, size := range analysis.PackSizes {
+		response.Usage = append(response.Usage, UsageInfo{Size: size, Orders: analysis.Usage[size]})
+	}
+	if response.UnusedSizes == nil {
+		response.UnusedSizes = []int{}
+	}
+	return response
+}
+
+// AnalyzePackSizes reports the exactness of the pack sizes and how orders up to a range use them
+func (h *Handler) AnalyzePackSizes(c echo.Context) error {
+	req := new(AnalysisRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+
+	if req.To < 0 {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("To must not be negative"))
+	}
+	options, err := h.resolveOptions(req.OptionsRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	analysis, err := h.PackService.AnalyzePackSizes(req.To, options)
+	if err != nil {
+		return c.JSON(calculationStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, newAnalysisResponse(analysis))
+}
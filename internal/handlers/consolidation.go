@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"packify/internal/models"
+	"packify/pkg/calculator"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxConsolidationOrders is the most orders one consolidation request can compare
+const maxConsolidationOrders = 1000
+
+type ConsolidateRequest struct {
+	Orders []OrderRequest `json:"orders"`
+	// Calculation options overriding the configured ones
+	OptionsRequest
+}
+
+// OrderRequest is one order to consolidate
+type OrderRequest struct {
+	ID           string `json:"id"`
+	Key          string `json:"key"` // Orders with the same key may ship together, such as a customer and a day
+	ItemsOrdered int    `json:"itemsOrdered"`
+}
+
+// ConsolidationResponse Format consolidation
+type ConsolidationResponse struct {
+	Groups      []OrderGroupInfo `json:"groups"`
+	ExcessSaved int              `json:"excessSaved"`
+	PacksSaved  int              `json:"packsSaved"`
+}
+
+// OrderGroupInfo compares shipping the orders with one key separately and together
+type OrderGroupInfo struct {
+	Key          string              `json:"key"`
+	Orders       []string            `json:"orders"`
+	ItemsOrdered int                 `json:"itemsOrdered"`
+	Consolidate  bool                `json:"consolidate"` // Whether to ship the orders together
+	Separate     []CalculateResponse `json:"separate"`    // One per order, empty if an order cannot be packed on its own
+	Combined     *CalculateResponse  `json:"combined"`    // Null if the orders cannot be packed together
+	ExcessSaved  int                 `json:"excessSaved"`
+	PacksSaved   int                 `json:"packsSaved"`
+}
+
+// newConsolidationResponse formats a consolidation for the API
+func newConsolidationResponse(consolidation *calculator.Consolidation) ConsolidationResponse {
+	response := ConsolidationResponse{
+		Groups:      []OrderGroupInfo{},
+		ExcessSaved: consolidation.ExcessSaved,
+		PacksSaved:  consolidation.PacksSaved,
+	}
+	for i := range consolidation.Groups {
+		group := &consolidation.Groups[i]
+		info := OrderGroupInfo{
+			Key:          group.Key,
+			Orders:       group.Orders,
+			ItemsOrdered: group.ItemsOrdered,
+			Consolidate:  group.Consolidate,
+			Separate:     newCalculateResponses(group.Separate),
+			ExcessSaved:  group.ExcessSaved,
+			PacksSaved:   group.PacksSaved,
+		}
+		if group.Combined.TotalPacks > 0 {
+			combined := newCalculateResponse(&group.Combined)
+			info.Combined = &combined
+		}
+		response.Groups = append(response.Groups, info)
+	}
+	return response
+}
+
+// validateOrders checks that orders are identified once and ask for items
+func validateOrders(orders []OrderRequest) error {
+	if len(orders) == 0 {
+		return errors.New("At least one order is required")
+	}
+	if len(orders) > maxConsolidationOrders {
+		return fmt.Errorf("At most %d orders can be consolidated", maxConsolidationOrders)
+	}
+
+	seen := make(map[string]bool, len(orders))
+	for _, order := range orders {
+		if order.ID == "" {
+			return errors.New("Order id is required")
+		}
+		if seen[order.ID] {
+			return fmt.Errorf("Order %s is listed more than once", order.ID)
+		}
+		seen[order.ID] = true
+		if order.ItemsOrdered <= 0 {
+			return fmt.Errorf("Items ordered of order %s must be greater than 0", order.ID)
+		}
+	}
+	return nil
+}
+
+// ConsolidateOrders recommends which orders with the same key to ship together and what that saves
+func (h *Handler) ConsolidateOrders(c echo.Context) error {
+	req := new(ConsolidateRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+
+	if err := validateOrders(req.Orders); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+	options, err := h.resolveOptions(req.OptionsRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	orders := make([]calculator.Order, len(req.Orders))
+	for i, order := range req.Orders {
+		orders[i] = calculator.Order{ID: order.ID, Key: order.Key, Items: order.ItemsOrdered}
+	}
+	consolidation, err := h.PackService.ConsolidateOrders(orders, options)
+	if err != nil {
+		return c.JSON(calculationStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, newConsolidationResponse(consolidation))
+}
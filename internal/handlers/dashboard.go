@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"packify/internal/charts"
+	"packify/internal/export"
+	"packify/internal/models"
+	"packify/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultDashboardDays is the number of days up to today a dashboard covers when a request gives no range
+const defaultDashboardDays = 30
+
+// dateLayout is the layout of the dates of dashboard requests, as sent by date inputs
+const dateLayout = "2006-01-02"
+
+type DashboardRequest struct {
+	// From is the first day, YYYY-MM-DD, empty for 30 days before the last one
+	From string `query:"from"`
+	// To is the last day, included, YYYY-MM-DD, empty for today
+	To string `query:"to"`
+	// Dataset is the aggregate exported as CSV: daily, packs or algorithms, empty for daily
+	Dataset string `query:"dataset"`
+}
+
+// DashboardResponse Format dashboard
+type DashboardResponse struct {
+	From          string               `json:"from"`
+	To            string               `json:"to"`
+	Orders        int                  `json:"orders"`
+	ItemsOrdered  float64              `json:"itemsOrdered"`
+	ExcessItems   int                  `json:"excessItems"`
+	ShortItems    int                  `json:"shortItems"`
+	ExcessPercent float64              `json:"excessPercent"` // Excess items as a percentage of the items ordered
+	Days          []DailyInfo          `json:"days"`          // Every day of the range
+	PackUsage     []PackUsageInfo      `json:"packUsage"`     // Largest size first
+	Algorithms    []AlgorithmUsageInfo `json:"algorithms"`    // Most used first
+}
+
+// DailyInfo is the calculations of one day
+type DailyInfo struct {
+	Day           string  `json:"day"`
+	Orders        int     `json:"orders"`
+	ItemsOrdered  float64 `json:"itemsOrdered"`
+	ExcessItems   int     `json:"excessItems"`
+	ShortItems    int     `json:"shortItems"`
+	ExcessPercent float64 `json:"excessPercent"`
+}
+
+// PackUsageInfo is the packs of one size shipped by the calculations
+type PackUsageInfo struct {
+	Size   int     `json:"size"`
+	Packs  float64 `json:"packs"`
+	Orders int     `json:"orders"` // Calculations shipping the size
+}
+
+// AlgorithmUsageInfo is the number of calculations run with an algorithm
+type AlgorithmUsageInfo struct {
+	Algorithm string `json:"algorithm"`
+	Orders    int    `json:"orders"`
+}
+
+// DashboardCharts are the SVG charts of a dashboard
+type DashboardCharts struct {
+	Orders        template.HTML
+	ExcessItems   template.HTML
+	ExcessPercent template.HTML
+	PackUsage     template.HTML
+	Algorithms    template.HTML
+}
+
+// newDashboardResponse formats a dashboard for the API
+func newDashboardResponse(dashboard *services.Dashboard) DashboardResponse {
+	response := DashboardResponse{
+		From:          dashboard.From.Format(dateLayout),
+		To:            dashboard.To.Format(dateLayout),
+		Orders:        dashboard.Orders,
+		ItemsOrdered:  dashboard.ItemsOrdered,
+		ExcessItems:   dashboard.ExcessItems,
+		ShortItems:    dashboard.ShortItems,
+		ExcessPercent: dashboard.ExcessPercent(),
+		Days:          make([]DailyInfo, len(dashboard.Days)),
+		PackUsage:     make([]PackUsageInfo, len(dashboard.PackUsage)),
+		Algorithms:    make([]AlgorithmUsageInfo, len(dashboard.Algorithms)),
+	}
+	for i, day := range dashboard.Days {
+		response.Days[i] = DailyInfo{
+			Day:           day.Day,
+			Orders:        day.Orders,
+			ItemsOrdered:  day.ItemsOrdered,
+			ExcessItems:   day.ExcessItems,
+			ShortItems:    day.ShortItems,
+			ExcessPercent: day.ExcessPercent(),
+		}
+	}
+	for i, pack := range dashboard.PackUsage {
+		response.PackUsage[i] = PackUsageInfo{Size: pack.Size, Packs: pack.Packs, Orders: pack.Orders}
+	}
+	for i, algorithm := range dashboard.Algorithms {
+		response.Algorithms[i] = AlgorithmUsageInfo{Algorithm: algorithm.Algorithm, Orders: algorithm.Orders}
+	}
+	return response
+}
+
+// newDashboardCharts renders the charts of a dashboard
+func newDashboardCharts(dashboard *services.Dashboard) DashboardCharts {
+	orders := make([]charts.Point, len(dashboard.Days))
+	excessItems := make([]charts.Point, len(dashboard.Days))
+	excessPercent := make([]charts.Point, len(dashboard.Days))
+	for i, day := range dashboard.Days {
+		orders[i] = charts.Point{Label: day.Day, Value: float64(day.Orders)}
+		excessItems[i] = charts.Point{Label: day.Day, Value: float64(day.ExcessItems)}
+		excessPercent[i] = charts.Point{Label: day.Day, Value: day.ExcessPercent()}
+	}
+	packUsage := make([]charts.Point, len(dashboard.PackUsage))
+	for i, pack := range dashboard.PackUsage {
+		packUsage[i] = charts.Point{Label: strconv.Itoa(pack.Size), Value: pack.Packs}
+	}
+	algorithms := make([]charts.Point, len(dashboard.Algorithms))
+	for i, algorithm := range dashboard.Algorithms {
+		algorithms[i] = charts.Point{Label: algorithm.Algorithm, Value: float64(algorithm.Orders)}
+	}
+
+	return DashboardCharts{
+		Orders:        charts.Columns("Orders per day", orders, "%.0f"),
+		ExcessItems:   charts.Columns("Excess items per day", excessItems, "%.0f"),
+		ExcessPercent: charts.Line("Excess items as a percentage of items ordered", excessPercent, "%.1f%%"),
+		PackUsage:     charts.Shares("Packs shipped by size", packUsage),
+		Algorithms:    charts.Shares("Calculations by algorithm", algorithms),
+	}
+}
+
+// dashboardStatus maps a dashboard error to an HTTP status
+func dashboardStatus(err error) int {
+	if errors.Is(err, services.ErrInvalidDateRange) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// dashboard parses the date range of a request and aggregates the calculations recorded over it,
+// returning the status to report on failure
+func (h *Handler) dashboard(req *DashboardRequest) (*services.Dashboard, int, error) {
+	to := time.Now().UTC()
+	if req.To != "" {
+		var err error
+		if to, err = time.ParseInLocation(dateLayout, req.To, time.UTC); err != nil {
+			return nil, http.StatusBadRequest, errors.New("To must be a date such as 2024-01-31")
+		}
+	}
+	from := to.AddDate(0, 0, 1-defaultDashboardDays)
+	if req.From != "" {
+		var err error
+		if from, err = time.ParseInLocation(dateLayout, req.From, time.UTC); err != nil {
+			return nil, http.StatusBadRequest, errors.New("From must be a date such as 2024-01-01")
+		}
+	}
+
+	dashboard, err := h.PackService.GetDashboard(from, to)
+	if err != nil {
+		return nil, dashboardStatus(err), err
+	}
+	return dashboard, http.StatusOK, nil
+}
+
+// GetDashboard returns the aggregates of the calculations recorded over a range of days
+func (h *Handler) GetDashboard(c echo.Context) error {
+	req := new(DashboardRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+
+	dashboard, status, err := h.dashboard(req)
+	if err != nil {
+		return c.JSON(status, models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, newDashboardResponse(dashboard))
+}
+
+// ExportDashboard downloads an aggregate of the calculations recorded over a range of days as CSV
+func (h *Handler) ExportDashboard(c echo.Context) error {
+	req := new(DashboardRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+	if err := export.ValidateDataset(req.Dataset); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+	if req.Dataset == "" {
+		req.Dataset = export.DatasetDaily
+	}
+
+	dashboard, status, err := h.dashboard(req)
+	if err != nil {
+		return c.JSON(status, models.NewErrorResponse(err.Error()))
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="calculations-%s-%s-%s.csv"`,
+		req.Dataset, dashboard.From.Format(dateLayout), dashboard.To.Format(dateLayout)))
+	res.WriteHeader(http.StatusOK)
+
+	switch req.Dataset {
+	case export.DatasetPacks:
+		return export.WritePackUsage(res, dashboard.PackUsage)
+	case export.DatasetAlgorithms:
+		return export.WriteAlgorithmUsage(res, dashboard.Algorithms)
+	default:
+		return export.WriteDailyCalculations(res, dashboard.Days)
+	}
+}
+
+// DashboardPage renders the dashboard of recorded calculations
+func (h *Handler) DashboardPage(c echo.Context) error {
+	return h.renderDashboard(c, "dashboard.html")
+}
+
+// DashboardPartial renders the charts of the dashboard for the date range of the filter form
+func (h *Handler) DashboardPartial(c echo.Context) error {
+	return h.renderDashboard(c, "dashboard_charts.html")
+}
+
+// renderDashboard renders the dashboard page or its charts partial
+func (h *Handler) renderDashboard(c echo.Context, name string) error {
+	req := new(DashboardRequest)
+	if err := c.Bind(req); err != nil {
+		return c.Render(http.StatusBadRequest, "dashboard_charts.html", map[string]interface{}{
+			"Error": "Invalid request",
+		})
+	}
+
+	dashboard, status, err := h.dashboard(req)
+	if err != nil {
+		return c.Render(status, "dashboard_charts.html", map[string]interface{}{
+			"Error": err.Error(),
+		})
+	}
+
+	return c.Render(http.StatusOK, name, map[string]interface{}{
+		"Title":     "Dashboard",
+		"From":      dashboard.From.Format(dateLayout),
+		"To":        dashboard.To.Format(dateLayout),
+		"Dashboard": dashboard,
+		"Charts":    newDashboardCharts(dashboard),
+	})
+}
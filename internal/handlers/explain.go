@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"packify/pkg/calculator"
+)
+
+// ExplanationResponse Format explanation
+type ExplanationResponse struct {
+	Algorithm              string            `json:"algorithm"`
+	AlgorithmReason        string            `json:"algorithmReason"`
+	Candidates             []CandidateInfo   `json:"candidates"`
+	RejectedByRule2        []AlternativeInfo `json:"rejectedByRule2"`
+	RejectedByRule3        []AlternativeInfo `json:"rejectedByRule3"`
+	RejectedByDistinctRule []AlternativeInfo `json:"rejectedByDistinctRule,omitempty"`
+	Steps                  []StepInfo        `json:"steps"`
+}
+
+// CandidateInfo is a total near the order size that can be packed exactly
+type CandidateInfo struct {
+	TotalItems  int  `json:"totalItems"`
+	ExcessItems int  `json:"excessItems"`
+	ShortItems  int  `json:"shortItems"`
+	MinPacks    int  `json:"minPacks"`
+	Chosen      bool `json:"chosen"`
+}
+
+// AlternativeInfo is a packing that was rejected and why
+type AlternativeInfo struct {
+	Packs             []PackInfo `json:"packs"`
+	TotalPacks        int        `json:"totalPacks"`
+	TotalItems        int        `json:"totalItems"`
+	ExcessItems       int        `json:"excessItems"`
+	ShortItems        int        `json:"shortItems"`
+	DistinctPackTypes int        `json:"distinctPackTypes"`
+	Reason            string     `json:"reason"`
+}
+
+// StepInfo is a single step of the calculation
+type StepInfo struct {
+	Phase       string `json:"phase"`
+	PackSize    int    `json:"packSize,omitempty"`
+	Count       int    `json:"count,omitempty"`
+	Remaining   int    `json:"remaining"`
+	Description string `json:"description"`
+}
+
+// newExplanationResponse formats an explanation for the API and templates
+func newExplanationResponse(explanation *calculator.Explanation) *ExplanationResponse {
+	response := &ExplanationResponse{
+		Algorithm:       explanation.Algorithm,
+		AlgorithmReason: explanation.AlgorithmReason,
+		RejectedByRule2: newAlternativeInfos(explanation.RejectedByRule2),
+		RejectedByRule3: newAlternativeInfos(explanation.RejectedByRule3),
+	}
+	if len(explanation.RejectedByDistinctRule) > 0 {
+		response.RejectedByDistinctRule = newAlternativeInfos(explanation.RejectedByDistinctRule)
+	}
+
+	for _, candidate := range explanation.Candidates {
+		response.Candidates = append(response.Candidates, CandidateInfo{
+			TotalItems:  candidate.TotalItems,
+			ExcessItems: candidate.ExcessItems,
+			ShortItems:  candidate.ShortItems,
+			MinPacks:    candidate.MinPacks,
+			Chosen:      candidate.Chosen,
+		})
+	}
+
+	for _, step := range explanation.Steps {
+		response.Steps = append(response.Steps, StepInfo{
+			Phase:       step.Phase,
+			PackSize:    step.PackSize,
+			Count:       step.Count,
+			Remaining:   step.Remaining,
+			Description: step.Description,
+		})
+	}
+
+	return response
+}
+
+// newAlternativeInfos formats rejected packings
+func newAlternativeInfos(alternatives []calculator.Alternative) []AlternativeInfo {
+	infos := []AlternativeInfo{}
+	for _, alternative := range alternatives {
+		infos = append(infos, AlternativeInfo{
+			Packs:             newPackInfos(alternative.PackCounts),
+			TotalPacks:        alternative.TotalPacks,
+			TotalItems:        alternative.TotalItems,
+			ExcessItems:       alternative.ExcessItems,
+			ShortItems:        alternative.ShortItems,
+			DistinctPackTypes: alternative.DistinctPackTypes,
+			Reason:            alternative.Reason,
+		})
+	}
+	return infos
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"packify/internal/models"
+	"packify/pkg/calculator"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ForecastRequest struct {
+	// Method is moving-average or exponential-smoothing, empty for the moving average
+	Method string `query:"method"`
+	// Window is the days the moving average covers, 0 for 28
+	Window int `query:"window"`
+	// Alpha is the weight of the latest day in exponential smoothing, 0 for 0.3
+	Alpha float64 `query:"alpha"`
+	// ServiceFactor is the standard deviations of consumption held as safety stock, empty for 1.65
+	ServiceFactor string `query:"serviceFactor"`
+}
+
+// ForecastResponse Format forecast
+type ForecastResponse struct {
+	Method      string             `json:"method"`
+	HistoryDays int                `json:"historyDays"` // Most past days a forecast uses
+	Forecasts   []PackForecastInfo `json:"forecasts"`   // Smallest size first
+}
+
+// PackForecastInfo is the forecast consumption of a pack size and its reorder point
+type PackForecastInfo struct {
+	Size             int      `json:"size"`
+	DailyConsumption float64  `json:"dailyConsumption"`
+	Deviation        float64  `json:"deviation"`
+	Days             int      `json:"days"` // Past days the forecast is based on
+	StockOnHand      int      `json:"stockOnHand"`
+	LeadTimeDays     int      `json:"leadTimeDays"`
+	SafetyStock      int      `json:"safetyStock"`
+	ReorderPoint     int      `json:"reorderPoint"`
+	DaysOfStock      *float64 `json:"daysOfStock"` // null when the size is not consumed
+	Reorder          bool     `json:"reorder"`
+}
+
+type SetPackStockRequest struct {
+	StockOnHand  int     `json:"stockOnHand"`
+	LeadTimeDays int     `json:"leadTimeDays"`
+	Shelf        *string `json:"shelf"` // Omitted or null keeps the current shelf
+}
+
+// resolveForecastOptions applies the parameters of a request over the default forecast options
+func resolveForecastOptions(req *ForecastRequest) (calculator.ForecastOptions, error) {
+	options := calculator.DefaultForecastOptions()
+	var err error
+	if options.Method, err = calculator.ParseForecastMethod(req.Method); err != nil {
+		return calculator.ForecastOptions{}, err
+	}
+	if req.Window < 0 {
+		return calculator.ForecastOptions{}, errors.New("Window must be at least 1 day")
+	}
+	if req.Window != 0 {
+		options.Window = req.Window
+	}
+	if req.Alpha < 0 || req.Alpha > 1 {
+		return calculator.ForecastOptions{}, errors.New("Alpha must be greater than 0 and at most 1")
+	}
+	if req.Alpha != 0 {
+		options.Alpha = req.Alpha
+	}
+	if req.ServiceFactor != "" {
+		options.ServiceFactor, err = strconv.ParseFloat(req.ServiceFactor, 64)
+		if err != nil || options.ServiceFactor < 0 || math.IsNaN(options.ServiceFactor) || math.IsInf(options.ServiceFactor, 0) {
+			return calculator.ForecastOptions{}, fmt.Errorf("Service factor must be a number of at least 0, got %q", req.ServiceFactor)
+		}
+	}
+	return options, nil
+}
+
+// newPackForecastInfo formats a forecast for the API
+func newPackForecastInfo(forecast calculator.Forecast) PackForecastInfo {
+	info := PackForecastInfo{
+		Size:             forecast.Size,
+		DailyConsumption: forecast.DailyConsumption,
+		Deviation:        forecast.Deviation,
+		Days:             forecast.Days,
+		StockOnHand:      forecast.StockOnHand,
+		LeadTimeDays:     forecast.LeadTimeDays,
+		SafetyStock:      forecast.SafetyStock,
+		ReorderPoint:     forecast.ReorderPoint,
+		Reorder:          forecast.Reorder,
+	}
+	if !math.IsInf(forecast.DaysOfStock, 1) {
+		info.DaysOfStock = &forecast.DaysOfStock
+	}
+	return info
+}
+
+// ForecastPackConsumption forecasts the daily consumption of every pack size and its reorder point
+func (h *Handler) ForecastPackConsumption(c echo.Context) error {
+	req := new(ForecastRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+
+	options, err := resolveForecastOptions(req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	forecasts, err := h.PackService.ForecastPackConsumption(options)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.NewErrorResponse(err.Error()))
+	}
+
+	response := ForecastResponse{
+		Method:      string(options.Method),
+		HistoryDays: options.HistoryDays(),
+		Forecasts:   make([]PackForecastInfo, len(forecasts)),
+	}
+	for i, forecast := range forecasts {
+		response.Forecasts[i] = newPackForecastInfo(forecast)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// SetPackStock sets the stock on hand, supplier lead time and shelf of a pack size
+func (h *Handler) SetPackStock(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid ID"))
+	}
+
+	req := new(SetPackStockRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+	if req.StockOnHand < 0 || req.LeadTimeDays < 0 {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Stock on hand and lead time must not be negative"))
+	}
+
+	if err := h.PackService.SetPackStock(uint(id), req.StockOnHand, req.LeadTimeDays, req.Shelf); err != nil {
+		return c.JSON(reservationStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, models.NewSuccessResponse("Pack stock updated successfully"))
+}
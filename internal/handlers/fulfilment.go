@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"packify/internal/models"
+	"packify/internal/services"
+	"packify/pkg/calculator"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PlanFulfilmentRequest struct {
+	ItemsOrdered int `json:"itemsOrdered"`
+	// Calculation options overriding the configured ones
+	OptionsRequest
+}
+
+// FulfilmentResponse Format fulfilment plan
+type FulfilmentResponse struct {
+	Shipments []WarehouseShipment `json:"shipments"` // One per warehouse that ships
+	Total     CalculateResponse   `json:"total"`     // All packs shipped
+	Split     bool                `json:"split"`     // Whether more than one warehouse ships
+}
+
+// WarehouseShipment is the part of an order a warehouse ships
+type WarehouseShipment struct {
+	Warehouse string `json:"warehouse"`
+	CalculateResponse
+}
+
+// WarehouseInfo is a warehouse and the packs it holds
+type WarehouseInfo struct {
+	Name  string      `json:"name"`
+	Stock []StockInfo `json:"stock"`
+}
+
+// StockInfo is the number of packs of a size in stock
+type StockInfo struct {
+	Size     int `json:"size"`
+	Quantity int `json:"quantity"`
+}
+
+// newFulfilmentResponse formats a fulfilment plan for the API
+func newFulfilmentResponse(allocation *calculator.Allocation) FulfilmentResponse {
+	response := FulfilmentResponse{
+		Shipments: []WarehouseShipment{},
+		Total:     newCalculateResponse(&allocation.Total),
+		Split:     allocation.Split,
+	}
+	for i := range allocation.Shipments {
+		shipment := &allocation.Shipments[i]
+		response.Shipments = append(response.Shipments, WarehouseShipment{
+			Warehouse:         shipment.Location,
+			CalculateResponse: newCalculateResponse(&shipment.Result),
+		})
+	}
+	return response
+}
+
+// newWarehouseStock converts stock from the API
+func newWarehouseStock(stock []StockInfo) []models.WarehouseStock {
+	items := make([]models.WarehouseStock, len(stock))
+	for i, item := range stock {
+		items[i] = models.WarehouseStock{Size: item.Size, Quantity: item.Quantity}
+	}
+	return items
+}
+
+// fulfilmentStatus returns the HTTP status for an error of a fulfilment plan
+func fulfilmentStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidStock):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrUnknownWarehouse):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrNoWarehouses):
+		return http.StatusUnprocessableEntity
+	default:
+		return calculationStatus(err)
+	}
+}
+
+// PlanFulfilment decides which warehouses ship an order and the packs each of them ships
+func (h *Handler) PlanFulfilment(c echo.Context) error {
+	req := new(PlanFulfilmentRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+
+	if req.ItemsOrdered <= 0 {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Items ordered must be greater than 0"))
+	}
+	options, err := h.resolveOptions(req.OptionsRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	allocation, err := h.PackService.PlanFulfilment(req.ItemsOrdered, options)
+	if err != nil {
+		return c.JSON(fulfilmentStatus(err), models.NewErrorResponse(err.Error()))
+	}
+	return c.JSON(http.StatusOK, newFulfilmentResponse(allocation))
+}
+
+// GetWarehouses returns all warehouses and their stock
+func (h *Handler) GetWarehouses(c echo.Context) error {
+	warehouses, err := h.PackService.GetWarehouses()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.NewErrorResponse(err.Error()))
+	}
+
+	infos := make([]WarehouseInfo, len(warehouses))
+	for i, warehouse := range warehouses {
+		infos[i] = WarehouseInfo{Name: warehouse.Name, Stock: []StockInfo{}}
+		for _, stock := range warehouse.Stock {
+			infos[i].Stock = append(infos[i].Stock, StockInfo{Size: stock.Size, Quantity: stock.Quantity})
+		}
+	}
+	return c.JSON(http.StatusOK, infos)
+}
+
+// AddWarehouse adds a new warehouse with its stock
+func (h *Handler) AddWarehouse(c echo.Context) error {
+	req := new(WarehouseInfo)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+
+	if req.Name == "" {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Warehouse name is required"))
+	}
+
+	warehouse := models.Warehouse{Name: req.Name, Stock: newWarehouseStock(req.Stock)}
+	if err := h.PackService.AddWarehouse(warehouse); err != nil {
+		return c.JSON(fulfilmentStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusCreated, models.NewSuccessResponse("Warehouse added successfully"))
+}
+
+// SetStock replaces the stock of a warehouse
+func (h *Handler) SetStock(c echo.Context) error {
+	var req []StockInfo
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+
+	if err := h.PackService.SetStock(c.Param("name"), newWarehouseStock(req)); err != nil {
+		return c.JSON(fulfilmentStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, models.NewSuccessResponse("Stock updated successfully"))
+}
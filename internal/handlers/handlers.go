@@ -1,14 +1,20 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
+	"math"
+	"math/big"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 
 	"packify/internal/models"
 	"packify/internal/services"
+	"packify/pkg/calculator"
 
 	"github.com/labstack/echo/v4"
 )
@@ -26,13 +32,22 @@ func (t *TemplateRenderer) Render(w io.Writer, name string, data interface{}, c
 		"pack_sizes.html":         "content",
 		"calculation_result.html": "calculation_result",
 		"pack_sizes_table.html":   "pack_sizes_table",
+		"shipments.html":          "content",
+		"shipment_plan.html":      "shipment_plan",
+		"simulations.html":        "content",
+		"simulation_result.html":  "simulation_result",
+		"dashboard.html":          "content",
+		"dashboard_charts.html":   "dashboard_charts",
+		"orders.html":             "content",
+		"orders_table.html":       "orders_table",
 	}
 
 	// If this is a page template, render the content template directly
 	if contentTemplate, ok := contentTemplateMap[name]; ok {
 		// For partial templates, render them directly
 		//TODO make this more generic in case we add more partials
-		if name == "calculation_result.html" || name == "pack_sizes_table.html" {
+		if name == "calculation_result.html" || name == "pack_sizes_table.html" || name == "shipment_plan.html" ||
+			name == "simulation_result.html" || name == "dashboard_charts.html" || name == "orders_table.html" {
 			return t.templates.ExecuteTemplate(w, contentTemplate, data)
 		}
 
@@ -50,14 +65,8 @@ func (t *TemplateRenderer) Render(w io.Writer, name string, data interface{}, c
 			return err
 		}
 
-		funcMap := template.FuncMap{
-			"multiply": func(a, b int) int {
-				return a * b
-			},
-		}
-
 		// Parse any partials that might be needed
-		_, err = tmpl.Funcs(funcMap).ParseGlob("templates/partials/*.html")
+		_, err = tmpl.Funcs(templateFuncs).ParseGlob("templates/partials/*.html")
 		if err != nil {
 			return err
 		}
@@ -70,17 +79,38 @@ func (t *TemplateRenderer) Render(w io.Writer, name string, data interface{}, c
 	return t.templates.ExecuteTemplate(w, name, data)
 }
 
-// NewTemplateRenderer creates a new template renderer
-func NewTemplateRenderer() (*TemplateRenderer, error) {
-	// Define template functions
-	funcMap := template.FuncMap{
-		"multiply": func(a, b int) int {
-			return a * b
-		},
+// templateFuncs are the functions available to all templates
+var templateFuncs = template.FuncMap{
+	// multiply works on ints and big.Ints so it can render arbitrary-precision results
+	"multiply": func(a, b interface{}) *big.Int {
+		return new(big.Int).Mul(toBigInt(a), toBigInt(b))
+	},
+	// money formats an amount in cents, e.g. 1234 as 12.34
+	"money": func(cents int) string {
+		sign := ""
+		if cents < 0 {
+			sign, cents = "-", -cents
+		}
+		return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+	},
+}
+
+// toBigInt converts a template value to a big.Int
+func toBigInt(v interface{}) *big.Int {
+	switch n := v.(type) {
+	case int:
+		return big.NewInt(int64(n))
+	case *big.Int:
+		return n
+	default:
+		return new(big.Int)
 	}
+}
 
+// NewTemplateRenderer creates a new template renderer
+func NewTemplateRenderer() (*TemplateRenderer, error) {
 	// Parse templates
-	tmpl, err := template.New("").Funcs(funcMap).ParseGlob("templates/**/*.html")
+	tmpl, err := template.New("").Funcs(templateFuncs).ParseGlob("templates/**/*.html")
 	if err != nil {
 		return nil, err
 	}
@@ -111,12 +141,65 @@ func (h *Handler) RegisterRoutes(e *echo.Echo) {
 	{
 		// Pack calculation routes
 		api.POST("/calculate", h.CalculatePacks)
+		api.GET("/calculate/alternatives", h.CalculateAlternatives)
+		api.POST("/calculate/amend", h.AmendPacks)
+		api.GET("/calculate/range", h.CalculateRange)
+		api.GET("/calculate/slip", h.CalculationSlip)
+		api.GET("/calculate/labels", h.CalculationLabels)
+		api.GET("/suggestions", h.SuggestQuantities)
+		api.POST("/orders/consolidate", h.ConsolidateOrders)
 
 		// Pack size management routes
 		api.GET("/pack-sizes", h.GetPackSizes)
+		api.GET("/pack-sizes/analysis", h.AnalyzePackSizes)
+		api.GET("/pack-sizes/forecast", h.ForecastPackConsumption)
+		api.POST("/pack-sizes/recommend", h.RecommendPackSets)
 		api.POST("/pack-sizes", h.AddPackSize)
 		api.PUT("/pack-sizes/:id", h.UpdatePackSize)
+		api.PUT("/pack-sizes/:id/stock", h.SetPackStock)
 		api.DELETE("/pack-sizes/:id", h.DeletePackSize)
+
+		// Shipment planning routes
+		api.POST("/shipments/plan", h.PlanShipment)
+		api.GET("/carriers", h.GetCarriers)
+		api.POST("/carriers", h.AddCarrier)
+		api.GET("/carriers/:name/rates", h.GetRateCard)
+		api.PUT("/carriers/:name/rates", h.SetRateCard)
+
+		// Fulfilment routes
+		api.POST("/fulfilment/plan", h.PlanFulfilment)
+		api.GET("/warehouses", h.GetWarehouses)
+		api.POST("/warehouses", h.AddWarehouse)
+		api.PUT("/warehouses/:name/stock", h.SetStock)
+
+		// Pack catalogue simulation routes
+		api.POST("/simulations", h.SimulatePackSets)
+		api.GET("/order-histories", h.GetOrderHistories)
+		api.PUT("/order-histories/:name", h.SaveOrderHistory)
+
+		// Stock reservation routes
+		api.POST("/reservations", h.ReserveOrder)
+		api.GET("/reservations/:id", h.GetReservation)
+		api.POST("/reservations/:id/release", h.ReleaseReservation)
+		api.POST("/reservations/:id/ship", h.ShipReservation)
+
+		// Order lifecycle routes
+		api.POST("/orders", h.CreateOrder)
+		api.GET("/orders", h.GetOrders)
+		api.GET("/orders/:id", h.GetOrder)
+		api.GET("/orders/:id/slip", h.OrderSlip)
+		api.GET("/orders/:id/labels", h.OrderLabels)
+		api.POST("/orders/:id/:transition", h.TransitionOrder)
+
+		// Label template routes
+		api.GET("/label-templates", h.GetLabelTemplates)
+		api.GET("/label-templates/:name", h.GetLabelTemplate)
+		api.PUT("/label-templates/:name", h.SetLabelTemplate)
+		api.GET("/label-templates/:name/preview", h.PreviewLabel)
+
+		// Dashboard routes
+		api.GET("/dashboard", h.GetDashboard)
+		api.GET("/dashboard/export", h.ExportDashboard)
 	}
 
 	// Web UI routes
@@ -124,9 +207,19 @@ func (h *Handler) RegisterRoutes(e *echo.Echo) {
 	e.GET("/calculate", h.CalculatePage)
 	e.POST("/calculate", h.CalculatePagePost)
 	e.GET("/pack-sizes", h.PackSizesPage)
+	e.GET("/shipments", h.ShipmentsPage)
+	e.POST("/shipments", h.ShipmentsPagePost)
+	e.GET("/simulations", h.SimulationsPage)
+	e.POST("/simulations", h.SimulationsPagePost)
+	e.GET("/dashboard", h.DashboardPage)
+	e.GET("/orders", h.OrdersPage)
+	e.POST("/orders", h.OrdersPagePost)
+	e.POST("/orders/:id/:transition", h.OrderTransitionPost)
 
 	// Partial templates for HTMX
 	e.GET("/pack-sizes/partial", h.PackSizesPartial)
+	e.GET("/dashboard/charts", h.DashboardPartial)
+	e.GET("/orders/partial", h.OrdersPartial)
 
 	// Static files
 	e.Static("/static", "static")
@@ -134,12 +227,25 @@ func (h *Handler) RegisterRoutes(e *echo.Echo) {
 }
 
 type CalculateRequest struct {
-	// Handle potential overflow for max int values. If the value exceeds the maximum, it will wrap around to zero.
-	// Consider using big.Int for handling extremely large numbers if required in the future.
-	// Lets be real here, we are not going to have more than 2^64 items in a single order but then again, we are not here to judge.
-	ItemsOrdered int `json:"itemsOrdered"`
+	// Quantities of any size are accepted, as a JSON number or string.
+	// Orders that do not fit in an int are calculated with arbitrary precision instead of wrapping around.
+	ItemsOrdered Quantity `json:"itemsOrdered"`
+	// Explain adds a trace of how the result was derived, also accepted as ?explain=true
+	Explain bool `json:"explain"`
+	// FollowUp adds the shipment that completes an under-shipped order, also accepted as ?followUp=true
+	FollowUp bool `json:"followUp"`
+	// Packaging rolls the packs up into containers such as "case:10,pallet:40", empty keeps the configured hierarchy
+	Packaging string `json:"packaging" query:"packaging"`
+	// Calculation options overriding the configured ones
+	OptionsRequest
 }
 
+// errExplainTooLarge is returned when an explanation is requested for an order beyond int
+var errExplainTooLarge = errors.New("explain is only available for orders that fit in a 64-bit integer")
+
+// errPackagingTooLarge is returned when a packaging hierarchy is requested for an order beyond int
+var errPackagingTooLarge = errors.New("packaging is only available for orders that fit in a 64-bit integer")
+
 // CalculatePacks calculates the optimal packs for an order
 func (h *Handler) CalculatePacks(c echo.Context) error {
 	// Parse request
@@ -150,31 +256,136 @@ func (h *Handler) CalculatePacks(c echo.Context) error {
 	}
 
 	// Validate request
-	if req.ItemsOrdered <= 0 {
+	if !req.ItemsOrdered.Positive() {
 		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Items ordered must be greater than 0"))
 	}
 
+	if c.QueryParam("explain") == "true" {
+		req.Explain = true
+	}
+	if c.QueryParam("followUp") == "true" {
+		req.FollowUp = true
+	}
+
+	options, err := h.resolveOptions(req.OptionsRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+	packaging, err := h.resolvePackaging(req.Packaging)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
 	// Calculate packs
-	result, err := h.PackService.CalculatePacks(req.ItemsOrdered)
+	response, err := h.calculate(req.ItemsOrdered, req.Explain, req.FollowUp, options, packaging, req.Packaging != "")
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, models.NewErrorResponse(err.Error()))
+		return c.JSON(calculationStatus(err), models.NewErrorResponse(err.Error()))
 	}
 
-	response := CalculateResponse{
-		TotalPacks:  result.TotalPacks,
-		TotalItems:  result.TotalItems,
-		ExcessItems: result.ExcessItems,
+	return c.JSON(http.StatusOK, response)
+}
+
+// calculate runs the int calculation when the quantity allows it
+// and falls back to the arbitrary-precision one when it would overflow.
+// With followUp, an under-shipped order also gets the shipment for its short items.
+// With a packaging hierarchy, int results also get their packs rolled up into containers; a hierarchy the request
+// names explicitly fails orders beyond int, which are not rolled up, instead of being left out.
+func (h *Handler) calculate(itemsOrdered Quantity, explain bool, followUp bool, options calculator.Options, packaging calculator.Hierarchy, explicitPackaging bool) (interface{}, error) {
+	if n, ok := itemsOrdered.Int(); ok {
+		response, err := h.calculateInt(n, explain, options, packaging)
+		if !errors.Is(err, calculator.ErrOverflow) {
+			if err == nil && followUp && response.ShortItems > 0 {
+				response.FollowUp, err = h.calculateFollowUp(response.ShortItems, packCounts(response.Packs), options, packaging)
+			}
+			return response, err
+		}
 	}
 
-	// Convert map to slice for better JSON formatting
-	for size, count := range result.PackCounts {
-		response.Packs = append(response.Packs, PackInfo{
-			Size:  size,
-			Count: count,
-		})
+	if explain {
+		return nil, errExplainTooLarge
+	}
+	if explicitPackaging && len(packaging) > 0 {
+		return nil, errPackagingTooLarge
 	}
 
-	return c.JSON(http.StatusOK, response)
+	result, err := h.PackService.CalculatePacksBig(itemsOrdered.BigInt(), options)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newBigCalculateResponse(result)
+	if followUp && result.ShortItems.Sign() > 0 {
+		// An under-shipped order is short by less than a largest pack, so the follow-up fits in an int
+		response.FollowUp, err = h.calculateFollowUp(int(result.ShortItems.Int64()), bigPackCounts(result.PackCounts), options, packaging)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// calculateFollowUp calculates the shipment that completes an under-shipped order,
+// shipped holds the pack counts of the first shipment
+func (h *Handler) calculateFollowUp(shortItems int, shipped map[int]int, options calculator.Options, packaging calculator.Hierarchy) (*CalculateResponse, error) {
+	result, err := h.PackService.CalculateFollowUp(shortItems, shipped, options)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newCalculateResponse(result)
+	response.Packaging, err = newPackagingResponse(result, packaging)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// packCounts returns the pack counts of a response's packs
+func packCounts(packs []PackInfo) map[int]int {
+	counts := make(map[int]int, len(packs))
+	for _, pack := range packs {
+		counts[pack.Size] = pack.Count
+	}
+	return counts
+}
+
+// bigPackCounts returns arbitrary-precision pack counts as ints, counts beyond an int are capped
+// since they meet any pack limit
+func bigPackCounts(packs map[int]*big.Int) map[int]int {
+	counts := make(map[int]int, len(packs))
+	for size, count := range packs {
+		if count.IsInt64() && count.Int64() <= math.MaxInt {
+			counts[size] = int(count.Int64())
+		} else {
+			counts[size] = math.MaxInt
+		}
+	}
+	return counts
+}
+
+// calculateInt calculates the packs for an order that fits in an int
+func (h *Handler) calculateInt(itemsOrdered int, explain bool, options calculator.Options, packaging calculator.Hierarchy) (CalculateResponse, error) {
+	var result *calculator.PackResult
+	var explanation *calculator.Explanation
+	var err error
+	if explain {
+		result, explanation, err = h.PackService.ExplainPacks(itemsOrdered, options)
+	} else {
+		result, err = h.PackService.CalculatePacks(itemsOrdered, options)
+	}
+	if err != nil {
+		return CalculateResponse{}, err
+	}
+
+	response := newCalculateResponse(result)
+	if explanation != nil {
+		response.Explanation = newExplanationResponse(explanation)
+	}
+	response.Packaging, err = newPackagingResponse(result, packaging)
+	if err != nil {
+		return CalculateResponse{}, err
+	}
+	return response, nil
 }
 
 // PackInfo Format response
@@ -183,10 +394,88 @@ type PackInfo struct {
 	Count int `json:"count"`
 }
 type CalculateResponse struct {
-	Packs       []PackInfo `json:"packs"`
-	TotalPacks  int        `json:"totalPacks"`
-	TotalItems  int        `json:"totalItems"`
-	ExcessItems int        `json:"excessItems"`
+	Packs             []PackInfo           `json:"packs"`
+	TotalPacks        int                  `json:"totalPacks"`
+	TotalItems        int                  `json:"totalItems"`
+	ExcessItems       int                  `json:"excessItems"`
+	ShortItems        int                  `json:"shortItems"`
+	DistinctPackTypes int                  `json:"distinctPackTypes"`
+	Explanation       *ExplanationResponse `json:"explanation,omitempty"`
+	Packaging         *PackagingResponse   `json:"packaging,omitempty"` // Containers the packs roll up into, with a hierarchy
+	FollowUp          *CalculateResponse   `json:"followUp,omitempty"`  // Shipment for the short items, if requested
+}
+
+// newCalculateResponse formats a pack result for the API and templates
+func newCalculateResponse(result *calculator.PackResult) CalculateResponse {
+	return CalculateResponse{
+		Packs:             newPackInfos(result.PackCounts),
+		TotalPacks:        result.TotalPacks,
+		TotalItems:        result.TotalItems,
+		ExcessItems:       result.ExcessItems,
+		ShortItems:        result.ShortItems,
+		DistinctPackTypes: result.DistinctPackTypes,
+	}
+}
+
+// newPackInfos converts pack counts to a slice for better JSON formatting, largest packs first
+func newPackInfos(packCounts map[int]int) []PackInfo {
+	var packs []PackInfo
+	for _, size := range sortedSizes(packCounts) {
+		packs = append(packs, PackInfo{
+			Size:  size,
+			Count: packCounts[size],
+		})
+	}
+	return packs
+}
+
+// sortedSizes returns the pack sizes of a packing in descending order,
+// so identical results always render identically
+func sortedSizes[V any](packCounts map[int]V) []int {
+	sizes := make([]int, 0, len(packCounts))
+	for size := range packCounts {
+		sizes = append(sizes, size)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	return sizes
+}
+
+// BigPackInfo is PackInfo with an arbitrary-precision count
+type BigPackInfo struct {
+	Size  int      `json:"size"`
+	Count *big.Int `json:"count"`
+}
+
+// BigCalculateResponse is CalculateResponse for orders that do not fit in an int.
+// big.Int values are encoded as plain JSON numbers, so the shape matches CalculateResponse.
+type BigCalculateResponse struct {
+	Packs             []BigPackInfo      `json:"packs"`
+	TotalPacks        *big.Int           `json:"totalPacks"`
+	TotalItems        *big.Int           `json:"totalItems"`
+	ExcessItems       *big.Int           `json:"excessItems"`
+	ShortItems        *big.Int           `json:"shortItems"`
+	DistinctPackTypes int                `json:"distinctPackTypes"`
+	FollowUp          *CalculateResponse `json:"followUp,omitempty"` // Shipment for the short items, if requested
+}
+
+// newBigCalculateResponse formats an arbitrary-precision pack result for the API and templates
+func newBigCalculateResponse(result *calculator.BigPackResult) BigCalculateResponse {
+	response := BigCalculateResponse{
+		TotalPacks:        result.TotalPacks,
+		TotalItems:        result.TotalItems,
+		ExcessItems:       result.ExcessItems,
+		ShortItems:        result.ShortItems,
+		DistinctPackTypes: result.DistinctPackTypes,
+	}
+
+	for _, size := range sortedSizes(result.PackCounts) {
+		response.Packs = append(response.Packs, BigPackInfo{
+			Size:  size,
+			Count: result.PackCounts[size],
+		})
+	}
+
+	return response
 }
 
 // GetPackSizes returns all pack sizes
@@ -199,7 +488,17 @@ func (h *Handler) GetPackSizes(c echo.Context) error {
 }
 
 type AddPackSizeRequest struct {
-	Size int `form:"size" json:"size"`
+	Size   int `form:"size" json:"size"`
+	Weight int `form:"weight" json:"weight"` // Grams, 0 if unknown
+	Length int `form:"length" json:"length"` // Millimetres, 0 if unknown
+	Width  int `form:"width" json:"width"`   // Millimetres, 0 if unknown
+	Height int `form:"height" json:"height"` // Millimetres, 0 if unknown
+	Cost   int `form:"cost" json:"cost"`     // Cents of packaging, 0 if unknown
+	// Stock and supplier lead time of the packaging, for reorder points
+	StockOnHand  int `form:"stockOnHand" json:"stockOnHand"`
+	LeadTimeDays int `form:"leadTimeDays" json:"leadTimeDays"`
+	// Shelf or bin the packs are picked from, printed on pick lists
+	Shelf string `form:"shelf" json:"shelf"`
 }
 
 // AddPackSize adds a new pack size
@@ -215,9 +514,17 @@ func (h *Handler) AddPackSize(c echo.Context) error {
 	if req.Size <= 0 {
 		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Pack size must be greater than 0"))
 	}
+	if req.Weight < 0 || req.Length < 0 || req.Width < 0 || req.Height < 0 || req.Cost < 0 {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Weight, dimensions and cost must not be negative"))
+	}
+	if req.StockOnHand < 0 || req.LeadTimeDays < 0 {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Stock on hand and lead time must not be negative"))
+	}
 
 	// Add pack size
-	if err := h.PackService.AddPackSize(req.Size); err != nil {
+	packSize := models.PackSize{Size: req.Size, Weight: req.Weight, Length: req.Length, Width: req.Width, Height: req.Height, Cost: req.Cost,
+		StockOnHand: req.StockOnHand, LeadTimeDays: req.LeadTimeDays, Shelf: strings.TrimSpace(req.Shelf)}
+	if err := h.PackService.AddPackSize(packSize); err != nil {
 		return c.JSON(http.StatusInternalServerError, models.NewErrorResponse(err.Error()))
 	}
 
@@ -287,7 +594,11 @@ func (h *Handler) CalculatePage(c echo.Context) error {
 }
 
 type CalculatePagePostRequest struct {
-	ItemsOrdered int `form:"itemsOrdered" json:"itemsOrdered"`
+	ItemsOrdered Quantity `form:"itemsOrdered" json:"itemsOrdered"`
+	Explain      bool     `form:"explain" json:"explain"`
+	FollowUp     bool     `form:"followUp" json:"followUp"`
+	Packaging    string   `form:"packaging" json:"packaging"`
+	OptionsRequest
 }
 
 // CalculatePagePost handles the calculate form submission
@@ -302,38 +613,48 @@ func (h *Handler) CalculatePagePost(c echo.Context) error {
 		})
 	}
 
-	if req.ItemsOrdered <= 0 {
+	if !req.ItemsOrdered.Positive() {
 		return c.Render(http.StatusBadRequest, "calculation_result.html", map[string]interface{}{
 			"Error": "Items ordered must be a positive number",
 		})
 	}
 
-	// Calculate packs
-	result, err := h.PackService.CalculatePacks(req.ItemsOrdered)
+	options, err := h.resolveOptions(req.OptionsRequest)
+	if err != nil {
+		return c.Render(http.StatusBadRequest, "calculation_result.html", map[string]interface{}{
+			"Error": err.Error(),
+		})
+	}
+	packaging, err := h.resolvePackaging(req.Packaging)
 	if err != nil {
-		return c.Render(http.StatusInternalServerError, "calculation_result.html", map[string]interface{}{
+		return c.Render(http.StatusBadRequest, "calculation_result.html", map[string]interface{}{
 			"Error": err.Error(),
 		})
 	}
 
-	var packs []PackInfo
-	for size, count := range result.PackCounts {
-		packs = append(packs, PackInfo{
-			Size:  size,
-			Count: count,
+	// Calculate packs
+	result, err := h.calculate(req.ItemsOrdered, req.Explain, req.FollowUp, options, packaging, req.Packaging != "")
+	if err != nil {
+		return c.Render(calculationStatus(err), "calculation_result.html", map[string]interface{}{
+			"Error": err.Error(),
 		})
 	}
 
+	// Quantities near the order that pack without excess, offered under the result
+	suggestions := h.suggest(req.ItemsOrdered, options)
+
+	// Slips are printed for orders that fit in an int only
+	_, printable := result.(CalculateResponse)
+
 	// If this is an HTMX request, render just the result partial
 	if c.Request().Header.Get("HX-Request") == "true" {
 		return c.Render(http.StatusOK, "calculation_result.html", map[string]interface{}{
 			"ItemsOrdered": req.ItemsOrdered,
-			"Result": CalculateResponse{
-				Packs:       packs,
-				TotalPacks:  result.TotalPacks,
-				TotalItems:  result.TotalItems,
-				ExcessItems: result.ExcessItems,
-			},
+			"Explain":      req.Explain,
+			"Result":       result,
+			"Suggestions":  suggestions,
+			"Printable":    printable,
+			"Options":      req.OptionsRequest,
 		})
 	}
 
@@ -341,12 +662,11 @@ func (h *Handler) CalculatePagePost(c echo.Context) error {
 	return c.Render(http.StatusOK, "calculate.html", map[string]interface{}{
 		"Title":        "Calculate Packs",
 		"ItemsOrdered": req.ItemsOrdered,
-		"Result": CalculateResponse{
-			Packs:       packs,
-			TotalPacks:  result.TotalPacks,
-			TotalItems:  result.TotalItems,
-			ExcessItems: result.ExcessItems,
-		},
+		"Explain":      req.Explain,
+		"Result":       result,
+		"Suggestions":  suggestions,
+		"Printable":    printable,
+		"Options":      req.OptionsRequest,
 	})
 }
 
@@ -364,7 +684,16 @@ func (h *Handler) PackSizesPartial(c echo.Context) error {
 		return c.HTML(http.StatusInternalServerError, "<div class='error'>Failed to load pack sizes</div>")
 	}
 
+	// Pack sizes are still listed when consumption cannot be forecast, without reorder warnings
+	forecasts := make(map[int]*calculator.Forecast)
+	packForecasts, err := h.PackService.ForecastPackConsumption(calculator.DefaultForecastOptions())
+	for i := range packForecasts {
+		forecasts[packForecasts[i].Size] = &packForecasts[i]
+	}
+
 	return c.Render(http.StatusOK, "pack_sizes_table.html", map[string]interface{}{
-		"PackSizes": packSizes,
+		"PackSizes":     packSizes,
+		"Forecasts":     forecasts,
+		"ForecastError": err,
 	})
 }
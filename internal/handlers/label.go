@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"packify/internal/models"
+	"packify/internal/printing"
+	"packify/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+type LabelsRequest struct {
+	ItemsOrdered int `query:"itemsOrdered"`
+	// Reference is printed on the labels as text and barcode, empty for none
+	Reference string `query:"reference"`
+	// Template names the label template, empty for the default
+	Template string `query:"template"`
+	// Calculation options overriding the configured ones
+	OptionsRequest
+}
+
+type LabelPreviewRequest struct {
+	// Format is svg or png, empty for svg
+	Format string `query:"format"`
+	// Sample label values, empty or 0 for SO-1042, a customer named Customer and a pack of 500
+	Reference string `query:"reference"`
+	Customer  string `query:"customer"`
+	Size      int    `query:"size"`
+}
+
+// LabelTemplateInfo is a label template, in printer dots
+type LabelTemplateInfo struct {
+	Name   string           `json:"name"`
+	Width  int              `json:"width"`
+	Height int              `json:"height"`
+	Fields []LabelFieldInfo `json:"fields"` // Drawn in order
+}
+
+// LabelFieldInfo is a text, barcode or box placed on a label
+type LabelFieldInfo struct {
+	Kind      string `json:"kind"` // text, barcode or box
+	X         int    `json:"x"`
+	Y         int    `json:"y"`
+	Width     int    `json:"width,omitempty"`     // Character width of texts, narrowest bar of barcodes, width of boxes
+	Height    int    `json:"height"`              // Font height of texts, bar height of barcodes, height of boxes
+	Thickness int    `json:"thickness,omitempty"` // Border of boxes
+	Content   string `json:"content,omitempty"`   // Text or barcode data of texts and barcodes, with placeholders
+}
+
+// newLabelTemplateInfo formats a label template for the API
+func newLabelTemplateInfo(template *models.LabelTemplate) LabelTemplateInfo {
+	info := LabelTemplateInfo{
+		Name:   template.Name,
+		Width:  template.Width,
+		Height: template.Height,
+		Fields: make([]LabelFieldInfo, len(template.Fields)),
+	}
+	for i, field := range template.Fields {
+		info.Fields[i] = LabelFieldInfo{
+			Kind:      field.Kind,
+			X:         field.X,
+			Y:         field.Y,
+			Width:     field.Width,
+			Height:    field.Height,
+			Thickness: field.Thickness,
+			Content:   field.Content,
+		}
+	}
+	return info
+}
+
+// labelStatus maps a label, slip or order error to an HTTP status
+func labelStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUnknownLabelTemplate):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidLabelTemplate):
+		return http.StatusBadRequest
+	case errors.Is(err, printing.ErrTooManyLabels):
+		return http.StatusUnprocessableEntity
+	default:
+		return slipStatus(err)
+	}
+}
+
+// writeLabels streams one ZPL label per pack of a slip, checked against printing.MaxLabels
+func writeLabels(c echo.Context, slip *printing.Slip, template *models.LabelTemplate) error {
+	filename := printFilename("labels", slip, "zpl")
+	c.Response().Header().Set(echo.HeaderContentType, "text/plain; charset=utf-8")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Response().WriteHeader(http.StatusOK)
+	return printing.WriteLabelsZPL(c.Response(), *template, printing.Labels(*slip))
+}
+
+// CalculationLabels downloads one ZPL label per pack of a calculation
+func (h *Handler) CalculationLabels(c echo.Context) error {
+	req := new(LabelsRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+	if req.ItemsOrdered <= 0 {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Items ordered must be greater than 0"))
+	}
+
+	options, err := h.resolveOptions(req.OptionsRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	template, err := h.PackService.GetLabelTemplate(req.Template)
+	if err != nil {
+		return c.JSON(labelStatus(err), models.NewErrorResponse(err.Error()))
+	}
+	slip, err := h.PackService.CalculationSlip(req.ItemsOrdered, strings.TrimSpace(req.Reference), options)
+	if err != nil {
+		return c.JSON(calculationStatus(err), models.NewErrorResponse(err.Error()))
+	}
+	if err := printing.CheckLabels(*slip); err != nil {
+		return c.JSON(labelStatus(err), models.NewErrorResponse(err.Error()))
+	}
+	return writeLabels(c, slip, template)
+}
+
+// OrderLabels downloads one ZPL label per pack of an order, with the label template in ?template=
+func (h *Handler) OrderLabels(c echo.Context) error {
+	id, err := pathID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	template, err := h.PackService.GetLabelTemplate(c.QueryParam("template"))
+	if err != nil {
+		return c.JSON(labelStatus(err), models.NewErrorResponse(err.Error()))
+	}
+	slip, err := h.PackService.OrderSlip(id)
+	if err != nil {
+		return c.JSON(labelStatus(err), models.NewErrorResponse(err.Error()))
+	}
+	if err := printing.CheckLabels(*slip); err != nil {
+		return c.JSON(labelStatus(err), models.NewErrorResponse(err.Error()))
+	}
+	return writeLabels(c, slip, template)
+}
+
+// GetLabelTemplates returns every label template
+func (h *Handler) GetLabelTemplates(c echo.Context) error {
+	templates, err := h.PackService.GetLabelTemplates()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.NewErrorResponse(err.Error()))
+	}
+
+	response := make([]LabelTemplateInfo, len(templates))
+	for i := range templates {
+		response[i] = newLabelTemplateInfo(&templates[i])
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetLabelTemplate returns a label template
+func (h *Handler) GetLabelTemplate(c echo.Context) error {
+	template, err := h.PackService.GetLabelTemplate(c.Param("name"))
+	if err != nil {
+		return c.JSON(labelStatus(err), models.NewErrorResponse(err.Error()))
+	}
+	return c.JSON(http.StatusOK, newLabelTemplateInfo(template))
+}
+
+// SetLabelTemplate creates or replaces a label template
+func (h *Handler) SetLabelTemplate(c echo.Context) error {
+	req := new(LabelTemplateInfo)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+
+	template := models.LabelTemplate{Name: c.Param("name"), Width: req.Width, Height: req.Height}
+	for _, field := range req.Fields {
+		template.Fields = append(template.Fields, models.LabelField{
+			Kind:      field.Kind,
+			X:         field.X,
+			Y:         field.Y,
+			Width:     field.Width,
+			Height:    field.Height,
+			Thickness: field.Thickness,
+			Content:   field.Content,
+		})
+	}
+	if err := h.PackService.SetLabelTemplate(template); err != nil {
+		return c.JSON(labelStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, models.NewSuccessResponse("Label template updated successfully"))
+}
+
+// PreviewLabel renders a sample label of a label template as SVG or PNG, for checking a layout without a printer
+func (h *Handler) PreviewLabel(c echo.Context) error {
+	req := new(LabelPreviewRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+	if req.Format != "" && req.Format != "svg" && req.Format != "png" {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(fmt.Sprintf("Unknown format %q, expected svg or png", req.Format)))
+	}
+	if req.Size < 0 {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Size must be greater than 0"))
+	}
+
+	template, err := h.PackService.GetLabelTemplate(c.Param("name"))
+	if err != nil {
+		return c.JSON(labelStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	label := printing.Label{Reference: "SO-1042", Customer: "Customer", Size: 500, Pack: 1, Packs: 3}
+	if req.Reference != "" {
+		label.Reference = req.Reference
+	}
+	if req.Customer != "" {
+		label.Customer = req.Customer
+	}
+	if req.Size != 0 {
+		label.Size = req.Size
+	}
+
+	var preview bytes.Buffer
+	if req.Format == "png" {
+		if err := printing.WriteLabelPNG(&preview, *template, label); err != nil {
+			return c.JSON(http.StatusInternalServerError, models.NewErrorResponse(err.Error()))
+		}
+		return c.Blob(http.StatusOK, "image/png", preview.Bytes())
+	}
+	if err := printing.WriteLabelSVG(&preview, *template, label); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.NewErrorResponse(err.Error()))
+	}
+	return c.Blob(http.StatusOK, "image/svg+xml", preview.Bytes())
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"packify/pkg/calculator"
+)
+
+// OptionsRequest holds the calculation options a request can override, empty fields keep the configured ones
+type OptionsRequest struct {
+	// TieBreak decides between packings tied on every business rule, see calculator.TieBreaks
+	TieBreak string `json:"tieBreak" form:"tieBreak" query:"tieBreak"`
+	// DistinctRule places the fewest distinct pack sizes rule, see calculator.DistinctRules
+	DistinctRule string `json:"distinctRule" form:"distinctRule" query:"distinctRule"`
+	// Fulfilment over-ships or under-ships the order, see calculator.Fulfilments
+	Fulfilment string `json:"fulfilment" form:"fulfilment" query:"fulfilment"`
+	// Tolerance accepts excess items to save packs, e.g. "50" or "5%", see calculator.ParseTolerance
+	Tolerance string `json:"tolerance" form:"tolerance" query:"tolerance"`
+	// Limits bounds the pack count of single sizes, only accepted in JSON bodies
+	Limits []PackLimitRequest `json:"limits" form:"-" query:"-"`
+}
+
+// PackLimitRequest bounds how many packs of one size a calculation may use
+type PackLimitRequest struct {
+	Size int  `json:"size"`
+	Min  int  `json:"min"`
+	Max  *int `json:"max"` // Omitted or null for no maximum
+}
+
+// resolveOptions applies the overrides of a request to the configured calculation options
+func (h *Handler) resolveOptions(req OptionsRequest) (calculator.Options, error) {
+	options := h.PackService.Options
+
+	if req.TieBreak != "" {
+		tieBreak, err := calculator.ParseTieBreak(req.TieBreak)
+		if err != nil {
+			return calculator.Options{}, err
+		}
+		options.TieBreak = tieBreak
+	}
+
+	if req.DistinctRule != "" {
+		distinctRule, err := calculator.ParseDistinctRule(req.DistinctRule)
+		if err != nil {
+			return calculator.Options{}, err
+		}
+		options.DistinctRule = distinctRule
+	}
+
+	if req.Fulfilment != "" {
+		fulfilment, err := calculator.ParseFulfilment(req.Fulfilment)
+		if err != nil {
+			return calculator.Options{}, err
+		}
+		options.Fulfilment = fulfilment
+	}
+
+	if req.Tolerance != "" {
+		tolerance, err := calculator.ParseTolerance(req.Tolerance)
+		if err != nil {
+			return calculator.Options{}, err
+		}
+		options.Tolerance = tolerance
+	}
+
+	if len(req.Limits) > 0 {
+		options.Limits = make(map[int]calculator.PackLimit, len(req.Limits))
+		for _, limit := range req.Limits {
+			if _, ok := options.Limits[limit.Size]; ok {
+				return calculator.Options{}, fmt.Errorf("pack size %d has more than one limit", limit.Size)
+			}
+			packLimit := calculator.PackLimit{Min: limit.Min, Max: calculator.Unlimited}
+			if limit.Max != nil {
+				packLimit.Max = *limit.Max
+				if packLimit.Max < 0 {
+					return calculator.Options{}, fmt.Errorf("maximum for pack size %d must not be negative", limit.Size)
+				}
+			}
+			options.Limits[limit.Size] = packLimit
+		}
+	}
+
+	return options, nil
+}
+
+// calculationStatus returns the HTTP status for an error of a calculation
+func calculationStatus(err error) int {
+	switch {
+	case errors.Is(err, errExplainTooLarge), errors.Is(err, errPackagingTooLarge),
+		errors.Is(err, calculator.ErrInvalidLimit), errors.Is(err, calculator.ErrInvalidRange),
+		errors.Is(err, calculator.ErrInvalidTolerance), errors.Is(err, calculator.ErrAllocationTooLarge):
+		return http.StatusBadRequest
+	case errors.Is(err, calculator.ErrInfeasible), errors.Is(err, calculator.ErrFineGrained),
+		errors.Is(err, calculator.ErrUnsupportedOrder):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
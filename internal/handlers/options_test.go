@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"packify/pkg/calculator"
+)
+
+// TestCalculationStatus checks that options an order cannot be calculated with are client errors
+func TestCalculationStatus(t *testing.T) {
+	standardPacks := []int{250, 500, 1000, 2000, 5000}
+
+	distinctFirst := calculator.DefaultOptions()
+	distinctFirst.DistinctRule = calculator.DistinctRuleBeforePacks
+
+	largestCapped := calculator.DefaultOptions()
+	largestCapped.Limits = map[int]calculator.PackLimit{5000: {Min: 0, Max: 1000}}
+
+	largeTolerance := calculator.DefaultOptions()
+	largeTolerance.Tolerance = calculator.Tolerance{Percent: 50}
+	largeTolerance.Limits = map[int]calculator.PackLimit{5000: {Min: 0, Max: 1000}}
+
+	invalidLimit := calculator.DefaultOptions()
+	invalidLimit.Limits = map[int]calculator.PackLimit{300: calculator.NoLimit()}
+
+	testCases := []struct {
+		name           string
+		itemsOrdered   int
+		options        calculator.Options
+		expectedStatus int
+	}{
+		{"Distinct sizes first above the safety threshold", 2000000, distinctFirst, http.StatusUnprocessableEntity},
+		{"Maximum on the largest pack above the safety threshold", 2000000, largestCapped, http.StatusUnprocessableEntity},
+		{"Tolerance too large for the order", 900000, largeTolerance, http.StatusBadRequest},
+		{"Limit on a size that is not available", 2000000, invalidLimit, http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := calculator.CalculatePacksWithOptions(tc.itemsOrdered, standardPacks, tc.options)
+			if err == nil {
+				t.Fatalf("Expected an error")
+			}
+			if status := calculationStatus(err); status != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d for %v", tc.expectedStatus, status, err)
+			}
+		})
+	}
+}
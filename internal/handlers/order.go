@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"packify/internal/models"
+	"packify/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultOrdersListed is the number of orders listed when a request does not set a limit
+const defaultOrdersListed = 100
+
+// maxOrdersListed is the most orders one request lists
+const maxOrdersListed = 1000
+
+type CreateOrderRequest struct {
+	Reference    string `json:"reference" form:"reference"` // Reference of the order in the shop or ERP, unique
+	Customer     string `json:"customer" form:"customer"`
+	ItemsOrdered int    `json:"itemsOrdered" form:"itemsOrdered"`
+}
+
+type OrdersRequest struct {
+	// Status lists only the orders with this status, empty for every status
+	Status string `query:"status" form:"status"`
+	// Limit is the most orders listed, newest first, 0 for 100
+	Limit int `query:"limit"`
+}
+
+type TransitionOrderRequest struct {
+	// Calculation options overriding the configured ones, used by the calculate transition
+	OptionsRequest
+}
+
+// OrderResponse Format order
+type OrderResponse struct {
+	ID            uint       `json:"id"`
+	Reference     string     `json:"reference"`
+	Customer      string     `json:"customer"`
+	ItemsOrdered  int        `json:"itemsOrdered"`
+	Status        string     `json:"status"`
+	Packs         []PackInfo `json:"packs"` // Largest size first, empty until the order is calculated
+	TotalPacks    int        `json:"totalPacks"`
+	TotalItems    int        `json:"totalItems"`
+	ExcessItems   int        `json:"excessItems"`
+	ShortItems    int        `json:"shortItems"`
+	ReservationID *uint      `json:"reservationId"` // Set once the order is confirmed
+	Transitions   []string   `json:"transitions"`   // Transitions that apply to the order in its status
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// newOrderResponse formats an order for the API
+func newOrderResponse(order *models.Order) OrderResponse {
+	transitions := services.AllowedTransitions(order.Status)
+	if transitions == nil {
+		transitions = []string{}
+	}
+	packs := newPackInfos(order.PackCounts())
+	if packs == nil {
+		packs = []PackInfo{}
+	}
+
+	return OrderResponse{
+		ID:            order.ID,
+		Reference:     order.Reference,
+		Customer:      order.Customer,
+		ItemsOrdered:  order.ItemsOrdered,
+		Status:        order.Status,
+		Packs:         packs,
+		TotalPacks:    order.TotalPacks,
+		TotalItems:    order.TotalItems,
+		ExcessItems:   order.ExcessItems,
+		ShortItems:    order.ShortItems,
+		ReservationID: order.ReservationID,
+		Transitions:   transitions,
+		CreatedAt:     order.CreatedAt,
+		UpdatedAt:     order.UpdatedAt,
+	}
+}
+
+// orderStatus maps an order, reservation or stock error to an HTTP status
+func orderStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUnknownOrder):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrUnknownTransition):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrDuplicateOrder), errors.Is(err, services.ErrInvalidTransition):
+		return http.StatusConflict
+	default:
+		return reservationStatus(err)
+	}
+}
+
+// validateCreateOrder checks the fields of a new order
+func validateCreateOrder(req *CreateOrderRequest) error {
+	if strings.TrimSpace(req.Reference) == "" {
+		return errors.New("Reference is required")
+	}
+	if req.ItemsOrdered <= 0 {
+		return errors.New("Items ordered must be greater than 0")
+	}
+	return nil
+}
+
+// validateOrdersRequest checks the status filter and limit of an orders list and applies the default limit
+func validateOrdersRequest(req *OrdersRequest) error {
+	if req.Status != "" {
+		known := false
+		for _, status := range models.OrderStatuses {
+			known = known || status == req.Status
+		}
+		if !known {
+			return fmt.Errorf("Unknown status %q, expected one of %s", req.Status, strings.Join(models.OrderStatuses, ", "))
+		}
+	}
+	if req.Limit < 0 || req.Limit > maxOrdersListed {
+		return fmt.Errorf("Limit must be between 1 and %d", maxOrdersListed)
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultOrdersListed
+	}
+	return nil
+}
+
+// CreateOrder creates a draft order
+func (h *Handler) CreateOrder(c echo.Context) error {
+	req := new(CreateOrderRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+	if err := validateCreateOrder(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	order, err := h.PackService.CreateOrder(req.Reference, req.Customer, req.ItemsOrdered)
+	if err != nil {
+		return c.JSON(orderStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusCreated, newOrderResponse(order))
+}
+
+// GetOrders lists the latest orders, newest first
+func (h *Handler) GetOrders(c echo.Context) error {
+	req := new(OrdersRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+	if err := validateOrdersRequest(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	orders, err := h.PackService.GetOrders(req.Status, req.Limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.NewErrorResponse(err.Error()))
+	}
+
+	response := make([]OrderResponse, len(orders))
+	for i := range orders {
+		response[i] = newOrderResponse(&orders[i])
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetOrder returns an order and its calculated packs
+func (h *Handler) GetOrder(c echo.Context) error {
+	id, err := pathID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	order, err := h.PackService.GetOrder(id)
+	if err != nil {
+		return c.JSON(orderStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, newOrderResponse(order))
+}
+
+// TransitionOrder moves an order through its lifecycle: calculate, confirm, pack, ship or cancel
+func (h *Handler) TransitionOrder(c echo.Context) error {
+	id, err := pathID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	req := new(TransitionOrderRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+	options, err := h.resolveOptions(req.OptionsRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	order, err := h.PackService.TransitionOrder(id, c.Param("transition"), options)
+	if err != nil {
+		return c.JSON(orderStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, newOrderResponse(order))
+}
+
+// OrdersPage renders the orders page
+func (h *Handler) OrdersPage(c echo.Context) error {
+	return c.Render(http.StatusOK, "orders.html", map[string]interface{}{
+		"Title":    "Orders",
+		"Statuses": models.OrderStatuses,
+	})
+}
+
+// OrdersPartial renders the orders table partial
+func (h *Handler) OrdersPartial(c echo.Context) error {
+	req := new(OrdersRequest)
+	if err := c.Bind(req); err != nil {
+		return h.renderOrders(c, http.StatusBadRequest, "", "Invalid request")
+	}
+	return h.renderOrders(c, http.StatusOK, req.Status, "")
+}
+
+// OrdersPagePost handles the new order form and renders the orders table
+func (h *Handler) OrdersPagePost(c echo.Context) error {
+	req := new(CreateOrderRequest)
+	if err := c.Bind(req); err != nil {
+		return h.renderOrders(c, http.StatusBadRequest, c.FormValue("status"), "Invalid request")
+	}
+	if err := validateCreateOrder(req); err != nil {
+		return h.renderOrders(c, http.StatusBadRequest, c.FormValue("status"), err.Error())
+	}
+
+	if _, err := h.PackService.CreateOrder(req.Reference, req.Customer, req.ItemsOrdered); err != nil {
+		return h.renderOrders(c, orderStatus(err), c.FormValue("status"), err.Error())
+	}
+	return h.renderOrders(c, http.StatusOK, c.FormValue("status"), "")
+}
+
+// OrderTransitionPost handles the transition buttons of the orders table, with the configured calculation options
+func (h *Handler) OrderTransitionPost(c echo.Context) error {
+	id, err := pathID(c)
+	if err != nil {
+		return h.renderOrders(c, http.StatusBadRequest, c.FormValue("status"), err.Error())
+	}
+
+	if _, err := h.PackService.TransitionOrder(id, c.Param("transition"), h.PackService.Options); err != nil {
+		return h.renderOrders(c, orderStatus(err), c.FormValue("status"), err.Error())
+	}
+	return h.renderOrders(c, http.StatusOK, c.FormValue("status"), "")
+}
+
+// renderOrders renders the latest orders with a status, or with any status if the filter is empty, and an error
+// message if it is not empty
+func (h *Handler) renderOrders(c echo.Context, status int, filter, message string) error {
+	data := map[string]interface{}{
+		"Status": filter,
+		"Error":  message,
+	}
+
+	req := &OrdersRequest{Status: filter}
+	if err := validateOrdersRequest(req); err != nil {
+		data["Error"] = err.Error()
+		return c.Render(http.StatusBadRequest, "orders_table.html", data)
+	}
+
+	orders, err := h.PackService.GetOrders(req.Status, req.Limit)
+	if err != nil {
+		data["Error"] = "Failed to load orders"
+		return c.Render(http.StatusInternalServerError, "orders_table.html", data)
+	}
+	responses := make([]OrderResponse, len(orders))
+	for i := range orders {
+		responses[i] = newOrderResponse(&orders[i])
+	}
+	data["Orders"] = responses
+	return c.Render(status, "orders_table.html", data)
+}
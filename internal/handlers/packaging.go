@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"packify/pkg/calculator"
+)
+
+// PackagingResponse Format packaging hierarchy roll-up
+type PackagingResponse struct {
+	Levels     []LevelInfo     `json:"levels"`
+	Containers []ContainerInfo `json:"containers"`
+}
+
+// LevelInfo counts the containers of one level of the hierarchy
+type LevelInfo struct {
+	Name       string `json:"name"`
+	Capacity   int    `json:"capacity"`
+	Containers int    `json:"containers"`
+	Partial    int    `json:"partial"`
+}
+
+// ContainerInfo is a group of identical containers and what each of them holds
+type ContainerInfo struct {
+	Level    string          `json:"level"`
+	Count    int             `json:"count"`
+	Units    int             `json:"units"`
+	Full     bool            `json:"full"`
+	Packs    []PackInfo      `json:"packs,omitempty"`    // Innermost level only
+	Contents []ContainerInfo `json:"contents,omitempty"` // Outer levels only
+}
+
+// resolvePackaging returns the hierarchy of a request, an empty value keeps the configured one
+func (h *Handler) resolvePackaging(value string) (calculator.Hierarchy, error) {
+	if value == "" {
+		return h.PackService.Packaging, nil
+	}
+	return calculator.ParseHierarchy(value)
+}
+
+// newPackagingResponse rolls a pack result up through a hierarchy, nil without one
+func newPackagingResponse(result *calculator.PackResult, hierarchy calculator.Hierarchy) (*PackagingResponse, error) {
+	if len(hierarchy) == 0 {
+		return nil, nil
+	}
+
+	packaging, err := calculator.RollUp(*result, hierarchy)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PackagingResponse{Containers: newContainerInfos(packaging.Containers)}
+	for _, level := range packaging.Levels {
+		response.Levels = append(response.Levels, LevelInfo{
+			Name:       level.Name,
+			Capacity:   level.Capacity,
+			Containers: level.Containers,
+			Partial:    level.Partial,
+		})
+	}
+	return response, nil
+}
+
+// newContainerInfos converts groups of containers and their contents
+func newContainerInfos(containers []calculator.Container) []ContainerInfo {
+	var infos []ContainerInfo
+	for _, container := range containers {
+		infos = append(infos, ContainerInfo{
+			Level:    container.Level,
+			Count:    container.Count,
+			Units:    container.Units,
+			Full:     container.Full,
+			Packs:    newPackInfos(container.Packs),
+			Contents: newContainerInfos(container.Contents),
+		})
+	}
+	return infos
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Quantity is an item quantity of arbitrary size.
+// It is accepted as a JSON number or a JSON string, and as a plain form/query value.
+type Quantity struct {
+	value *big.Int
+}
+
+// NewQuantity creates a quantity from an int
+func NewQuantity(n int) Quantity {
+	return Quantity{value: big.NewInt(int64(n))}
+}
+
+// UnmarshalJSON decodes a quantity from a JSON number or string
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	raw := strings.TrimSpace(string(data))
+	if raw == "null" {
+		return nil
+	}
+
+	if strings.HasPrefix(raw, `"`) {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	}
+
+	return q.parse(raw)
+}
+
+// UnmarshalParam decodes a quantity from a form or query param
+func (q *Quantity) UnmarshalParam(param string) error {
+	return q.parse(param)
+}
+
+// MarshalJSON encodes the quantity as a JSON number
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// parse parses a base 10 integer, rejecting fractions and exponents
+func (q *Quantity) parse(raw string) error {
+	value, ok := new(big.Int).SetString(strings.TrimSpace(raw), 10)
+	if !ok {
+		return fmt.Errorf("invalid quantity %q", raw)
+	}
+	q.value = value
+	return nil
+}
+
+// Positive reports whether the quantity is set and greater than zero
+func (q Quantity) Positive() bool {
+	return q.value != nil && q.value.Sign() > 0
+}
+
+// Int returns the quantity as an int, ok is false if it would overflow
+func (q Quantity) Int() (int, bool) {
+	if q.value == nil || !q.value.IsInt64() || q.value.Int64() > math.MaxInt || q.value.Int64() < math.MinInt {
+		return 0, false
+	}
+	return int(q.value.Int64()), true
+}
+
+// BigInt returns the quantity as a big.Int
+func (q Quantity) BigInt() *big.Int {
+	if q.value == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(q.value)
+}
+
+// String returns the decimal representation of the quantity
+func (q Quantity) String() string {
+	if q.value == nil {
+		return "0"
+	}
+	return q.value.String()
+}
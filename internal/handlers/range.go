@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"packify/internal/export"
+	"packify/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+type RangeRequest struct {
+	From   int    `query:"from"`
+	To     int    `query:"to"`
+	Format string `query:"format"` // csv (default) or ndjson
+	OptionsRequest
+}
+
+// streamWriter starts a streamed response on its first write, so errors before the first row are still
+// answered with a status and an error message
+type streamWriter struct {
+	c           echo.Context
+	contentType string
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	res := w.c.Response()
+	if !res.Committed {
+		res.Header().Set(echo.HeaderContentType, w.contentType)
+		res.WriteHeader(http.StatusOK)
+	}
+	n, err := res.Write(p)
+	if err != nil {
+		return n, err
+	}
+	// Writes arrive in buffered chunks, flushing each sends rows to the client as they are calculated
+	return n, http.NewResponseController(res).Flush()
+}
+
+// CalculateRange streams the optimal packs of every order in a range of quantities as CSV or NDJSON
+func (h *Handler) CalculateRange(c echo.Context) error {
+	req := new(RangeRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+
+	if req.From <= 0 {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("From must be greater than 0"))
+	}
+	if req.To < req.From {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("To must not be less than from"))
+	}
+	contentType, err := export.ContentType(req.Format)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	options, err := h.resolveOptions(req.OptionsRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	err = h.PackService.CalculateRange(req.From, req.To, options, req.Format, &streamWriter{c: c, contentType: contentType})
+	if err != nil && !c.Response().Committed {
+		return c.JSON(calculationStatus(err), models.NewErrorResponse(err.Error()))
+	}
+	// A failure while streaming can only cut the table short, Echo logs it
+	return err
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"packify/internal/models"
+	"packify/pkg/calculator"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultRecommendations is the number of pack sets recommended when a request does not ask for a number
+const defaultRecommendations = 5
+
+// maxRecommendations is the largest number of pack sets a request may ask for
+const maxRecommendations = 50
+
+// maxCandidateSizes is the largest number of candidate sizes a request may give
+const maxCandidateSizes = 100
+
+type RecommendRequest struct {
+	// Orders are the quantities of past orders, empty to use a stored order history
+	Orders []int `json:"orders"`
+	// OrderHistory names the stored order history to use when no orders are given
+	OrderHistory string `json:"orderHistory"`
+	// MaxSizes is the largest number of pack sizes a recommended set may have
+	MaxSizes int `json:"maxSizes"`
+	// CandidateSizes are the sizes sets are chosen from, empty for the most frequent order quantities
+	CandidateSizes []int `json:"candidateSizes"`
+	// Top is the number of sets to recommend
+	Top int `json:"top"`
+	// Calculation options overriding the configured ones, except pack limits
+	OptionsRequest
+}
+
+// RecommendationResponse Format recommendation
+type RecommendationResponse struct {
+	Orders     int           `json:"orders"`
+	Candidates []int         `json:"candidates"` // Sizes the sets were chosen from
+	Evaluated  int           `json:"evaluated"`  // Pack sets evaluated
+	Truncated  bool          `json:"truncated"`  // The search stopped early to stay within its work budget
+	Sets       []PackSetInfo `json:"sets"`       // Best first
+}
+
+// PackSetInfo is how well a recommended set of pack sizes packs the orders
+type PackSetInfo struct {
+	PackSizes   []int `json:"packSizes"`
+	ExcessItems int   `json:"excessItems"`
+	ShortItems  int   `json:"shortItems"`
+	TotalPacks  int   `json:"totalPacks"`
+	ExactOrders int   `json:"exactOrders"`
+}
+
+// newRecommendationResponse formats a recommendation for the API
+func newRecommendationResponse(recommendation *calculator.Recommendation) RecommendationResponse {
+	response := RecommendationResponse{
+		Orders:     recommendation.Orders,
+		Candidates: recommendation.Candidates,
+		Evaluated:  recommendation.Evaluated,
+		Truncated:  recommendation.Truncated,
+		Sets:       make([]PackSetInfo, len(recommendation.Sets)),
+	}
+	for i, set := range recommendation.Sets {
+		response.Sets[i] = PackSetInfo{
+			PackSizes:   set.PackSizes,
+			ExcessItems: set.ExcessItems,
+			ShortItems:  set.ShortItems,
+			TotalPacks:  set.TotalPacks,
+			ExactOrders: set.ExactOrders,
+		}
+	}
+	return response
+}
+
+// validateRecommendedOrders checks the orders of a recommendation like those of a simulation, within the smaller
+// limits of the recommendation search. Orders of a stored history are checked by the search.
+func validateRecommendedOrders(orders []int) error {
+	if err := validateOrderQuantities(orders); err != nil {
+		return err
+	}
+	if len(orders) > calculator.MaxRecommendedOrders {
+		return fmt.Errorf("At most %d orders are accepted, got %d", calculator.MaxRecommendedOrders, len(orders))
+	}
+	for _, itemsOrdered := range orders {
+		if itemsOrdered > calculator.MaxRecommendedItems {
+			return fmt.Errorf("Items of every order must be at most %d, got %d", calculator.MaxRecommendedItems, itemsOrdered)
+		}
+	}
+	return nil
+}
+
+// RecommendPackSets recommends the pack sizes to manufacture from the quantities of past orders
+func (h *Handler) RecommendPackSets(c echo.Context) error {
+	req := new(RecommendRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+
+	if req.MaxSizes <= 0 || req.MaxSizes > calculator.MaxRecommendedSizes {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			fmt.Sprintf("Maximum number of pack sizes must be between 1 and %d", calculator.MaxRecommendedSizes)))
+	}
+	if req.Top == 0 {
+		req.Top = defaultRecommendations
+	}
+	if req.Top < 0 || req.Top > maxRecommendations {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			fmt.Sprintf("Number of recommendations must be between 1 and %d", maxRecommendations)))
+	}
+	if len(req.CandidateSizes) > maxCandidateSizes {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			fmt.Sprintf("At most %d candidate pack sizes are accepted", maxCandidateSizes)))
+	}
+	for _, size := range req.CandidateSizes {
+		if size <= 0 {
+			return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Candidate pack sizes must be positive"))
+		}
+	}
+	if len(req.Orders) > 0 && req.OrderHistory != "" {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Give either orders or an order history, not both"))
+	}
+	if req.OrderHistory == "" {
+		if err := validateRecommendedOrders(req.Orders); err != nil {
+			return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+		}
+	}
+
+	options, err := h.resolveOptions(req.OptionsRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	recommendation, err := h.PackService.RecommendPackSets(req.Orders, req.OrderHistory, req.MaxSizes,
+		req.CandidateSizes, req.Top, options)
+	if err != nil {
+		return c.JSON(simulationStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, newRecommendationResponse(recommendation))
+}
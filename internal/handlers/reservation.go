@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"packify/internal/models"
+	"packify/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ReserveRequest struct {
+	ItemsOrdered int `json:"itemsOrdered"`
+	// Calculation options overriding the configured ones
+	OptionsRequest
+}
+
+// ReservationResponse Format reservation
+type ReservationResponse struct {
+	ID           uint       `json:"id"`
+	ItemsOrdered int        `json:"itemsOrdered"`
+	Status       string     `json:"status"` // reserved, released or shipped
+	Packs        []PackInfo `json:"packs"`  // Largest size first
+	TotalPacks   int        `json:"totalPacks"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+// newReservationResponse formats a reservation for the API
+func newReservationResponse(reservation *models.Reservation) ReservationResponse {
+	packCounts := make(map[int]int, len(reservation.Packs))
+	for _, pack := range reservation.Packs {
+		packCounts[pack.Size] += pack.Count
+	}
+
+	response := ReservationResponse{
+		ID:           reservation.ID,
+		ItemsOrdered: reservation.ItemsOrdered,
+		Status:       reservation.Status,
+		Packs:        newPackInfos(packCounts),
+		CreatedAt:    reservation.CreatedAt,
+		UpdatedAt:    reservation.UpdatedAt,
+	}
+	for _, pack := range response.Packs {
+		response.TotalPacks += pack.Count
+	}
+	return response
+}
+
+// reservationStatus maps a reservation or stock error to an HTTP status
+func reservationStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUnknownReservation), errors.Is(err, services.ErrUnknownPackSize):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInsufficientStock), errors.Is(err, services.ErrReservationClosed),
+		errors.Is(err, services.ErrReservationOwned):
+		return http.StatusConflict
+	default:
+		return calculationStatus(err)
+	}
+}
+
+// pathID parses the ID of a reservation or order from the URL
+func pathID(c echo.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return 0, errors.New("Invalid ID")
+	}
+	return uint(id), nil
+}
+
+// ReserveOrder confirms an order: its optimal packs are calculated and reserved in stock
+func (h *Handler) ReserveOrder(c echo.Context) error {
+	req := new(ReserveRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+	if req.ItemsOrdered <= 0 {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Items ordered must be greater than 0"))
+	}
+
+	options, err := h.resolveOptions(req.OptionsRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	reservation, err := h.PackService.ReserveOrder(req.ItemsOrdered, options)
+	if err != nil {
+		return c.JSON(reservationStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusCreated, newReservationResponse(reservation))
+}
+
+// GetReservation returns a reservation and its packs
+func (h *Handler) GetReservation(c echo.Context) error {
+	id, err := pathID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	reservation, err := h.PackService.GetReservation(id)
+	if err != nil {
+		return c.JSON(reservationStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, newReservationResponse(reservation))
+}
+
+// ReleaseReservation cancels a reservation, its packs return to available stock
+func (h *Handler) ReleaseReservation(c echo.Context) error {
+	id, err := pathID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	reservation, err := h.PackService.ReleaseReservation(id)
+	if err != nil {
+		return c.JSON(reservationStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, newReservationResponse(reservation))
+}
+
+// ShipReservation ships a reservation, its packs leave the stock
+func (h *Handler) ShipReservation(c echo.Context) error {
+	id, err := pathID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	reservation, err := h.PackService.ShipReservation(id)
+	if err != nil {
+		return c.JSON(reservationStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, newReservationResponse(reservation))
+}
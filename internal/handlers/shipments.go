@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"packify/internal/models"
+	"packify/internal/services"
+	"packify/pkg/calculator"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PlanShipmentRequest struct {
+	ItemsOrdered int `form:"itemsOrdered" json:"itemsOrdered"`
+	// Carrier names the carrier profile, empty for the configured one
+	Carrier string `form:"carrier" json:"carrier"`
+	// Objective picks the fewest packs or the cheapest shipment, see calculator.Objectives
+	Objective string `form:"objective" json:"objective"`
+	// Calculation options overriding the configured ones
+	OptionsRequest
+}
+
+// ShipmentPlanResponse Format shipment plan
+type ShipmentPlanResponse struct {
+	CalculateResponse
+	Carrier      CarrierInfo  `json:"carrier"`
+	Parcels      []ParcelInfo `json:"parcels"`
+	TotalParcels int          `json:"totalParcels"`
+	LowerBound   int          `json:"lowerBound"` // No assignment uses fewer parcels
+	Optimal      bool         `json:"optimal"`    // Whether totalParcels is proven to be the fewest
+	Objective    string       `json:"objective"`
+	Cost         *CostInfo    `json:"cost,omitempty"`       // Omitted when the carrier has no rate card
+	Fewest       *FewestInfo  `json:"fewest,omitempty"`     // With the cheapest objective, the packing the rules pick
+	Candidates   int          `json:"candidates,omitempty"` // With the cheapest objective, the packings priced
+}
+
+// CostInfo is what a shipment costs to pack and ship, in cents
+type CostInfo struct {
+	Packaging int              `json:"packaging"`
+	Shipping  int              `json:"shipping"`
+	Total     int              `json:"total"`
+	Parcels   []ParcelCostInfo `json:"parcels"`
+}
+
+// ParcelCostInfo is the shipping cost of each parcel of a group, in cents
+type ParcelCostInfo struct {
+	Count  int `json:"count"`
+	Weight int `json:"weight"` // Grams
+	Fee    int `json:"fee"`
+	Price  int `json:"price"` // Price of the weight band
+	Total  int `json:"total"` // Fees and prices of all parcels of the group
+}
+
+// FewestInfo is the packing the business rules pick and what choosing the cheapest one saves over it
+type FewestInfo struct {
+	Packs        []PackInfo `json:"packs"`
+	TotalPacks   int        `json:"totalPacks"`
+	TotalItems   int        `json:"totalItems"`
+	TotalParcels int        `json:"totalParcels"`
+	Cost         *CostInfo  `json:"cost,omitempty"`    // Omitted when the packing cannot be shipped
+	Savings      int        `json:"savings,omitempty"` // Cents saved by the cheapest shipment
+}
+
+// RateCardInfo is the rate card of a carrier, in cents
+type RateCardInfo struct {
+	ParcelFee int            `json:"parcelFee"`
+	Bands     []RateBandInfo `json:"bands"`
+}
+
+// RateBandInfo is the price of a parcel weighing up to maxWeight grams
+type RateBandInfo struct {
+	MaxWeight int `json:"maxWeight"`
+	Price     int `json:"price"`
+}
+
+// CarrierInfo is a carrier profile and the largest parcel it accepts
+type CarrierInfo struct {
+	Name      string `json:"name"`
+	MaxWeight int    `json:"maxWeight"` // Grams, 0 for no limit
+	MaxVolume int    `json:"maxVolume"` // Cubic centimetres, 0 for no limit
+}
+
+// ParcelInfo is a group of identical parcels and the packs in each of them
+type ParcelInfo struct {
+	Count  int        `json:"count"`
+	Packs  []PackInfo `json:"packs"`
+	Weight int        `json:"weight"` // Grams in each parcel
+	Volume int        `json:"volume"` // Cubic centimetres in each parcel, rounded up
+}
+
+// newShipmentPlanResponse formats a shipment plan for the API and templates
+func newShipmentPlanResponse(plan *services.ShipmentPlan) ShipmentPlanResponse {
+	response := ShipmentPlanResponse{
+		CalculateResponse: newCalculateResponse(plan.Result),
+		Carrier:           newCarrierInfo(plan.Carrier),
+		TotalParcels:      plan.Plan.TotalParcels,
+		LowerBound:        plan.Plan.LowerBound,
+		Optimal:           plan.Plan.Optimal,
+		Objective:         string(plan.Objective),
+		Cost:              newCostInfo(plan.Cost),
+		Candidates:        plan.Candidates,
+	}
+	if fewest := plan.Fewest; fewest != nil {
+		response.Fewest = &FewestInfo{
+			Packs:        newPackInfos(fewest.PackCounts),
+			TotalPacks:   fewest.TotalPacks,
+			TotalItems:   fewest.TotalItems,
+			TotalParcels: fewest.Plan.TotalParcels,
+		}
+		if fewest.Plan.TotalParcels > 0 {
+			response.Fewest.Cost = newCostInfo(&fewest.Cost)
+			response.Fewest.Savings = fewest.Cost.Total - plan.Cost.Total
+		}
+	}
+	for _, parcel := range plan.Plan.Parcels {
+		response.Parcels = append(response.Parcels, ParcelInfo{
+			Count:  parcel.Count,
+			Packs:  newPackInfos(parcel.Packs),
+			Weight: parcel.Weight,
+			Volume: (parcel.Volume + 999) / 1000,
+		})
+	}
+	return response
+}
+
+// newCostInfo formats a cost breakdown, nil without one
+func newCostInfo(cost *calculator.CostBreakdown) *CostInfo {
+	if cost == nil {
+		return nil
+	}
+
+	info := &CostInfo{Packaging: cost.Packaging, Shipping: cost.Shipping, Total: cost.Total}
+	for _, parcel := range cost.Parcels {
+		info.Parcels = append(info.Parcels, ParcelCostInfo{
+			Count:  parcel.Count,
+			Weight: parcel.Weight,
+			Fee:    parcel.Fee,
+			Price:  parcel.Price,
+			Total:  parcel.Count * (parcel.Fee + parcel.Price),
+		})
+	}
+	return info
+}
+
+// newCarrierInfo formats a carrier profile
+func newCarrierInfo(carrier models.Carrier) CarrierInfo {
+	return CarrierInfo{Name: carrier.Name, MaxWeight: carrier.MaxWeight, MaxVolume: carrier.MaxVolume}
+}
+
+// shipmentStatus returns the HTTP status for an error of a shipment plan
+func shipmentStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUnknownCarrier):
+		return http.StatusBadRequest
+	case errors.Is(err, calculator.ErrPackTooLarge), errors.Is(err, calculator.ErrNoRate):
+		return http.StatusUnprocessableEntity
+	default:
+		return calculationStatus(err)
+	}
+}
+
+// planShipment validates a shipment request and plans it
+func (h *Handler) planShipment(req *PlanShipmentRequest) (ShipmentPlanResponse, int, error) {
+	if req.ItemsOrdered <= 0 {
+		return ShipmentPlanResponse{}, http.StatusBadRequest, errors.New("Items ordered must be greater than 0")
+	}
+
+	options, err := h.resolveOptions(req.OptionsRequest)
+	if err != nil {
+		return ShipmentPlanResponse{}, http.StatusBadRequest, err
+	}
+	objective, err := calculator.ParseObjective(req.Objective)
+	if err != nil {
+		return ShipmentPlanResponse{}, http.StatusBadRequest, err
+	}
+
+	plan, err := h.PackService.PlanShipment(req.ItemsOrdered, options, req.Carrier, objective)
+	if err != nil {
+		return ShipmentPlanResponse{}, shipmentStatus(err), err
+	}
+	return newShipmentPlanResponse(plan), http.StatusOK, nil
+}
+
+// PlanShipment calculates the optimal packs for an order and assigns them to the fewest parcels
+func (h *Handler) PlanShipment(c echo.Context) error {
+	req := new(PlanShipmentRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+
+	response, status, err := h.planShipment(req)
+	if err != nil {
+		return c.JSON(status, models.NewErrorResponse(err.Error()))
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetCarriers returns all carrier profiles
+func (h *Handler) GetCarriers(c echo.Context) error {
+	carriers, err := h.PackService.GetCarriers()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.NewErrorResponse(err.Error()))
+	}
+
+	infos := make([]CarrierInfo, len(carriers))
+	for i, carrier := range carriers {
+		infos[i] = newCarrierInfo(carrier)
+	}
+	return c.JSON(http.StatusOK, infos)
+}
+
+// AddCarrier adds a new carrier profile
+func (h *Handler) AddCarrier(c echo.Context) error {
+	req := new(CarrierInfo)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+
+	if req.Name == "" {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Carrier name is required"))
+	}
+	if req.MaxWeight < 0 || req.MaxVolume < 0 {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Parcel limits must not be negative"))
+	}
+
+	carrier := models.Carrier{Name: req.Name, MaxWeight: req.MaxWeight, MaxVolume: req.MaxVolume}
+	if err := h.PackService.AddCarrier(carrier); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusCreated, models.NewSuccessResponse("Carrier added successfully"))
+}
+
+// GetRateCard returns the rate card of a carrier
+func (h *Handler) GetRateCard(c echo.Context) error {
+	rates, err := h.PackService.GetRateCard(c.Param("name"))
+	if err != nil {
+		return c.JSON(shipmentStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	response := RateCardInfo{ParcelFee: rates.ParcelFee, Bands: []RateBandInfo{}}
+	for _, band := range rates.Bands {
+		response.Bands = append(response.Bands, RateBandInfo{MaxWeight: band.MaxWeight, Price: band.Price})
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// SetRateCard replaces the rate card of a carrier, from JSON or from CSV with a max_weight,price header
+// and the parcel fee in ?parcelFee=
+func (h *Handler) SetRateCard(c echo.Context) error {
+	var rates calculator.RateCard
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), "text/csv") {
+		var err error
+		rates, err = parseRateCardCSV(c.Request().Body, c.QueryParam("parcelFee"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+		}
+	} else {
+		req := new(RateCardInfo)
+		if err := c.Bind(req); err != nil {
+			return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+		}
+		rates.ParcelFee = req.ParcelFee
+		for _, band := range req.Bands {
+			rates.Bands = append(rates.Bands, calculator.RateBand{MaxWeight: band.MaxWeight, Price: band.Price})
+		}
+	}
+
+	if err := rates.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+	if err := h.PackService.SetRateCard(c.Param("name"), rates); err != nil {
+		return c.JSON(shipmentStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, models.NewSuccessResponse("Rate card updated successfully"))
+}
+
+// parseRateCardCSV reads weight bands from CSV with a max_weight,price header
+func parseRateCardCSV(body io.Reader, parcelFee string) (calculator.RateCard, error) {
+	var rates calculator.RateCard
+	if parcelFee != "" {
+		fee, err := strconv.Atoi(parcelFee)
+		if err != nil {
+			return calculator.RateCard{}, fmt.Errorf("invalid parcel fee %q", parcelFee)
+		}
+		rates.ParcelFee = fee
+	}
+
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return calculator.RateCard{}, fmt.Errorf("invalid rate card CSV: %v", err)
+	}
+	if len(records) == 0 || records[0][0] != "max_weight" || records[0][1] != "price" {
+		return calculator.RateCard{}, fmt.Errorf("rate card CSV must start with a max_weight,price header")
+	}
+
+	for line, record := range records[1:] {
+		maxWeight, err := strconv.Atoi(record[0])
+		if err != nil {
+			return calculator.RateCard{}, fmt.Errorf("invalid maximum weight %q on line %d", record[0], line+2)
+		}
+		price, err := strconv.Atoi(record[1])
+		if err != nil {
+			return calculator.RateCard{}, fmt.Errorf("invalid price %q on line %d", record[1], line+2)
+		}
+		rates.Bands = append(rates.Bands, calculator.RateBand{MaxWeight: maxWeight, Price: price})
+	}
+	return rates, nil
+}
+
+// ShipmentsPage renders the shipment planning page
+func (h *Handler) ShipmentsPage(c echo.Context) error {
+	carriers, err := h.PackService.GetCarriers()
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, "<div class='error'>Failed to load carriers</div>")
+	}
+
+	return c.Render(http.StatusOK, "shipments.html", map[string]interface{}{
+		"Title":          "Plan Shipment",
+		"Carriers":       carriers,
+		"DefaultCarrier": h.PackService.Carrier,
+	})
+}
+
+// ShipmentsPagePost handles the shipment planning form submission
+func (h *Handler) ShipmentsPagePost(c echo.Context) error {
+	req := new(PlanShipmentRequest)
+	if err := c.Bind(req); err != nil {
+		return c.Render(http.StatusBadRequest, "shipment_plan.html", map[string]interface{}{
+			"Error": "Invalid request",
+		})
+	}
+
+	response, status, err := h.planShipment(req)
+	if err != nil {
+		return c.Render(status, "shipment_plan.html", map[string]interface{}{
+			"Error": err.Error(),
+		})
+	}
+
+	return c.Render(http.StatusOK, "shipment_plan.html", map[string]interface{}{
+		"ItemsOrdered": req.ItemsOrdered,
+		"Plan":         response,
+	})
+}
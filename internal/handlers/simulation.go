@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"packify/internal/export"
+	"packify/internal/models"
+	"packify/internal/services"
+	"packify/pkg/calculator"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxSimulationOrders is the largest number of orders a simulation or an order history may hold
+const maxSimulationOrders = 100000
+
+// maxChangedOrders is the number of changed orders the simulation page lists
+const maxChangedOrders = 100
+
+type SimulationRequest struct {
+	// CandidateSizes is the pack catalogue to compare with the available pack sizes
+	CandidateSizes []int `json:"candidateSizes"`
+	// Orders are the quantities of the orders to simulate, empty to simulate a stored order history
+	Orders []int `json:"orders"`
+	// OrderHistory names the stored order history to simulate when no orders are given
+	OrderHistory string `json:"orderHistory"`
+	// Calculation options overriding the configured ones
+	OptionsRequest
+}
+
+type SimulationPagePostRequest struct {
+	CandidateSizes string `form:"candidateSizes"` // Pack sizes separated by commas or spaces
+	OrderHistory   string `form:"orderHistory"`   // Stored order history to simulate when no file is uploaded
+	SaveAs         string `form:"saveAs"`         // Name to store uploaded orders under, empty not to store them
+	OptionsRequest
+}
+
+// OrderHistoryRequest is the orders of an order history
+type OrderHistoryRequest struct {
+	Orders []int `json:"orders"`
+}
+
+// SimulationResponse Format simulation
+type SimulationResponse struct {
+	CurrentSizes   []int                 `json:"currentSizes"`
+	CandidateSizes []int                 `json:"candidateSizes"`
+	Current        SimulationTotalsInfo  `json:"current"`
+	Candidate      SimulationTotalsInfo  `json:"candidate"`
+	ExcessSaved    int                   `json:"excessSaved"` // Negative if the candidate sizes add excess
+	PacksSaved     int                   `json:"packsSaved"`  // Negative if the candidate sizes need more packs
+	Improved       int                   `json:"improved"`    // Orders the candidate sizes pack better
+	Worsened       int                   `json:"worsened"`    // Orders the candidate sizes pack worse
+	Unchanged      int                   `json:"unchanged"`   // Orders packed differently but as well, or the same
+	Orders         []OrderComparisonInfo `json:"orders"`
+}
+
+// SimulationTotalsInfo is the packings of all orders of a simulation added up
+type SimulationTotalsInfo struct {
+	Orders      int `json:"orders"`
+	TotalPacks  int `json:"totalPacks"`
+	TotalItems  int `json:"totalItems"`
+	ExcessItems int `json:"excessItems"`
+	ShortItems  int `json:"shortItems"`
+	ExactOrders int `json:"exactOrders"` // Orders packed without excess or short items
+}
+
+// OrderComparisonInfo is the packing of one order with the current and the candidate pack sizes
+type OrderComparisonInfo struct {
+	ItemsOrdered int               `json:"itemsOrdered"`
+	Current      CalculateResponse `json:"current"`
+	Candidate    CalculateResponse `json:"candidate"`
+	ExcessSaved  int               `json:"excessSaved"`
+	PacksSaved   int               `json:"packsSaved"`
+	Changed      bool              `json:"changed"` // Whether the candidate sizes pack the order differently
+}
+
+// OrderHistoryInfo is a stored order history and the number of orders it holds
+type OrderHistoryInfo struct {
+	Name   string `json:"name"`
+	Orders int    `json:"orders"`
+}
+
+// newSimulationResponse formats a simulation for the API and templates
+func newSimulationResponse(simulation *calculator.Simulation) SimulationResponse {
+	response := SimulationResponse{
+		CurrentSizes:   simulation.CurrentPackSizes,
+		CandidateSizes: simulation.CandidatePackSizes,
+		Current:        newSimulationTotalsInfo(simulation.Current, len(simulation.Orders)),
+		Candidate:      newSimulationTotalsInfo(simulation.Candidate, len(simulation.Orders)),
+		ExcessSaved:    simulation.ExcessSaved,
+		PacksSaved:     simulation.PacksSaved,
+		Improved:       simulation.Improved,
+		Worsened:       simulation.Worsened,
+		Unchanged:      len(simulation.Orders) - simulation.Improved - simulation.Worsened,
+		Orders:         make([]OrderComparisonInfo, len(simulation.Orders)),
+	}
+	for i := range simulation.Orders {
+		order := &simulation.Orders[i]
+		response.Orders[i] = OrderComparisonInfo{
+			ItemsOrdered: order.ItemsOrdered,
+			Current:      newCalculateResponse(&order.Current),
+			Candidate:    newCalculateResponse(&order.Candidate),
+			ExcessSaved:  order.ExcessSaved,
+			PacksSaved:   order.PacksSaved,
+			Changed:      order.Changed,
+		}
+	}
+	return response
+}
+
+// newSimulationTotalsInfo formats the totals of a simulation
+func newSimulationTotalsInfo(totals calculator.SimulationTotals, orders int) SimulationTotalsInfo {
+	return SimulationTotalsInfo{
+		Orders:      orders,
+		TotalPacks:  totals.TotalPacks,
+		TotalItems:  totals.TotalItems,
+		ExcessItems: totals.ExcessItems,
+		ShortItems:  totals.ShortItems,
+		ExactOrders: totals.ExactOrders,
+	}
+}
+
+// Changed returns the first orders the candidate sizes pack differently, for templates
+func (r SimulationResponse) Changed(limit int) []OrderComparisonInfo {
+	var changed []OrderComparisonInfo
+	for _, order := range r.Orders {
+		if len(changed) == limit {
+			break
+		}
+		if order.Changed {
+			changed = append(changed, order)
+		}
+	}
+	return changed
+}
+
+// ChangedCount returns the number of orders the candidate sizes pack differently, for templates
+func (r SimulationResponse) ChangedCount() int {
+	count := 0
+	for _, order := range r.Orders {
+		if order.Changed {
+			count++
+		}
+	}
+	return count
+}
+
+// simulationStatus returns the HTTP status for an error of a simulation
+func simulationStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUnknownOrderHistory):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidOrders):
+		return http.StatusBadRequest
+	default:
+		return calculationStatus(err)
+	}
+}
+
+// validateOrderQuantities checks that there are orders, not too many, and that each has items
+func validateOrderQuantities(orders []int) error {
+	if len(orders) == 0 {
+		return errors.New("At least one order is required")
+	}
+	if len(orders) > maxSimulationOrders {
+		return fmt.Errorf("At most %d orders are accepted, got %d", maxSimulationOrders, len(orders))
+	}
+	for _, itemsOrdered := range orders {
+		if itemsOrdered <= 0 {
+			return fmt.Errorf("Items of every order must be greater than 0, got %d", itemsOrdered)
+		}
+	}
+	return nil
+}
+
+// simulate validates a simulation request and runs it
+func (h *Handler) simulate(req *SimulationRequest) (SimulationResponse, int, error) {
+	if len(req.CandidateSizes) == 0 {
+		return SimulationResponse{}, http.StatusBadRequest, errors.New("Candidate pack sizes are required")
+	}
+	for _, size := range req.CandidateSizes {
+		if size <= 0 {
+			return SimulationResponse{}, http.StatusBadRequest, errors.New("Candidate pack sizes must be positive")
+		}
+	}
+	if len(req.Orders) > 0 && req.OrderHistory != "" {
+		return SimulationResponse{}, http.StatusBadRequest, errors.New("Give either orders or an order history, not both")
+	}
+	if req.OrderHistory == "" {
+		if err := validateOrderQuantities(req.Orders); err != nil {
+			return SimulationResponse{}, http.StatusBadRequest, err
+		}
+	}
+
+	options, err := h.resolveOptions(req.OptionsRequest)
+	if err != nil {
+		return SimulationResponse{}, http.StatusBadRequest, err
+	}
+
+	simulation, err := h.PackService.SimulatePackSets(req.CandidateSizes, req.Orders, req.OrderHistory, options)
+	if err != nil {
+		return SimulationResponse{}, simulationStatus(err), err
+	}
+	return newSimulationResponse(simulation), http.StatusOK, nil
+}
+
+// SimulatePackSets compares packing historical orders with the available pack sizes and with candidate ones
+func (h *Handler) SimulatePackSets(c echo.Context) error {
+	req := new(SimulationRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+
+	response, status, err := h.simulate(req)
+	if err != nil {
+		return c.JSON(status, models.NewErrorResponse(err.Error()))
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetOrderHistories returns the stored order histories
+func (h *Handler) GetOrderHistories(c echo.Context) error {
+	histories, err := h.PackService.GetOrderHistories()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.NewErrorResponse(err.Error()))
+	}
+
+	infos := make([]OrderHistoryInfo, len(histories))
+	for i, history := range histories {
+		infos[i] = OrderHistoryInfo{Name: history.Name, Orders: history.Orders}
+	}
+	return c.JSON(http.StatusOK, infos)
+}
+
+// SaveOrderHistory replaces the orders of an order history, from JSON or from CSV with a quantity per line
+func (h *Handler) SaveOrderHistory(c echo.Context) error {
+	var orders []int
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), "text/csv") {
+		var err error
+		orders, err = export.ReadOrders(c.Request().Body)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+		}
+	} else {
+		req := new(OrderHistoryRequest)
+		if err := c.Bind(req); err != nil {
+			return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+		}
+		orders = req.Orders
+	}
+
+	if err := validateOrderQuantities(orders); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+	if err := h.PackService.SaveOrderHistory(c.Param("name"), orders); err != nil {
+		return c.JSON(simulationStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, models.NewSuccessResponse("Order history saved successfully"))
+}
+
+// parsePackSizes reads pack sizes separated by commas or spaces, such as "250, 500, 750"
+func parsePackSizes(value string) ([]int, error) {
+	var sizes []int
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		size, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid pack size %q", field)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// SimulationsPage renders the pack catalogue simulation page
+func (h *Handler) SimulationsPage(c echo.Context) error {
+	histories, err := h.PackService.GetOrderHistories()
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, "<div class='error'>Failed to load order histories</div>")
+	}
+	packSizes, err := h.PackService.GetPackSizes()
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, "<div class='error'>Failed to load pack sizes</div>")
+	}
+
+	sort.Slice(packSizes, func(i, j int) bool {
+		return packSizes[i].Size < packSizes[j].Size
+	})
+	sizes := make([]string, len(packSizes))
+	for i, packSize := range packSizes {
+		sizes[i] = strconv.Itoa(packSize.Size)
+	}
+	return c.Render(http.StatusOK, "simulations.html", map[string]interface{}{
+		"Title":          "Simulate Pack Sizes",
+		"OrderHistories": histories,
+		"CurrentSizes":   strings.Join(sizes, ", "),
+	})
+}
+
+// SimulationsPagePost handles the simulation form submission. Orders come from an uploaded CSV file, which is
+// stored as an order history when the form names one, or from a stored order history.
+func (h *Handler) SimulationsPagePost(c echo.Context) error {
+	renderError := func(status int, message string) error {
+		return c.Render(status, "simulation_result.html", map[string]interface{}{
+			"Error": message,
+		})
+	}
+
+	form := new(SimulationPagePostRequest)
+	if err := c.Bind(form); err != nil {
+		return renderError(http.StatusBadRequest, "Invalid request")
+	}
+	candidateSizes, err := parsePackSizes(form.CandidateSizes)
+	if err != nil {
+		return renderError(http.StatusBadRequest, err.Error())
+	}
+	req := &SimulationRequest{CandidateSizes: candidateSizes, OptionsRequest: form.OptionsRequest}
+
+	file, err := c.FormFile("orders")
+	switch {
+	case err == nil && file.Size > 0:
+		upload, err := file.Open()
+		if err != nil {
+			return renderError(http.StatusBadRequest, "Failed to read the uploaded orders")
+		}
+		defer upload.Close()
+		if req.Orders, err = export.ReadOrders(upload); err != nil {
+			return renderError(http.StatusBadRequest, err.Error())
+		}
+		if err := validateOrderQuantities(req.Orders); err != nil {
+			return renderError(http.StatusBadRequest, err.Error())
+		}
+		if name := strings.TrimSpace(form.SaveAs); name != "" {
+			if err := h.PackService.SaveOrderHistory(name, req.Orders); err != nil {
+				return renderError(simulationStatus(err), err.Error())
+			}
+		}
+	case form.OrderHistory != "":
+		req.OrderHistory = form.OrderHistory
+	default:
+		return renderError(http.StatusBadRequest, "Upload orders or choose an order history")
+	}
+
+	response, status, err := h.simulate(req)
+	if err != nil {
+		return renderError(status, err.Error())
+	}
+
+	return c.Render(http.StatusOK, "simulation_result.html", map[string]interface{}{
+		"Simulation":       response,
+		"MaxChangedOrders": maxChangedOrders,
+	})
+}
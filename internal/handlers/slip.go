@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"packify/internal/models"
+	"packify/internal/printing"
+	"packify/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CalculationSlipRequest struct {
+	ItemsOrdered int `query:"itemsOrdered"`
+	// Reference is printed on the slip as a barcode, empty for none
+	Reference string `query:"reference"`
+	// Calculation options overriding the configured ones
+	OptionsRequest
+}
+
+// slipStatus maps a slip error to an HTTP status
+func slipStatus(err error) int {
+	if errors.Is(err, services.ErrOrderNotCalculated) {
+		return http.StatusConflict
+	}
+	return orderStatus(err)
+}
+
+// printFilename returns the name of a printed document of a slip, with the characters of its reference that
+// are safe in a file name, e.g. packing-slip-SO-1042.pdf
+func printFilename(document string, slip *printing.Slip, extension string) string {
+	reference := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return -1
+	}, slip.Reference)
+	if reference == "" {
+		return fmt.Sprintf("%s-%d.%s", document, slip.ItemsOrdered, extension)
+	}
+	return fmt.Sprintf("%s-%s.%s", document, reference, extension)
+}
+
+// writeSlip downloads a slip as PDF. The PDF is rendered before the response is written, so a failure is still
+// reported as JSON.
+func writeSlip(c echo.Context, slip *printing.Slip) error {
+	var pdf bytes.Buffer
+	if err := printing.WriteSlipPDF(&pdf, *slip); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.NewErrorResponse(err.Error()))
+	}
+
+	filename := printFilename("packing-slip", slip, "pdf")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	return c.Blob(http.StatusOK, "application/pdf", pdf.Bytes())
+}
+
+// CalculationSlip downloads the packing slip and pick list of a calculation as PDF
+func (h *Handler) CalculationSlip(c echo.Context) error {
+	req := new(CalculationSlipRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+	if req.ItemsOrdered <= 0 {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Items ordered must be greater than 0"))
+	}
+
+	options, err := h.resolveOptions(req.OptionsRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	slip, err := h.PackService.CalculationSlip(req.ItemsOrdered, strings.TrimSpace(req.Reference), options)
+	if err != nil {
+		return c.JSON(calculationStatus(err), models.NewErrorResponse(err.Error()))
+	}
+	return writeSlip(c, slip)
+}
+
+// OrderSlip downloads the packing slip and pick list of an order as PDF
+func (h *Handler) OrderSlip(c echo.Context) error {
+	id, err := pathID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	slip, err := h.PackService.OrderSlip(id)
+	if err != nil {
+		return c.JSON(slipStatus(err), models.NewErrorResponse(err.Error()))
+	}
+	return writeSlip(c, slip)
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"packify/internal/models"
+	"packify/pkg/calculator"
+
+	"github.com/labstack/echo/v4"
+)
+
+type SuggestionsRequest struct {
+	ItemsOrdered int `query:"items"`
+	OptionsRequest
+}
+
+// SuggestionsResponse Format quantity suggestions
+type SuggestionsResponse struct {
+	ItemsOrdered    int               `json:"itemsOrdered"`
+	Result          CalculateResponse `json:"result"`
+	Below           *SuggestionInfo   `json:"below"`           // Nearest smaller quantity without excess
+	Above           *SuggestionInfo   `json:"above"`           // Nearest larger quantity without excess
+	FewerPacksBelow *SuggestionInfo   `json:"fewerPacksBelow"` // Nearest smaller quantity without excess in fewer packs
+	FewerPacksAbove *SuggestionInfo   `json:"fewerPacksAbove"` // Nearest larger quantity without excess in fewer packs
+}
+
+// SuggestionInfo is an order quantity that packs without excess
+type SuggestionInfo struct {
+	ItemsOrdered int        `json:"itemsOrdered"`
+	Packs        []PackInfo `json:"packs"`
+	TotalPacks   int        `json:"totalPacks"`
+	ExcessSaved  int        `json:"excessSaved"` // Excess items of the order avoided
+	PacksSaved   int        `json:"packsSaved"`  // Packs saved over the order, negative if more are needed
+}
+
+// newSuggestionsResponse formats quantity suggestions for the API and templates
+func newSuggestionsResponse(itemsOrdered int, suggestions *calculator.Suggestions) SuggestionsResponse {
+	return SuggestionsResponse{
+		ItemsOrdered:    itemsOrdered,
+		Result:          newCalculateResponse(&suggestions.Result),
+		Below:           newSuggestionInfo(suggestions.Below),
+		Above:           newSuggestionInfo(suggestions.Above),
+		FewerPacksBelow: newSuggestionInfo(suggestions.FewerPacksBelow),
+		FewerPacksAbove: newSuggestionInfo(suggestions.FewerPacksAbove),
+	}
+}
+
+// newSuggestionInfo formats a suggestion, nil without one
+func newSuggestionInfo(suggestion *calculator.Suggestion) *SuggestionInfo {
+	if suggestion == nil {
+		return nil
+	}
+	return &SuggestionInfo{
+		ItemsOrdered: suggestion.ItemsOrdered,
+		Packs:        newPackInfos(suggestion.Result.PackCounts),
+		TotalPacks:   suggestion.Result.TotalPacks,
+		ExcessSaved:  suggestion.ExcessSaved,
+		PacksSaved:   suggestion.PacksSaved,
+	}
+}
+
+// List returns the distinct suggestions by quantity, for templates
+func (r SuggestionsResponse) List() []SuggestionInfo {
+	var list []SuggestionInfo
+	seen := make(map[int]bool)
+	for _, suggestion := range []*SuggestionInfo{r.FewerPacksBelow, r.Below, r.Above, r.FewerPacksAbove} {
+		if suggestion != nil && !seen[suggestion.ItemsOrdered] {
+			seen[suggestion.ItemsOrdered] = true
+			list = append(list, *suggestion)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ItemsOrdered < list[j].ItemsOrdered
+	})
+	return list
+}
+
+// suggest finds the quantities near an order that pack without excess, nil for orders too large to look around
+func (h *Handler) suggest(itemsOrdered Quantity, options calculator.Options) *SuggestionsResponse {
+	n, ok := itemsOrdered.Int()
+	if !ok || n > calculator.MaxSuggestionItems {
+		return nil
+	}
+
+	suggestions, err := h.PackService.SuggestQuantities(n, options)
+	if err != nil {
+		return nil
+	}
+	response := newSuggestionsResponse(n, suggestions)
+	return &response
+}
+
+// SuggestQuantities returns the nearest quantities below and above an order that pack without excess
+func (h *Handler) SuggestQuantities(c echo.Context) error {
+	req := new(SuggestionsRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Invalid request"))
+	}
+
+	if req.ItemsOrdered <= 0 {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse("Items ordered must be greater than 0"))
+	}
+	if req.ItemsOrdered > calculator.MaxSuggestionItems {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			fmt.Sprintf("Suggestions are only available for orders of up to %d items", calculator.MaxSuggestionItems)))
+	}
+
+	options, err := h.resolveOptions(req.OptionsRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err.Error()))
+	}
+
+	suggestions, err := h.PackService.SuggestQuantities(req.ItemsOrdered, options)
+	if err != nil {
+		return c.JSON(calculationStatus(err), models.NewErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, newSuggestionsResponse(req.ItemsOrdered, suggestions))
+}
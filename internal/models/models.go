@@ -1,19 +1,253 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 // PackSize represents a pack size option
 type PackSize struct {
 	gorm.Model
-	Size int `gorm:"not null;uniqueIndex:idx_size_deleted_at"`
+	Size   int `gorm:"not null;uniqueIndex:idx_size_deleted_at"`
+	Weight int `gorm:"not null;default:0"` // Grams, 0 if unknown
+	Length int `gorm:"not null;default:0"` // Millimetres, 0 if unknown
+	Width  int `gorm:"not null;default:0"` // Millimetres, 0 if unknown
+	Height int `gorm:"not null;default:0"` // Millimetres, 0 if unknown
+	Cost   int `gorm:"not null;default:0"` // Cents of packaging, 0 if unknown
+	// Stock and supplier lead time of the packaging, for reorder points
+	StockOnHand  int `gorm:"not null;default:0"` // Empty packs in stock
+	LeadTimeDays int `gorm:"not null;default:0"` // Days from ordering packs to receiving them, 0 if unknown
+	Reserved     int `gorm:"not null;default:0"` // Packs in stock held for confirmed orders
+	// Shelf or bin the packs are picked from, empty if unknown
+	Shelf string `gorm:"not null;default:''"`
+}
+
+// Available returns the packs in stock that are not reserved
+func (p PackSize) Available() int {
+	return p.StockOnHand - p.Reserved
+}
+
+// Volume returns the volume of a pack in cubic millimetres
+func (p PackSize) Volume() int {
+	return p.Length * p.Width * p.Height
+}
+
+// Carrier is a carrier profile with the largest parcel it accepts and its rate card
+type Carrier struct {
+	gorm.Model
+	Name      string     `gorm:"not null;uniqueIndex:idx_carrier_name_deleted_at"`
+	MaxWeight int        `gorm:"not null;default:0"` // Grams per parcel, 0 for no limit
+	MaxVolume int        `gorm:"not null;default:0"` // Cubic centimetres per parcel, 0 for no limit
+	ParcelFee int        `gorm:"not null;default:0"` // Cents per parcel on top of the weight band
+	Rates     []RateBand `gorm:"foreignKey:CarrierID"`
+}
+
+// RateBand is the price a carrier charges for a parcel weighing up to MaxWeight
+type RateBand struct {
+	gorm.Model
+	CarrierID uint `gorm:"not null;index"`
+	MaxWeight int  `gorm:"not null"` // Grams, inclusive
+	Price     int  `gorm:"not null"` // Cents per parcel
+}
+
+// Warehouse is a stock location and the packs it holds
+type Warehouse struct {
+	gorm.Model
+	Name  string           `gorm:"not null;uniqueIndex:idx_warehouse_name_deleted_at"`
+	Stock []WarehouseStock `gorm:"foreignKey:WarehouseID"`
+}
+
+// WarehouseStock is the number of packs of a size a warehouse holds
+type WarehouseStock struct {
+	gorm.Model
+	WarehouseID uint `gorm:"not null;index"`
+	Size        int  `gorm:"not null"`
+	Quantity    int  `gorm:"not null;default:0"`
+}
+
+// OrderHistory is a named list of past orders pack size changes can be simulated against
+type OrderHistory struct {
+	gorm.Model
+	Name   string            `gorm:"not null;uniqueIndex:idx_order_history_name_deleted_at"`
+	Orders []HistoricalOrder `gorm:"foreignKey:OrderHistoryID"`
+}
+
+// HistoricalOrder is the quantity of one past order
+type HistoricalOrder struct {
+	gorm.Model
+	OrderHistoryID uint `gorm:"not null;index"`
+	ItemsOrdered   int  `gorm:"not null"`
+}
+
+// OrderHistorySummary is the name of an order history and the number of orders it holds
+type OrderHistorySummary struct {
+	Name   string
+	Orders int
+}
+
+// Reservation statuses, a reservation is created reserved and ends released or shipped
+const (
+	ReservationReserved = "reserved" // The packs are held in stock
+	ReservationReleased = "released" // The order was cancelled and its packs returned to available stock
+	ReservationShipped  = "shipped"  // The packs left the stock
+)
+
+// Reservation holds the packs of a confirmed order in stock until the order ships or is cancelled
+type Reservation struct {
+	gorm.Model
+	ItemsOrdered int            `gorm:"not null"`
+	Status       string         `gorm:"not null;index"`
+	Packs        []ReservedPack `gorm:"foreignKey:ReservationID"`
+}
+
+// ReservedPack is the number of packs of one size a reservation holds
+type ReservedPack struct {
+	gorm.Model
+	ReservationID uint `gorm:"not null;index"`
+	Size          int  `gorm:"not null"`
+	Count         int  `gorm:"not null"`
+}
+
+// Order statuses, see the order state machine of the services
+const (
+	OrderDraft      = "draft"      // Created, not calculated yet
+	OrderCalculated = "calculated" // Its optimal packs are calculated
+	OrderConfirmed  = "confirmed"  // Its packs are reserved in stock
+	OrderPacked     = "packed"     // Its packs are filled
+	OrderShipped    = "shipped"    // Its packs left the stock
+	OrderCancelled  = "cancelled"  // Abandoned, any reserved packs were released
+)
+
+// OrderStatuses lists the order statuses in lifecycle order
+var OrderStatuses = []string{OrderDraft, OrderCalculated, OrderConfirmed, OrderPacked, OrderShipped, OrderCancelled}
+
+// Order is a customer order and the packing calculated for it
+type Order struct {
+	gorm.Model
+	Reference     string      `gorm:"not null;uniqueIndex:idx_order_reference_deleted_at"` // Reference of the order in the shop or ERP
+	Customer      string      `gorm:"not null"`
+	ItemsOrdered  int         `gorm:"not null"`
+	Status        string      `gorm:"not null;index"`
+	Packs         []OrderPack `gorm:"foreignKey:OrderID"`
+	TotalPacks    int         `gorm:"not null;default:0"`
+	TotalItems    int         `gorm:"not null;default:0"`
+	ExcessItems   int         `gorm:"not null;default:0"`
+	ShortItems    int         `gorm:"not null;default:0"`
+	ReservationID *uint       // Packs reserved for the order once it is confirmed
+}
+
+// OrderPack is the number of packs of one size calculated for an order
+type OrderPack struct {
+	gorm.Model
+	OrderID uint `gorm:"not null;index"`
+	Size    int  `gorm:"not null"`
+	Count   int  `gorm:"not null"`
+}
+
+// PackCounts returns the packs calculated for an order by size
+func (o Order) PackCounts() map[int]int {
+	packCounts := make(map[int]int, len(o.Packs))
+	for _, pack := range o.Packs {
+		packCounts[pack.Size] += pack.Count
+	}
+	return packCounts
+}
+
+// Label field kinds
+const (
+	LabelText    = "text"    // A line of text, Height is the font height and Width the character width, 0 for Height
+	LabelBarcode = "barcode" // A Code 128 barcode and its text, Height is the bar height and Width the narrowest bar
+	LabelBox     = "box"     // A box outline, Thickness is the width of its border
+)
+
+// LabelTemplate is the layout of the thermal label printed for each pack, in printer dots
+type LabelTemplate struct {
+	gorm.Model
+	Name   string       `gorm:"not null;uniqueIndex:idx_label_template_name_deleted_at"`
+	Width  int          `gorm:"not null"` // Dots
+	Height int          `gorm:"not null"` // Dots
+	Fields []LabelField `gorm:"foreignKey:LabelTemplateID"`
+}
+
+// LabelField is a text, barcode or box placed on a label. The text of texts and barcodes may hold
+// placeholders, such as {reference}, filled in for each pack.
+type LabelField struct {
+	gorm.Model
+	LabelTemplateID uint   `gorm:"not null;index"`
+	Kind            string `gorm:"not null"`
+	X               int    `gorm:"not null"` // Dots from the left edge
+	Y               int    `gorm:"not null"` // Dots from the top edge
+	Width           int    `gorm:"not null;default:0"`
+	Height          int    `gorm:"not null;default:0"`
+	Thickness       int    `gorm:"not null;default:0"`
+	Content         string `gorm:"not null;default:''"`
+}
+
+// Calculation is a pack calculation served by the API or the calculate page, recorded for the dashboard.
+// Quantities are numeric as orders of any size can be calculated.
+type Calculation struct {
+	gorm.Model
+	ItemsOrdered string            `gorm:"type:numeric;not null"`
+	TotalItems   string            `gorm:"type:numeric;not null"`
+	TotalPacks   string            `gorm:"type:numeric;not null"`
+	ExcessItems  int               `gorm:"not null;default:0"`
+	ShortItems   int               `gorm:"not null;default:0"`
+	Algorithm    string            `gorm:"not null"`
+	Packs        []CalculationPack `gorm:"foreignKey:CalculationID"`
+}
+
+// CalculationPack is the number of packs of one size a calculation shipped
+type CalculationPack struct {
+	gorm.Model
+	CalculationID uint   `gorm:"not null;index"`
+	Size          int    `gorm:"not null"`
+	Count         string `gorm:"type:numeric;not null"`
+}
+
+// DailyCalculations sums the calculations of one day
+type DailyCalculations struct {
+	Day          string  // YYYY-MM-DD
+	Orders       int     // Calculations
+	ItemsOrdered float64 // Approximate beyond 2^53 items
+	ExcessItems  int
+	ShortItems   int
+}
+
+// ExcessPercent returns the excess items as a percentage of the items ordered, 0 without orders
+func (d DailyCalculations) ExcessPercent() float64 {
+	if d.ItemsOrdered == 0 {
+		return 0
+	}
+	return float64(d.ExcessItems) * 100 / d.ItemsOrdered
+}
+
+// PackUsage sums the packs of one size shipped by calculations
+type PackUsage struct {
+	Size   int
+	Packs  float64 // Approximate beyond 2^53 packs
+	Orders int     // Calculations shipping the size
+}
+
+// DailyPackConsumption is the packs of one size shipped by the calculations of one day
+type DailyPackConsumption struct {
+	Day   string // YYYY-MM-DD
+	Size  int
+	Packs float64 // Approximate beyond 2^53 packs
+}
+
+// AlgorithmUsage is the number of calculations run with an algorithm
+type AlgorithmUsage struct {
+	Algorithm string
+	Orders    int
 }
 
 // SetupDatabase initializes the database with default pack sizes
 func SetupDatabase(db *gorm.DB) error {
 	// Auto migrate the schemas
-	err := db.AutoMigrate(&PackSize{})
+	err := db.AutoMigrate(&PackSize{}, &Carrier{}, &RateBand{}, &Warehouse{}, &WarehouseStock{}, &OrderHistory{}, &HistoricalOrder{},
+		&Calculation{}, &CalculationPack{}, &Reservation{}, &ReservedPack{},
+		&Order{}, &OrderPack{}, &LabelTemplate{}, &LabelField{})
 	if err != nil {
 		return err
 	}
@@ -24,11 +258,11 @@ func SetupDatabase(db *gorm.DB) error {
 	if count == 0 {
 		// Create default pack sizes
 		defaultPackSizes := []PackSize{
-			{Size: 250},
-			{Size: 500},
-			{Size: 1000},
-			{Size: 2000},
-			{Size: 5000},
+			{Size: 250, Weight: 300, Length: 200, Width: 150, Height: 100, Cost: 40},
+			{Size: 500, Weight: 560, Length: 250, Width: 200, Height: 120, Cost: 60},
+			{Size: 1000, Weight: 1100, Length: 300, Width: 250, Height: 160, Cost: 90},
+			{Size: 2000, Weight: 2200, Length: 400, Width: 300, Height: 200, Cost: 140},
+			{Size: 5000, Weight: 5400, Length: 600, Width: 400, Height: 250, Cost: 250},
 		}
 
 		// Insert default pack sizes
@@ -39,9 +273,48 @@ func SetupDatabase(db *gorm.DB) error {
 		}
 	}
 
+	// Create the default carrier profile and its rate card
+	db.Model(&Carrier{}).Count(&count)
+	if count == 0 {
+		carrier := Carrier{
+			Name:      "standard",
+			MaxWeight: 31500,
+			MaxVolume: 100000,
+			ParcelFee: 150,
+			Rates: []RateBand{
+				{MaxWeight: 2000, Price: 450},
+				{MaxWeight: 5000, Price: 650},
+				{MaxWeight: 10000, Price: 900},
+				{MaxWeight: 20000, Price: 1300},
+				{MaxWeight: 31500, Price: 1800},
+			},
+		}
+		if err := db.Create(&carrier).Error; err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// GetCarrier returns the carrier profile with a name and its rate bands, lightest first
+func GetCarrier(db *gorm.DB, name string) (Carrier, error) {
+	var carrier Carrier
+	err := db.Preload("Rates", func(db *gorm.DB) *gorm.DB {
+		return db.Order("max_weight")
+	}).Where("name = ?", name).First(&carrier).Error
+	return carrier, err
+}
+
+// GetWarehouses returns all warehouses and their stock in the order they were added
+func GetWarehouses(db *gorm.DB) ([]Warehouse, error) {
+	var warehouses []Warehouse
+	err := db.Preload("Stock", func(db *gorm.DB) *gorm.DB {
+		return db.Order("size DESC")
+	}).Order("id").Find(&warehouses).Error
+	return warehouses, err
+}
+
 // GetPackSizes returns all available pack sizes in descending order
 func GetPackSizes(db *gorm.DB) ([]int, error) {
 	var packSizes []PackSize
@@ -80,3 +353,141 @@ func NewSuccessResponse(message string) SuccessResponse {
 		Message: message,
 	}
 }
+
+// GetOrderHistory returns the order history with a name and its orders in the order they were listed
+func GetOrderHistory(db *gorm.DB, name string) (OrderHistory, error) {
+	var history OrderHistory
+	err := db.Preload("Orders", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("name = ?", name).First(&history).Error
+	return history, err
+}
+
+// GetOrderHistories returns the name and number of orders of every order history, by name
+func GetOrderHistories(db *gorm.DB) ([]OrderHistorySummary, error) {
+	var summaries []OrderHistorySummary
+	err := db.Model(&OrderHistory{}).
+		Select("order_histories.name, count(historical_orders.id) AS orders").
+		Joins("LEFT JOIN historical_orders ON historical_orders.order_history_id = order_histories.id " +
+			"AND historical_orders.deleted_at IS NULL").
+		Group("order_histories.id, order_histories.name").
+		Order("order_histories.name").
+		Scan(&summaries).Error
+	return summaries, err
+}
+
+// GetDailyCalculations sums the calculations recorded from from until to, excluded, by UTC day
+func GetDailyCalculations(db *gorm.DB, from, to time.Time) ([]DailyCalculations, error) {
+	var days []DailyCalculations
+	err := db.Model(&Calculation{}).
+		Select("to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, count(*) AS orders, "+
+			"sum(items_ordered) AS items_ordered, sum(excess_items) AS excess_items, sum(short_items) AS short_items").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("day").
+		Order("day").
+		Scan(&days).Error
+	return days, err
+}
+
+// GetPackUsage sums the packs of each size shipped by the calculations recorded from from until to, excluded,
+// largest size first
+func GetPackUsage(db *gorm.DB, from, to time.Time) ([]PackUsage, error) {
+	var usage []PackUsage
+	err := db.Model(&CalculationPack{}).
+		Select("calculation_packs.size, sum(calculation_packs.count) AS packs, count(*) AS orders").
+		Joins("JOIN calculations ON calculations.id = calculation_packs.calculation_id "+
+			"AND calculations.deleted_at IS NULL").
+		Where("calculations.created_at >= ? AND calculations.created_at < ?", from, to).
+		Group("calculation_packs.size").
+		Order("calculation_packs.size DESC").
+		Scan(&usage).Error
+	return usage, err
+}
+
+// GetAlgorithmUsage counts the calculations recorded from from until to, excluded, by algorithm, most used first
+func GetAlgorithmUsage(db *gorm.DB, from, to time.Time) ([]AlgorithmUsage, error) {
+	var usage []AlgorithmUsage
+	err := db.Model(&Calculation{}).
+		Select("algorithm, count(*) AS orders").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("algorithm").
+		Order("orders DESC, algorithm").
+		Scan(&usage).Error
+	return usage, err
+}
+
+// GetDailyPackConsumption sums the packs of each size shipped by the calculations recorded from from until to,
+// excluded, by UTC day
+func GetDailyPackConsumption(db *gorm.DB, from, to time.Time) ([]DailyPackConsumption, error) {
+	var consumption []DailyPackConsumption
+	err := db.Model(&CalculationPack{}).
+		Select("to_char(calculations.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, calculation_packs.size, "+
+			"sum(calculation_packs.count) AS packs").
+		Joins("JOIN calculations ON calculations.id = calculation_packs.calculation_id "+
+			"AND calculations.deleted_at IS NULL").
+		Where("calculations.created_at >= ? AND calculations.created_at < ?", from, to).
+		Group("day, calculation_packs.size").
+		Order("day").
+		Scan(&consumption).Error
+	return consumption, err
+}
+
+// GetFirstCalculationTime returns when the first calculation was recorded, and false if none was
+func GetFirstCalculationTime(db *gorm.DB) (time.Time, bool, error) {
+	var calculation Calculation
+	err := db.Order("created_at").Limit(1).Find(&calculation).Error
+	if err != nil || calculation.ID == 0 {
+		return time.Time{}, false, err
+	}
+	return calculation.CreatedAt, true, nil
+}
+
+// GetReservation returns a reservation and its packs, largest size first
+func GetReservation(db *gorm.DB, id uint) (Reservation, error) {
+	var reservation Reservation
+	err := db.Preload("Packs", func(db *gorm.DB) *gorm.DB {
+		return db.Order("size DESC")
+	}).First(&reservation, id).Error
+	return reservation, err
+}
+
+// GetOrder returns an order and its packs, largest size first
+func GetOrder(db *gorm.DB, id uint) (Order, error) {
+	var order Order
+	err := db.Preload("Packs", func(db *gorm.DB) *gorm.DB {
+		return db.Order("size DESC")
+	}).First(&order, id).Error
+	return order, err
+}
+
+// GetOrders returns the latest orders with a status, or with any status if it is empty, newest first
+func GetOrders(db *gorm.DB, status string, limit int) ([]Order, error) {
+	query := db.Preload("Packs", func(db *gorm.DB) *gorm.DB {
+		return db.Order("size DESC")
+	}).Order("id DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var orders []Order
+	err := query.Find(&orders).Error
+	return orders, err
+}
+
+// GetLabelTemplate returns the label template with a name and its fields in the order they were listed
+func GetLabelTemplate(db *gorm.DB, name string) (LabelTemplate, error) {
+	var template LabelTemplate
+	err := db.Preload("Fields", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("name = ?", name).First(&template).Error
+	return template, err
+}
+
+// GetLabelTemplates returns every label template and its fields, by name
+func GetLabelTemplates(db *gorm.DB) ([]LabelTemplate, error) {
+	var templates []LabelTemplate
+	err := db.Preload("Fields", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Order("name").Find(&templates).Error
+	return templates, err
+}
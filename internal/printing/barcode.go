@@ -0,0 +1,41 @@
+// Package printing renders the documents warehouse staff print: packing slips, pick lists and pack labels.
+package printing
+
+import (
+	"fmt"
+
+	"github.com/boombuler/barcode/code128"
+)
+
+// Code128 encodes text as a Code 128 barcode, one module per element: true for a bar, false for a space.
+// Code 128 holds 1 to 80 ASCII characters.
+func Code128(text string) ([]bool, error) {
+	code, err := code128.Encode(text)
+	if err != nil {
+		return nil, fmt.Errorf("barcode of %q: %w", text, err)
+	}
+
+	bounds := code.Bounds()
+	modules := make([]bool, bounds.Dx())
+	for x := range modules {
+		r, _, _, _ := code.At(bounds.Min.X+x, bounds.Min.Y).RGBA()
+		modules[x] = r < 0x8000
+	}
+	return modules, nil
+}
+
+// bars returns the runs of bars of a barcode as the index of their first module and their width in modules
+func bars(modules []bool) [][2]int {
+	var runs [][2]int
+	for x := 0; x < len(modules); x++ {
+		if !modules[x] {
+			continue
+		}
+		start := x
+		for x < len(modules) && modules[x] {
+			x++
+		}
+		runs = append(runs, [2]int{start, x - start})
+	}
+	return runs
+}
@@ -0,0 +1,161 @@
+package printing
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image"
+	"image/png"
+	"io"
+
+	"packify/internal/models"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Previews approximate the printer: text is drawn in a generic font scaled to the font height of its field and
+// the text under barcodes in a font about as high as the printer's.
+const (
+	glyphWidth   = 7  // Width of a basicfont glyph, in pixels
+	glyphHeight  = 13 // Height of a basicfont glyph, in pixels
+	glyphAscent  = 11 // Ascent of a basicfont glyph, in pixels
+	barcodeGap   = 4  // Dots between barcode bars and their text
+	barcodeScale = 9  // Height of the text under barcodes, in narrowest bars
+)
+
+// previewElement is a field of a label laid out for a preview, with its placeholders filled in
+type previewElement struct {
+	kind    string
+	x, y    int
+	width   int // Character width of texts, narrowest bar of barcodes, width of boxes
+	height  int // Font height of texts, bar height of barcodes, height of boxes
+	border  int // Border of boxes
+	content string
+	modules []bool // Modules of barcodes
+}
+
+// layoutLabel lays out the fields of a label template for a label. Texts and barcodes left empty by their
+// placeholders and barcodes Code 128 cannot encode are left out.
+func layoutLabel(template models.LabelTemplate, label Label) []previewElement {
+	var elements []previewElement
+	for _, field := range template.Fields {
+		element := previewElement{kind: field.Kind, x: field.X, y: field.Y, width: field.Width, height: field.Height,
+			border: field.Thickness, content: label.fill(field.Content)}
+		switch field.Kind {
+		case models.LabelText:
+			if element.content == "" {
+				continue
+			}
+			element.width = textWidth(field)
+		case models.LabelBarcode:
+			modules, err := Code128(element.content)
+			if err != nil {
+				continue
+			}
+			element.modules = modules
+		}
+		elements = append(elements, element)
+	}
+	return elements
+}
+
+// barcodeTextX returns where the text under a barcode starts, centred under its bars like the printer's
+func barcodeTextX(element previewElement, size int) int {
+	textWidth := len([]rune(element.content)) * size * glyphWidth / glyphHeight
+	return element.x + (len(element.modules)*element.width-textWidth)/2
+}
+
+// WriteLabelSVG writes a preview of a label as SVG, one unit per printer dot
+func WriteLabelSVG(w io.Writer, template models.LabelTemplate, label Label) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		template.Width, template.Height, template.Width, template.Height)
+	fmt.Fprintf(out, `<rect width="%d" height="%d" fill="white"/>`+"\n", template.Width, template.Height)
+
+	for _, element := range layoutLabel(template, label) {
+		switch element.kind {
+		case models.LabelText:
+			writeSVGText(out, element.x, element.y, element.height, element.width, element.content)
+		case models.LabelBarcode:
+			fmt.Fprint(out, `<g fill="black">`)
+			for _, bar := range bars(element.modules) {
+				fmt.Fprintf(out, `<rect x="%d" y="%d" width="%d" height="%d"/>`, element.x+bar[0]*element.width,
+					element.y, bar[1]*element.width, element.height)
+			}
+			fmt.Fprint(out, "</g>\n")
+			size := barcodeScale * element.width
+			writeSVGText(out, barcodeTextX(element, size), element.y+element.height+barcodeGap, size, size, element.content)
+		case models.LabelBox:
+			inset := float64(element.border) / 2
+			fmt.Fprintf(out, `<rect x="%g" y="%g" width="%g" height="%g" fill="none" stroke="black" stroke-width="%d"/>`+"\n",
+				float64(element.x)+inset, float64(element.y)+inset, float64(element.width)-2*inset,
+				float64(element.height)-2*inset, element.border)
+		}
+	}
+
+	fmt.Fprint(out, "</svg>\n")
+	return out.Flush()
+}
+
+// writeSVGText writes a line of text whose top left corner is at x, y
+func writeSVGText(w io.Writer, x, y, height, width int, text string) {
+	fmt.Fprintf(w, `<text x="%d" y="%d" font-family="Helvetica, Arial, sans-serif" font-size="%d"`, x,
+		y+height*glyphAscent/glyphHeight, height)
+	if width != height {
+		// Characters wider or narrower than high are stretched from the left edge
+		fmt.Fprintf(w, ` transform="translate(%d 0) scale(%g 1) translate(%d 0)"`, x, float64(width)/float64(height), -x)
+	}
+	fmt.Fprintf(w, ">%s</text>\n", html.EscapeString(text))
+}
+
+// WriteLabelPNG writes a preview of a label as a PNG in black and white, one pixel per printer dot
+func WriteLabelPNG(w io.Writer, template models.LabelTemplate, label Label) error {
+	img := image.NewGray(image.Rect(0, 0, template.Width, template.Height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	for _, element := range layoutLabel(template, label) {
+		switch element.kind {
+		case models.LabelText:
+			drawPNGText(img, element.x, element.y, element.height, element.width, element.content)
+		case models.LabelBarcode:
+			for _, bar := range bars(element.modules) {
+				fillRect(img, element.x+bar[0]*element.width, element.y, bar[1]*element.width, element.height)
+			}
+			size := barcodeScale * element.width
+			drawPNGText(img, barcodeTextX(element, size), element.y+element.height+barcodeGap, size, size, element.content)
+		case models.LabelBox:
+			x, y, width, height, border := element.x, element.y, element.width, element.height, element.border
+			fillRect(img, x, y, width, border)
+			fillRect(img, x, y+height-border, width, border)
+			fillRect(img, x, y, border, height)
+			fillRect(img, x+width-border, y, border, height)
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// fillRect paints a rectangle black, clipped to the image
+func fillRect(img *image.Gray, x, y, width, height int) {
+	draw.Draw(img, image.Rect(x, y, x+width, y+height).Intersect(img.Bounds()), image.Black, image.Point{}, draw.Src)
+}
+
+// drawPNGText draws a line of text whose top left corner is at x, y. The text is drawn in a bitmap font and
+// scaled to the font height and character width.
+func drawPNGText(img *image.Gray, x, y, height, width int, text string) {
+	runes := []rune(text)
+	glyphs := image.NewRGBA(image.Rect(0, 0, len(runes)*glyphWidth, glyphHeight))
+	drawer := font.Drawer{
+		Dst:  glyphs,
+		Src:  image.Black,
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(0, glyphAscent),
+	}
+	drawer.DrawString(text)
+
+	target := image.Rect(x, y, x+len(runes)*width*glyphWidth/glyphHeight, y+height)
+	draw.NearestNeighbor.Scale(img, target, glyphs, glyphs.Bounds(), draw.Over, nil)
+}
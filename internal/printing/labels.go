@@ -0,0 +1,179 @@
+package printing
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"packify/internal/models"
+)
+
+// DefaultLabelTemplate is the name of the label template used when a request names none
+const DefaultLabelTemplate = "default"
+
+// MaxLabels caps the labels of one run, one per pack, so a large order cannot be labelled in one request
+const MaxLabels = 10000
+
+// ErrTooManyLabels is returned when a slip holds more packs than one label run prints
+var ErrTooManyLabels = errors.New("too many labels")
+
+// Label limits, in dots
+const (
+	maxLabelDots     = 32000 // Largest label width and height ZPL accepts
+	maxBarcodeModule = 10    // Widest narrow bar ZPL accepts
+)
+
+// Label is what the label of one pack prints
+type Label struct {
+	Reference string
+	Customer  string
+	Size      int // Items in the pack
+	Pack      int // Number of the pack, from 1
+	Packs     int // Packs of the order
+}
+
+// LabelPlaceholders lists the placeholders the text of label fields may hold
+var LabelPlaceholders = []string{"{reference}", "{customer}", "{size}", "{pack}", "{packs}"}
+
+// fill replaces the placeholders of the text of a label field with the values of a label
+func (l Label) fill(content string) string {
+	return strings.NewReplacer(
+		"{reference}", l.Reference,
+		"{customer}", l.Customer,
+		"{size}", strconv.Itoa(l.Size),
+		"{pack}", strconv.Itoa(l.Pack),
+		"{packs}", strconv.Itoa(l.Packs),
+	).Replace(content)
+}
+
+// CheckLabels checks that a slip holds no more packs than MaxLabels, before its labels are built
+func CheckLabels(slip Slip) error {
+	packs := 0
+	for _, pack := range slip.Packs {
+		if packs += pack.Count; packs > MaxLabels {
+			return fmt.Errorf("%w: at most %d packs can be labelled at once", ErrTooManyLabels, MaxLabels)
+		}
+	}
+	return nil
+}
+
+// Labels returns the labels of the packs of a slip, one per pack, largest size first
+func Labels(slip Slip) []Label {
+	var labels []Label
+	packs := 0
+	for _, pack := range slip.Packs {
+		packs += pack.Count
+	}
+	for _, pack := range slip.packingOrder() {
+		for i := 0; i < pack.Count; i++ {
+			labels = append(labels, Label{
+				Reference: slip.Reference,
+				Customer:  slip.Customer,
+				Size:      pack.Size,
+				Pack:      len(labels) + 1,
+				Packs:     packs,
+			})
+		}
+	}
+	return labels
+}
+
+// NewDefaultLabelTemplate returns the built-in label template: a 4 × 2 inch label at 203 dpi with the pack size,
+// the order reference as text and barcode, and the number of the pack
+func NewDefaultLabelTemplate() models.LabelTemplate {
+	return models.LabelTemplate{
+		Name:   DefaultLabelTemplate,
+		Width:  812,
+		Height: 406,
+		Fields: []models.LabelField{
+			{Kind: models.LabelBox, X: 20, Y: 20, Width: 772, Height: 366, Thickness: 3},
+			{Kind: models.LabelText, X: 50, Y: 45, Height: 60, Content: "Pack of {size}"},
+			{Kind: models.LabelText, X: 560, Y: 55, Height: 45, Content: "{pack} of {packs}"},
+			{Kind: models.LabelText, X: 50, Y: 120, Height: 35, Content: "Order {reference}"},
+			{Kind: models.LabelBarcode, X: 50, Y: 180, Width: 3, Height: 140, Content: "{reference}"},
+		},
+	}
+}
+
+// ValidateLabelTemplate checks that a label template fits ZPL and that every field of it can be placed
+func ValidateLabelTemplate(template models.LabelTemplate) error {
+	if template.Width <= 0 || template.Width > maxLabelDots || template.Height <= 0 || template.Height > maxLabelDots {
+		return fmt.Errorf("label width and height must be between 1 and %d dots", maxLabelDots)
+	}
+	if len(template.Fields) == 0 {
+		return errors.New("a label needs at least one field")
+	}
+
+	for i, field := range template.Fields {
+		if field.X < 0 || field.Y < 0 || field.X >= template.Width || field.Y >= template.Height {
+			return fmt.Errorf("field %d must start on the label", i+1)
+		}
+		switch field.Kind {
+		case models.LabelText:
+			if field.Height <= 0 || field.Width < 0 {
+				return fmt.Errorf("text field %d needs a font height", i+1)
+			}
+		case models.LabelBarcode:
+			if field.Height <= 0 || field.Width < 1 || field.Width > maxBarcodeModule {
+				return fmt.Errorf("barcode field %d needs a bar height and a narrowest bar of 1 to %d dots", i+1,
+					maxBarcodeModule)
+			}
+		case models.LabelBox:
+			if field.Width <= 0 || field.Height <= 0 || field.Thickness <= 0 {
+				return fmt.Errorf("box field %d needs a width, height and thickness", i+1)
+			}
+			continue
+		default:
+			return fmt.Errorf("field %d has unknown kind %q, expected %s, %s or %s", i+1, field.Kind,
+				models.LabelText, models.LabelBarcode, models.LabelBox)
+		}
+		if field.Content == "" {
+			return fmt.Errorf("%s field %d needs content", field.Kind, i+1)
+		}
+	}
+	return nil
+}
+
+// zplEscaper escapes the characters ZPL reads as commands in field data, as hexadecimal after the ^FH indicator
+var zplEscaper = strings.NewReplacer("_", "_5F", "^", "_5E", "~", "_7E")
+
+// WriteLabelsZPL writes one ZPL label per pack, in UTF-8. Texts and barcodes left empty by their placeholders,
+// such as the reference of a calculation outside an order, are left out.
+func WriteLabelsZPL(w io.Writer, template models.LabelTemplate, labels []Label) error {
+	out := bufio.NewWriter(w)
+	for _, label := range labels {
+		fmt.Fprintf(out, "^XA\n^CI28\n^PW%d\n^LL%d\n", template.Width, template.Height)
+		for _, field := range template.Fields {
+			content := zplEscaper.Replace(label.fill(field.Content))
+			switch field.Kind {
+			case models.LabelText:
+				if content == "" {
+					continue
+				}
+				fmt.Fprintf(out, "^FO%d,%d^A0N,%d,%d^FH^FD%s^FS\n", field.X, field.Y, field.Height, textWidth(field),
+					content)
+			case models.LabelBarcode:
+				if content == "" {
+					continue
+				}
+				fmt.Fprintf(out, "^FO%d,%d^BY%d^BCN,%d,Y,N,N^FH^FD%s^FS\n", field.X, field.Y, field.Width, field.Height,
+					content)
+			case models.LabelBox:
+				fmt.Fprintf(out, "^FO%d,%d^GB%d,%d,%d^FS\n", field.X, field.Y, field.Width, field.Height, field.Thickness)
+			}
+		}
+		fmt.Fprint(out, "^XZ\n")
+	}
+	return out.Flush()
+}
+
+// textWidth returns the character width of a text field, its font height unless it is set
+func textWidth(field models.LabelField) int {
+	if field.Width == 0 {
+		return field.Height
+	}
+	return field.Width
+}
@@ -0,0 +1,192 @@
+package printing
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Slip is an order to pick and pack: the packs calculated for it and the shelves they are picked from
+type Slip struct {
+	Reference    string // Order reference, printed as a barcode, empty for a calculation outside an order
+	Customer     string
+	Date         time.Time
+	ItemsOrdered int
+	Packs        []SlipPack
+	TotalPacks   int
+	TotalItems   int
+	ExcessItems  int
+	ShortItems   int
+}
+
+// SlipPack is the packs of one size a slip ships
+type SlipPack struct {
+	Size  int
+	Count int
+	Shelf string // Empty if unknown
+}
+
+// Page layout in millimetres, A4 portrait
+const (
+	pageMargin     = 15.0
+	lineHeight     = 7.0
+	barcodeHeight  = 14.0
+	barcodeWidth   = 80.0 // Widest barcode, narrower modules are used for long references
+	barcodeModule  = 0.4  // Widest module, wide enough for handheld scanners
+	headerFontSize = 20.0
+	bodyFontSize   = 11.0
+)
+
+// packingOrder returns the packs of a slip largest size first, as the customer reads them
+func (s Slip) packingOrder() []SlipPack {
+	packs := append([]SlipPack(nil), s.Packs...)
+	sort.Slice(packs, func(i, j int) bool {
+		return packs[i].Size > packs[j].Size
+	})
+	return packs
+}
+
+// pickingOrder returns the packs of a slip in the order they are picked: by shelf, largest size first on a shelf,
+// and packs without a shelf last
+func (s Slip) pickingOrder() []SlipPack {
+	packs := append([]SlipPack(nil), s.Packs...)
+	sort.Slice(packs, func(i, j int) bool {
+		if (packs[i].Shelf == "") != (packs[j].Shelf == "") {
+			return packs[j].Shelf == ""
+		}
+		if packs[i].Shelf != packs[j].Shelf {
+			return packs[i].Shelf < packs[j].Shelf
+		}
+		return packs[i].Size > packs[j].Size
+	})
+	return packs
+}
+
+// WriteSlipPDF writes a slip as a PDF of two pages: the packing slip that ships with the order and the pick
+// list warehouse staff collect its packs with. Both carry the order reference as a Code 128 barcode, or as
+// text only if it cannot be encoded as Code 128.
+func WriteSlipPDF(w io.Writer, slip Slip) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(true, pageMargin)
+	pdf.SetCreator("Packify", false)
+	pdf.SetTitle("Packing slip "+slip.Reference, true)
+	pdf.SetCreationDate(slip.Date)
+	pdf.SetModificationDate(slip.Date)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	// Packing slip
+	writeSlipHeader(pdf, tr, "Packing Slip", slip)
+	writeTable(pdf, []column{{"Pack Size", 60, "R"}, {"Packs", 40, "R"}, {"Items", 60, "R"}},
+		packingRows(slip.packingOrder()))
+	pdf.Ln(lineHeight / 2)
+	writeTotal(pdf, "Total Packs", slip.TotalPacks)
+	writeTotal(pdf, "Total Items", slip.TotalItems)
+	writeTotal(pdf, "Excess Items", slip.ExcessItems)
+	if slip.ShortItems > 0 {
+		writeTotal(pdf, "Short Items", slip.ShortItems)
+	}
+
+	// Pick list
+	writeSlipHeader(pdf, tr, "Pick List", slip)
+	var rows [][]string
+	for _, pack := range slip.pickingOrder() {
+		shelf := pack.Shelf
+		if shelf == "" {
+			shelf = "-"
+		}
+		rows = append(rows, []string{tr(shelf), fmt.Sprint(pack.Size), fmt.Sprint(pack.Count), ""})
+	}
+	writeTable(pdf, []column{{"Shelf", 60, "L"}, {"Pack Size", 40, "R"}, {"Packs", 40, "R"}, {"Picked", 40, "C"}}, rows)
+	pdf.Ln(lineHeight / 2)
+	writeTotal(pdf, "Total Packs", slip.TotalPacks)
+
+	return pdf.Output(w)
+}
+
+// packingRows returns the table rows of the packs of a packing slip
+func packingRows(packs []SlipPack) [][]string {
+	rows := make([][]string, 0, len(packs))
+	for _, pack := range packs {
+		rows = append(rows, []string{fmt.Sprint(pack.Size), fmt.Sprint(pack.Count), fmt.Sprint(pack.Size * pack.Count)})
+	}
+	return rows
+}
+
+// writeSlipHeader starts a page with a title, the order reference barcode and the details of the order
+func writeSlipHeader(pdf *fpdf.Fpdf, tr func(string) string, title string, slip Slip) {
+	pdf.AddPage()
+	pageWidth, _ := pdf.GetPageSize()
+
+	pdf.SetFont("Helvetica", "B", headerFontSize)
+	pdf.CellFormat(0, barcodeHeight, title, "", 1, "LM", false, 0, "")
+
+	if slip.Reference != "" {
+		if modules, err := Code128(slip.Reference); err == nil {
+			module := barcodeModule
+			if width := float64(len(modules)) * module; width > barcodeWidth {
+				module = barcodeWidth / float64(len(modules))
+			}
+			x := pageWidth - pageMargin - float64(len(modules))*module
+			pdf.SetFillColor(0, 0, 0)
+			for _, bar := range bars(modules) {
+				pdf.Rect(x+float64(bar[0])*module, pageMargin, float64(bar[1])*module, barcodeHeight, "F")
+			}
+		}
+	}
+
+	pdf.SetFont("Helvetica", "", bodyFontSize)
+	pdf.Ln(lineHeight / 2)
+	reference := slip.Reference
+	if reference == "" {
+		reference = "-"
+	}
+	writeDetail(pdf, "Order", tr(reference))
+	if slip.Customer != "" {
+		writeDetail(pdf, "Customer", tr(slip.Customer))
+	}
+	writeDetail(pdf, "Date", slip.Date.Format("2006-01-02 15:04"))
+	writeDetail(pdf, "Items Ordered", fmt.Sprint(slip.ItemsOrdered))
+	pdf.Ln(lineHeight / 2)
+}
+
+// writeDetail writes a labelled detail of the order
+func writeDetail(pdf *fpdf.Fpdf, label, value string) {
+	pdf.SetFont("Helvetica", "B", bodyFontSize)
+	pdf.CellFormat(40, lineHeight, label+":", "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", bodyFontSize)
+	pdf.CellFormat(0, lineHeight, value, "", 1, "L", false, 0, "")
+}
+
+// writeTotal writes a labelled total under a table
+func writeTotal(pdf *fpdf.Fpdf, label string, value int) {
+	writeDetail(pdf, label, fmt.Sprint(value))
+}
+
+// column is a table column: its title, width in millimetres and the alignment of its values
+type column struct {
+	title string
+	width float64
+	align string
+}
+
+// writeTable writes a table with a shaded header row
+func writeTable(pdf *fpdf.Fpdf, columns []column, rows [][]string) {
+	pdf.SetFont("Helvetica", "B", bodyFontSize)
+	pdf.SetFillColor(230, 230, 230)
+	for _, column := range columns {
+		pdf.CellFormat(column.width, lineHeight, column.title, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", bodyFontSize)
+	for _, row := range rows {
+		for i, value := range row {
+			pdf.CellFormat(columns[i].width, lineHeight, value, "1", 0, columns[i].align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"time"
+
+	"packify/internal/models"
+	"packify/pkg/calculator"
+)
+
+// ErrInvalidDateRange is returned when a dashboard is requested for a range that ends before it starts or is too long
+var ErrInvalidDateRange = errors.New("invalid date range")
+
+// MaxDashboardDays is the most days a dashboard covers
+const MaxDashboardDays = 366
+
+// dayLayout is the layout of the days of a dashboard
+const dayLayout = "2006-01-02"
+
+// Dashboard aggregates the calculations recorded over a range of days
+type Dashboard struct {
+	From         time.Time                  // First day
+	To           time.Time                  // Last day, included
+	Days         []models.DailyCalculations // Every day of the range, days without calculations included
+	PackUsage    []models.PackUsage         // Largest size first
+	Algorithms   []models.AlgorithmUsage    // Most used first
+	Orders       int
+	ItemsOrdered float64
+	ExcessItems  int
+	ShortItems   int
+}
+
+// ExcessPercent returns the excess items as a percentage of the items ordered over the whole range
+func (d Dashboard) ExcessPercent() float64 {
+	return models.DailyCalculations{ItemsOrdered: d.ItemsOrdered, ExcessItems: d.ExcessItems}.ExcessPercent()
+}
+
+// recordCalculation stores a calculation served to a client for the dashboard. A calculation is still served
+// when it cannot be recorded, so failures are only logged.
+func (s *PackService) recordCalculation(itemsOrdered *big.Int, result calculator.BigPackResult, packSizes []int, options calculator.Options) {
+	if err := s.saveCalculation(itemsOrdered, result, packSizes, options); err != nil {
+		log.Printf("Warning: failed to record the calculation of %s items: %v", itemsOrdered, err)
+	}
+}
+
+// saveCalculation stores a calculation with the algorithm that ran it
+func (s *PackService) saveCalculation(itemsOrdered *big.Int, result calculator.BigPackResult, packSizes []int, options calculator.Options) error {
+	algorithm, err := calculator.SelectAlgorithm(itemsOrdered, packSizes, options)
+	if err != nil {
+		return err
+	}
+
+	calculation := models.Calculation{
+		ItemsOrdered: itemsOrdered.String(),
+		TotalItems:   result.TotalItems.String(),
+		TotalPacks:   result.TotalPacks.String(),
+		ExcessItems:  int(result.ExcessItems.Int64()), // Less than a largest pack plus the tolerance
+		ShortItems:   int(result.ShortItems.Int64()),  // Less than a largest pack
+		Algorithm:    algorithm,
+	}
+	for size, count := range result.PackCounts {
+		if count.Sign() > 0 {
+			calculation.Packs = append(calculation.Packs, models.CalculationPack{Size: size, Count: count.String()})
+		}
+	}
+	sort.Slice(calculation.Packs, func(i, j int) bool {
+		return calculation.Packs[i].Size > calculation.Packs[j].Size
+	})
+
+	return s.DB.Create(&calculation).Error
+}
+
+// GetDashboard aggregates the calculations recorded from the first day to the last one, both included.
+// Days are UTC days, as the database groups them, whatever the time zones of the application and the database
+// session, so the daily totals and the pack and algorithm usage count the same calculations.
+func (s *PackService) GetDashboard(from, to time.Time) (*Dashboard, error) {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: the last day %s is before the first day %s", ErrInvalidDateRange,
+			to.Format(dayLayout), from.Format(dayLayout))
+	}
+	if days := int(to.Sub(from)/(24*time.Hour)) + 1; days > MaxDashboardDays {
+		return nil, fmt.Errorf("%w: at most %d days are covered, got %d", ErrInvalidDateRange, MaxDashboardDays, days)
+	}
+	end := to.AddDate(0, 0, 1)
+
+	days, err := models.GetDailyCalculations(s.DB, from, end)
+	if err != nil {
+		return nil, err
+	}
+	dashboard := Dashboard{From: from, To: to}
+	if dashboard.PackUsage, err = models.GetPackUsage(s.DB, from, end); err != nil {
+		return nil, err
+	}
+	if dashboard.Algorithms, err = models.GetAlgorithmUsage(s.DB, from, end); err != nil {
+		return nil, err
+	}
+
+	// Fill the days without calculations so charts keep a uniform time axis
+	recorded := make(map[string]models.DailyCalculations, len(days))
+	for _, day := range days {
+		recorded[day.Day] = day
+	}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		key := day.Format(dayLayout)
+		daily, ok := recorded[key]
+		if !ok {
+			daily = models.DailyCalculations{Day: key}
+		}
+		dashboard.Days = append(dashboard.Days, daily)
+
+		dashboard.Orders += daily.Orders
+		dashboard.ItemsOrdered += daily.ItemsOrdered
+		dashboard.ExcessItems += daily.ExcessItems
+		dashboard.ShortItems += daily.ShortItems
+	}
+
+	return &dashboard, nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"packify/internal/models"
+	"packify/pkg/calculator"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrUnknownPackSize is returned when the stock of a pack size that does not exist is set
+var ErrUnknownPackSize = errors.New("unknown pack size")
+
+// maxDailyPacks caps the packs of one size a day of forecast history counts, so forecasts of orders beyond int
+// stay within int
+const maxDailyPacks = math.MaxInt32
+
+// ForecastPackConsumption forecasts the daily consumption of every pack size from the packs the recorded
+// calculations shipped, and the stock at which to reorder it, smallest size first. Forecasts use complete days
+// only, up to yesterday, and no days before the first calculation was recorded, so a fresh database does not
+// read as days without consumption. Days are UTC days, as the database groups them, whatever the time zones of
+// the application and the database session.
+func (s *PackService) ForecastPackConsumption(options calculator.ForecastOptions) ([]calculator.Forecast, error) {
+	var packSizes []models.PackSize
+	if err := s.DB.Order("size").Find(&packSizes).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -options.HistoryDays())
+	first, ok, err := models.GetFirstCalculationTime(s.DB)
+	if err != nil {
+		return nil, err
+	}
+	first = first.UTC()
+	if !ok {
+		from = to
+	} else if first = time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, time.UTC); first.After(from) {
+		from = first
+	}
+	if from.After(to) {
+		from = to
+	}
+
+	consumption, err := models.GetDailyPackConsumption(s.DB, from, to)
+	if err != nil {
+		return nil, err
+	}
+	dayIndex := make(map[string]int)
+	days := 0
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		dayIndex[day.Format(dayLayout)] = days
+		days++
+	}
+	daily := make(map[int][]int)
+	for _, packSize := range packSizes {
+		daily[packSize.Size] = make([]int, days)
+	}
+	for _, packs := range consumption {
+		// A day outside the history cannot be placed, rather than counting it on the first day it is left out
+		series, ok := daily[packs.Size]
+		index, known := dayIndex[packs.Day]
+		if !ok || !known {
+			continue
+		}
+		series[index] = int(min(float64(series[index])+math.Round(packs.Packs), maxDailyPacks))
+	}
+
+	forecasts := make([]calculator.Forecast, 0, len(packSizes))
+	for _, packSize := range packSizes {
+		forecast, err := calculator.ForecastConsumption(packSize.Size, daily[packSize.Size], packSize.StockOnHand,
+			packSize.LeadTimeDays, options)
+		if err != nil {
+			return nil, fmt.Errorf("pack size %d: %w", packSize.Size, err)
+		}
+		forecasts = append(forecasts, forecast)
+	}
+	return forecasts, nil
+}
+
+// SetPackStock sets the packs of a size in stock, the days the supplier takes to deliver more and, unless it is
+// nil, the shelf they are picked from. The stock cannot drop below the packs reserved for confirmed orders.
+func (s *PackService) SetPackStock(id uint, stockOnHand, leadTimeDays int, shelf *string) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		var packSize models.PackSize
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&packSize, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w %d", ErrUnknownPackSize, id)
+		}
+		if err != nil {
+			return err
+		}
+		if stockOnHand < packSize.Reserved {
+			return fmt.Errorf("%w: %d packs of %d are reserved, stock cannot be set to %d", ErrInsufficientStock,
+				packSize.Reserved, packSize.Size, stockOnHand)
+		}
+
+		updates := map[string]interface{}{
+			"stock_on_hand":  stockOnHand,
+			"lead_time_days": leadTimeDays,
+		}
+		if shelf != nil {
+			updates["shelf"] = strings.TrimSpace(*shelf)
+		}
+		return tx.Model(&packSize).Updates(updates).Error
+	})
+}
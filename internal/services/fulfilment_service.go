@@ -0,0 +1,119 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"packify/internal/models"
+	"packify/pkg/calculator"
+
+	"gorm.io/gorm"
+)
+
+// ErrUnknownWarehouse is returned when the stock of a warehouse that does not exist is changed
+var ErrUnknownWarehouse = errors.New("unknown warehouse")
+
+// ErrInvalidStock is returned when stock is set for a size that is not positive or in a negative quantity
+var ErrInvalidStock = errors.New("invalid stock")
+
+// ErrNoWarehouses is returned when fulfilment is planned before any warehouse is set up
+var ErrNoWarehouses = errors.New("no warehouses")
+
+// PlanFulfilment decides which warehouses ship an order from their stock. A single warehouse is preferred,
+// the first added among equals, and the order is split across the fewest warehouses only when needed.
+// Stock of sizes that are no longer pack sizes is ignored.
+func (s *PackService) PlanFulfilment(itemsOrdered int, options calculator.Options) (*calculator.Allocation, error) {
+	warehouses, err := models.GetWarehouses(s.DB)
+	if err != nil {
+		return nil, err
+	}
+	if len(warehouses) == 0 {
+		return nil, ErrNoWarehouses
+	}
+
+	packSizes, err := models.GetPackSizes(s.DB)
+	if err != nil {
+		return nil, err
+	}
+	available := make(map[int]bool, len(packSizes))
+	for _, size := range packSizes {
+		available[size] = true
+	}
+
+	locations := make([]calculator.Location, len(warehouses))
+	for i, warehouse := range warehouses {
+		locations[i] = calculator.Location{Name: warehouse.Name, Stock: make(map[int]int)}
+		for _, stock := range warehouse.Stock {
+			if available[stock.Size] {
+				locations[i].Stock[stock.Size] = stock.Quantity
+			}
+		}
+	}
+
+	allocation, err := calculator.PlanAllocation(itemsOrdered, locations, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &allocation, nil
+}
+
+// GetWarehouses returns all warehouses and their stock
+func (s *PackService) GetWarehouses() ([]models.Warehouse, error) {
+	return models.GetWarehouses(s.DB)
+}
+
+// AddWarehouse adds a new warehouse with its stock
+func (s *PackService) AddWarehouse(warehouse models.Warehouse) error {
+	if err := validateStock(warehouse.Stock); err != nil {
+		return err
+	}
+	return s.DB.Create(&warehouse).Error
+}
+
+// SetStock replaces the stock of a warehouse
+func (s *PackService) SetStock(warehouseName string, stock []models.WarehouseStock) error {
+	if err := validateStock(stock); err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		var warehouse models.Warehouse
+		err := tx.Where("name = ?", warehouseName).First(&warehouse).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w %q", ErrUnknownWarehouse, warehouseName)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("warehouse_id = ?", warehouse.ID).Delete(&models.WarehouseStock{}).Error; err != nil {
+			return err
+		}
+		for _, item := range stock {
+			item := models.WarehouseStock{WarehouseID: warehouse.ID, Size: item.Size, Quantity: item.Quantity}
+			if err := tx.Create(&item).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// validateStock checks that stock is held of positive sizes, each listed once, in quantities that are not negative
+func validateStock(stock []models.WarehouseStock) error {
+	seen := make(map[int]bool, len(stock))
+	for _, item := range stock {
+		if item.Size <= 0 {
+			return fmt.Errorf("%w: pack size must be positive, got %d", ErrInvalidStock, item.Size)
+		}
+		if item.Quantity < 0 {
+			return fmt.Errorf("%w: stock of pack size %d must not be negative, got %d", ErrInvalidStock, item.Size, item.Quantity)
+		}
+		if seen[item.Size] {
+			return fmt.Errorf("%w: pack size %d is listed more than once", ErrInvalidStock, item.Size)
+		}
+		seen[item.Size] = true
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"packify/internal/models"
+	"packify/internal/printing"
+
+	"gorm.io/gorm"
+)
+
+// ErrUnknownLabelTemplate is returned when a label template that does not exist is read or printed with
+var ErrUnknownLabelTemplate = errors.New("unknown label template")
+
+// ErrInvalidLabelTemplate is returned when a label template does not fit ZPL or a field of it cannot be placed
+var ErrInvalidLabelTemplate = errors.New("invalid label template")
+
+// GetLabelTemplate returns the label template with a name, or the default one if the name is empty. The
+// built-in default is used until a template named default is stored.
+func (s *PackService) GetLabelTemplate(name string) (*models.LabelTemplate, error) {
+	if name == "" {
+		name = printing.DefaultLabelTemplate
+	}
+
+	template, err := models.GetLabelTemplate(s.DB, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if name == printing.DefaultLabelTemplate {
+			template = printing.NewDefaultLabelTemplate()
+			return &template, nil
+		}
+		return nil, fmt.Errorf("%w %q", ErrUnknownLabelTemplate, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// GetLabelTemplates returns every label template by name, with the built-in default unless one is stored
+func (s *PackService) GetLabelTemplates() ([]models.LabelTemplate, error) {
+	templates, err := models.GetLabelTemplates(s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, template := range templates {
+		if template.Name == printing.DefaultLabelTemplate {
+			return templates, nil
+		}
+	}
+	return append([]models.LabelTemplate{printing.NewDefaultLabelTemplate()}, templates...), nil
+}
+
+// SetLabelTemplate creates a label template or replaces the size and fields of the one with its name
+func (s *PackService) SetLabelTemplate(template models.LabelTemplate) error {
+	template.Name = strings.TrimSpace(template.Name)
+	if template.Name == "" {
+		return fmt.Errorf("%w: a label template needs a name", ErrInvalidLabelTemplate)
+	}
+	if err := printing.ValidateLabelTemplate(template); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidLabelTemplate, err)
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		stored := models.LabelTemplate{Name: template.Name}
+		if err := tx.Where("name = ?", template.Name).FirstOrCreate(&stored).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&stored).Updates(map[string]interface{}{"width": template.Width, "height": template.Height}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("label_template_id = ?", stored.ID).Delete(&models.LabelField{}).Error; err != nil {
+			return err
+		}
+		for _, field := range template.Fields {
+			field.ID = 0
+			field.LabelTemplateID = stored.ID
+			if err := tx.Create(&field).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
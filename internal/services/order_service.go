@@ -0,0 +1,210 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"packify/internal/models"
+	"packify/pkg/calculator"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrUnknownOrder is returned when an order that does not exist is read or transitioned
+var ErrUnknownOrder = errors.New("unknown order")
+
+// ErrDuplicateOrder is returned when an order is created with the reference of another order
+var ErrDuplicateOrder = errors.New("an order with this reference already exists")
+
+// ErrUnknownTransition is returned for a transition the order state machine does not have
+var ErrUnknownTransition = errors.New("unknown order transition")
+
+// ErrInvalidTransition is returned when an order is transitioned from a status the transition does not apply to
+var ErrInvalidTransition = errors.New("invalid order transition")
+
+// ErrReservationOwned is returned when a reservation made for an order is released or shipped directly
+var ErrReservationOwned = errors.New("reservation belongs to an order")
+
+// Order transitions, each moves an order to one status from the statuses listed in orderTransitions
+const (
+	TransitionCalculate = "calculate" // Calculates the optimal packs, again if the order was calculated already
+	TransitionConfirm   = "confirm"   // Reserves the calculated packs in stock
+	TransitionPack      = "pack"      // Records that the packs were filled
+	TransitionShip      = "ship"      // Ships the reserved packs, they leave the stock
+	TransitionCancel    = "cancel"    // Abandons the order and releases any reserved packs
+)
+
+// OrderTransitions lists the transitions of the order state machine in lifecycle order
+var OrderTransitions = []string{TransitionCalculate, TransitionConfirm, TransitionPack, TransitionShip, TransitionCancel}
+
+// orderTransitions is the order state machine: the statuses each transition applies to and the status it
+// moves orders to. Shipped and cancelled orders are final.
+var orderTransitions = map[string]struct {
+	from []string
+	to   string
+}{
+	TransitionCalculate: {from: []string{models.OrderDraft, models.OrderCalculated}, to: models.OrderCalculated},
+	TransitionConfirm:   {from: []string{models.OrderCalculated}, to: models.OrderConfirmed},
+	TransitionPack:      {from: []string{models.OrderConfirmed}, to: models.OrderPacked},
+	TransitionShip:      {from: []string{models.OrderPacked}, to: models.OrderShipped},
+	TransitionCancel: {
+		from: []string{models.OrderDraft, models.OrderCalculated, models.OrderConfirmed, models.OrderPacked},
+		to:   models.OrderCancelled,
+	},
+}
+
+// AllowedTransitions returns the transitions that apply to an order with a status, in lifecycle order
+func AllowedTransitions(status string) []string {
+	var allowed []string
+	for _, transition := range OrderTransitions {
+		if canTransition(status, transition) {
+			allowed = append(allowed, transition)
+		}
+	}
+	return allowed
+}
+
+// canTransition reports whether a transition applies to an order with a status
+func canTransition(status, transition string) bool {
+	for _, from := range orderTransitions[transition].from {
+		if from == status {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTransition returns an error if a transition does not exist or does not apply to an order
+func checkTransition(order models.Order, transition string) error {
+	if _, ok := orderTransitions[transition]; !ok {
+		return fmt.Errorf("%w %q, expected one of %s", ErrUnknownTransition, transition,
+			strings.Join(OrderTransitions, ", "))
+	}
+	if !canTransition(order.Status, transition) {
+		return fmt.Errorf("%w: order %s is %s and cannot %s", ErrInvalidTransition, order.Reference, order.Status,
+			transition)
+	}
+	return nil
+}
+
+// CreateOrder creates a draft order. References are unique, so an order imported twice is rejected; the unique
+// index decides, so concurrent imports of one reference cannot both pass. It needs a database opened with
+// TranslateError for the violation to read as gorm.ErrDuplicatedKey.
+func (s *PackService) CreateOrder(reference, customer string, itemsOrdered int) (*models.Order, error) {
+	order := models.Order{
+		Reference:    strings.TrimSpace(reference),
+		Customer:     strings.TrimSpace(customer),
+		ItemsOrdered: itemsOrdered,
+		Status:       models.OrderDraft,
+	}
+
+	err := s.DB.Create(&order).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateOrder, order.Reference)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetOrder returns an order and its calculated packs
+func (s *PackService) GetOrder(id uint) (*models.Order, error) {
+	order, err := models.GetOrder(s.DB, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w %d", ErrUnknownOrder, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetOrders returns the latest orders with a status, or with any status if it is empty, newest first
+func (s *PackService) GetOrders(status string, limit int) ([]models.Order, error) {
+	return models.GetOrders(s.DB, status, limit)
+}
+
+// TransitionOrder moves an order through the state machine. Calculating stores the optimal packs for the
+// order, calculated with the options and recorded like any other calculation; confirming reserves them in
+// stock; shipping ships the reservation and cancelling releases it. The order is locked while it moves, so
+// concurrent transitions of one order apply one after the other and the second is checked against the status
+// the first left.
+func (s *PackService) TransitionOrder(id uint, transition string, options calculator.Options) (*models.Order, error) {
+	order, err := s.GetOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTransition(*order, transition); err != nil {
+		return nil, err
+	}
+
+	// The calculation reads the pack sizes only, so it runs before the order is locked
+	var result *calculator.PackResult
+	if transition == TransitionCalculate {
+		if result, err = s.CalculatePacks(order.ItemsOrdered, options); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error; err != nil {
+			return err
+		}
+		if err := checkTransition(order, transition); err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"status": orderTransitions[transition].to}
+		switch transition {
+		case TransitionCalculate:
+			if err := tx.Unscoped().Where("order_id = ?", id).Delete(&models.OrderPack{}).Error; err != nil {
+				return err
+			}
+			for size, count := range result.PackCounts {
+				if count == 0 {
+					continue
+				}
+				pack := models.OrderPack{OrderID: id, Size: size, Count: count}
+				if err := tx.Create(&pack).Error; err != nil {
+					return err
+				}
+			}
+			updates["total_packs"] = result.TotalPacks
+			updates["total_items"] = result.TotalItems
+			updates["excess_items"] = result.ExcessItems
+			updates["short_items"] = result.ShortItems
+		case TransitionConfirm:
+			if err := tx.Where("order_id = ?", id).Find(&order.Packs).Error; err != nil {
+				return err
+			}
+			reservation, err := reservePacks(tx, order.ItemsOrdered, order.PackCounts())
+			if err != nil {
+				return err
+			}
+			updates["reservation_id"] = reservation.ID
+		case TransitionShip:
+			if order.ReservationID == nil {
+				// Confirming reserves the packs, an order without a reservation has nothing in stock to ship
+				return fmt.Errorf("%w: order %d has no reserved packs to ship", ErrInvalidTransition, id)
+			}
+			if err := closeReservation(tx, *order.ReservationID, models.ReservationShipped); err != nil {
+				return err
+			}
+		case TransitionCancel:
+			if order.ReservationID != nil {
+				if err := closeReservation(tx, *order.ReservationID, models.ReservationReleased); err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Model(&order).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOrder(id)
+}
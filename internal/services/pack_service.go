@@ -1,6 +1,10 @@
 package services
 
 import (
+	"io"
+	"math/big"
+
+	"packify/internal/export"
 	"packify/internal/models"
 	"packify/pkg/calculator"
 
@@ -9,30 +13,188 @@ import (
 
 // PackService handles pack calculation business logic
 type PackService struct {
-	DB *gorm.DB
+	DB        *gorm.DB
+	Options   calculator.Options   // Calculation options used when a request does not override them
+	Packaging calculator.Hierarchy // Containers packs roll up into when a request does not override them, nil for none
+	Carrier   string               // Carrier profile shipments are planned for when a request does not name one
 }
 
 // NewPackService creates a new pack service
-func NewPackService(db *gorm.DB) *PackService {
+func NewPackService(db *gorm.DB, options calculator.Options, packaging calculator.Hierarchy, carrier string) *PackService {
 	return &PackService{
-		DB: db,
+		DB:        db,
+		Options:   options,
+		Packaging: packaging,
+		Carrier:   carrier,
 	}
 }
 
-// CalculatePacks calculates the optimal packs for an order
-func (s *PackService) CalculatePacks(itemsOrdered int) (*calculator.PackResult, error) {
+// CalculatePacks calculates the optimal packs for an order and records it for the dashboard
+func (s *PackService) CalculatePacks(itemsOrdered int, options calculator.Options) (*calculator.PackResult, error) {
 	// Get available pack sizes from the database
 	packSizes, err := models.GetPackSizes(s.DB)
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate the optimal packs using the most efficient algorithm based on order size and pack sizes
-	result, err := calculator.OptimalCalculatePacks(itemsOrdered, packSizes)
+	// Calculate the optimal packs, ties are resolved by the tie-break policy
+	result, err := calculator.CalculatePacksWithOptions(itemsOrdered, packSizes, options)
+	if err != nil {
+		return nil, err
+	}
+	s.recordCalculation(big.NewInt(int64(itemsOrdered)), result.Big(), packSizes, options)
+
+	return &result, nil
+}
+
+// ExplainPacks calculates the optimal packs for an order, explains how they were derived and records it for
+// the dashboard
+func (s *PackService) ExplainPacks(itemsOrdered int, options calculator.Options) (*calculator.PackResult, *calculator.Explanation, error) {
+	packSizes, err := models.GetPackSizes(s.DB)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, explanation, err := calculator.ExplainCalculatePacks(itemsOrdered, packSizes, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.recordCalculation(big.NewInt(int64(itemsOrdered)), result.Big(), packSizes, options)
+
+	return &result, &explanation, nil
+}
+
+// CalculateAlternatives returns all optimal packings for an order plus the topK next best ones
+func (s *PackService) CalculateAlternatives(itemsOrdered int, topK int, options calculator.Options) (*calculator.Alternatives, error) {
+	packSizes, err := models.GetPackSizes(s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	alternatives, err := calculator.CalculateAlternatives(itemsOrdered, packSizes, topK, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &alternatives, nil
+}
+
+// CalculateFollowUp calculates the shipment that completes an under-shipped order,
+// shipped holds the pack counts of the first shipment
+func (s *PackService) CalculateFollowUp(shortItems int, shipped map[int]int, options calculator.Options) (*calculator.PackResult, error) {
+	packSizes, err := models.GetPackSizes(s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := calculator.CalculateFollowUp(shortItems, shipped, packSizes, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// CalculateRange calculates the optimal packs of every order from first to last items in one DP sweep
+// and writes them to w in a format of export.NewRangeWriter
+func (s *PackService) CalculateRange(first, last int, options calculator.Options, format string, w io.Writer) error {
+	packSizes, err := models.GetPackSizes(s.DB)
+	if err != nil {
+		return err
+	}
+
+	writer, err := export.NewRangeWriter(w, format, packSizes)
+	if err != nil {
+		return err
+	}
+
+	itemsOrdered := first
+	err = calculator.CalculateRange(first, last, packSizes, options, func(result calculator.PackResult) error {
+		err := writer.Write(itemsOrdered, result)
+		itemsOrdered++
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+// SuggestQuantities finds the nearest order quantities that pack without excess
+func (s *PackService) SuggestQuantities(itemsOrdered int, options calculator.Options) (*calculator.Suggestions, error) {
+	packSizes, err := models.GetPackSizes(s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions, err := calculator.SuggestQuantities(itemsOrdered, packSizes, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &suggestions, nil
+}
+
+// AmendPacks calculates the packs of an amended order that change the fewest of the packs already packed
+func (s *PackService) AmendPacks(original map[int]int, itemsOrdered int, options calculator.Options) (*calculator.Amendment, error) {
+	packSizes, err := models.GetPackSizes(s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	amendment, err := calculator.AmendPacks(original, itemsOrdered, packSizes, options)
 	if err != nil {
 		return nil, err
 	}
 
+	return &amendment, nil
+}
+
+// ConsolidateOrders compares shipping orders with the same consolidation key together and separately
+func (s *PackService) ConsolidateOrders(orders []calculator.Order, options calculator.Options) (*calculator.Consolidation, error) {
+	packSizes, err := models.GetPackSizes(s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	consolidation, err := calculator.ConsolidateOrders(orders, packSizes, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &consolidation, nil
+}
+
+// AnalyzePackSizes reports how the available pack sizes behave for orders from 1 to rangeEnd items,
+// 0 for calculator.DefaultAnalysisRange
+func (s *PackService) AnalyzePackSizes(rangeEnd int, options calculator.Options) (*calculator.PackSetAnalysis, error) {
+	packSizes, err := models.GetPackSizes(s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis, err := calculator.AnalyzePackSizes(packSizes, rangeEnd, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &analysis, nil
+}
+
+// CalculatePacksBig calculates the optimal packs for an order of any size and records it for the dashboard
+func (s *PackService) CalculatePacksBig(itemsOrdered *big.Int, options calculator.Options) (*calculator.BigPackResult, error) {
+	packSizes, err := models.GetPackSizes(s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := calculator.CalculatePacksBig(itemsOrdered, packSizes, options)
+	if err != nil {
+		return nil, err
+	}
+	s.recordCalculation(itemsOrdered, result, packSizes, options)
+
 	return &result, nil
 }
 
@@ -45,11 +207,8 @@ func (s *PackService) GetPackSizes() ([]models.PackSize, error) {
 	return packSizes, nil
 }
 
-// AddPackSize adds a new pack size
-func (s *PackService) AddPackSize(size int) error {
-	packSize := models.PackSize{
-		Size: size,
-	}
+// AddPackSize adds a new pack size with its weight and dimensions
+func (s *PackService) AddPackSize(packSize models.PackSize) error {
 	return s.DB.Create(&packSize).Error
 }
 
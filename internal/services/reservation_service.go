@@ -0,0 +1,188 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"packify/internal/models"
+	"packify/pkg/calculator"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInsufficientStock is returned when a pack size has fewer packs available than a reservation needs
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// ErrUnknownReservation is returned when a reservation that does not exist is released or shipped
+var ErrUnknownReservation = errors.New("unknown reservation")
+
+// ErrReservationClosed is returned when a reservation that was already released or shipped is released or shipped
+var ErrReservationClosed = errors.New("reservation is no longer reserved")
+
+// ReserveOrder calculates the optimal packs for an order and reserves them in stock, so they cannot be promised
+// to another order. The calculation is recorded like any other.
+func (s *PackService) ReserveOrder(itemsOrdered int, options calculator.Options) (*models.Reservation, error) {
+	result, err := s.CalculatePacks(itemsOrdered, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var reservation *models.Reservation
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		reservation, err = reservePacks(tx, itemsOrdered, result.PackCounts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// reservePacks reserves packs in stock within a transaction. The pack sizes are locked until the transaction
+// ends, so concurrent reservations wait for each other instead of both reserving the last packs.
+func reservePacks(tx *gorm.DB, itemsOrdered int, packCounts map[int]int) (*models.Reservation, error) {
+	packSizes, err := lockPackSizes(tx, packCounts)
+	if err != nil {
+		return nil, err
+	}
+
+	var short []string
+	for _, packSize := range packSizes {
+		if count := packCounts[packSize.Size]; count > packSize.Available() {
+			short = append(short, fmt.Sprintf("%d × %d needed, %d available", count, packSize.Size, packSize.Available()))
+		}
+	}
+	if len(short) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInsufficientStock, strings.Join(short, ", "))
+	}
+
+	reservation := models.Reservation{ItemsOrdered: itemsOrdered, Status: models.ReservationReserved}
+	for _, packSize := range packSizes {
+		count := packCounts[packSize.Size]
+		err := tx.Model(&packSize).Update("reserved", gorm.Expr("reserved + ?", count)).Error
+		if err != nil {
+			return nil, err
+		}
+		reservation.Packs = append(reservation.Packs, models.ReservedPack{Size: packSize.Size, Count: count})
+	}
+	if err := tx.Create(&reservation).Error; err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// ReleaseReservation cancels a reservation and returns its packs to available stock
+func (s *PackService) ReleaseReservation(id uint) (*models.Reservation, error) {
+	return s.closeReservation(id, models.ReservationReleased)
+}
+
+// ShipReservation ships a reservation, its packs leave the stock
+func (s *PackService) ShipReservation(id uint) (*models.Reservation, error) {
+	return s.closeReservation(id, models.ReservationShipped)
+}
+
+// closeReservation moves a reserved reservation to its final status and updates the stock of its packs.
+// Reservations made for orders move with their order instead, so the order keeps its status in step.
+func (s *PackService) closeReservation(id uint, status string) (*models.Reservation, error) {
+	var orders []models.Order
+	if err := s.DB.Where("reservation_id = ?", id).Limit(1).Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	if len(orders) > 0 {
+		return nil, fmt.Errorf("%w %s, transition the order instead", ErrReservationOwned, orders[0].Reference)
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		return closeReservation(tx, id, status)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reservation, err := models.GetReservation(s.DB, id)
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// closeReservation moves a reserved reservation to its final status within a transaction: released packs return
+// to available stock, shipped packs leave the stock. The reservation is locked first so it is closed only once.
+func closeReservation(tx *gorm.DB, id uint, status string) error {
+	var reservation models.Reservation
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reservation, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w %d", ErrUnknownReservation, id)
+	}
+	if err != nil {
+		return err
+	}
+	if reservation.Status != models.ReservationReserved {
+		return fmt.Errorf("%w: reservation %d is %s", ErrReservationClosed, id, reservation.Status)
+	}
+
+	var packs []models.ReservedPack
+	if err := tx.Where("reservation_id = ?", id).Find(&packs).Error; err != nil {
+		return err
+	}
+	packCounts := make(map[int]int, len(packs))
+	for _, pack := range packs {
+		packCounts[pack.Size] += pack.Count
+	}
+	packSizes, err := lockPackSizes(tx, packCounts)
+	if err != nil {
+		return err
+	}
+
+	for _, packSize := range packSizes {
+		count := packCounts[packSize.Size]
+		updates := map[string]interface{}{"reserved": gorm.Expr("reserved - ?", count)}
+		if status == models.ReservationShipped {
+			updates["stock_on_hand"] = gorm.Expr("stock_on_hand - ?", count)
+		}
+		if err := tx.Model(&packSize).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Model(&reservation).Update("status", status).Error
+}
+
+// lockPackSizes locks the pack sizes of the pack counts, smallest first so that transactions locking several
+// sizes always lock them in the same order and cannot deadlock. Every size must exist.
+func lockPackSizes(tx *gorm.DB, packCounts map[int]int) ([]models.PackSize, error) {
+	sizes := make([]int, 0, len(packCounts))
+	for size, count := range packCounts {
+		if count > 0 {
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Ints(sizes)
+	if len(sizes) == 0 {
+		return nil, nil
+	}
+
+	var packSizes []models.PackSize
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("size IN ?", sizes).Order("size").Find(&packSizes).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(packSizes) != len(sizes) {
+		return nil, fmt.Errorf("%w: pack sizes %v are not all available", ErrInsufficientStock, sizes)
+	}
+	return packSizes, nil
+}
+
+// GetReservation returns a reservation and its packs
+func (s *PackService) GetReservation(id uint) (*models.Reservation, error) {
+	reservation, err := models.GetReservation(s.DB, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w %d", ErrUnknownReservation, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
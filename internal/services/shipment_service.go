@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"packify/internal/models"
+	"packify/pkg/calculator"
+
+	"gorm.io/gorm"
+)
+
+// ErrUnknownCarrier is returned when a shipment is planned for a carrier profile that does not exist
+var ErrUnknownCarrier = errors.New("unknown carrier")
+
+// ShipmentPlan is the packs of an order, the parcels they ship in and, with a rate card, what that costs
+type ShipmentPlan struct {
+	Result     *calculator.PackResult
+	Carrier    models.Carrier
+	Plan       calculator.ParcelPlan
+	Cost       *calculator.CostBreakdown // Nil when the carrier has no rate card
+	Objective  calculator.Objective
+	Fewest     *calculator.Shipment // With the cheapest objective, the packing the business rules pick
+	Candidates int                  // With the cheapest objective, the packings priced
+}
+
+// PlanShipment calculates the packs for an order and assigns them to the fewest parcels the carrier accepts,
+// an empty carrier name plans for the configured carrier. The fewest packs objective ships the optimal packs,
+// the cheapest objective the packs with the lowest packaging plus shipping cost on the carrier's rate card.
+func (s *PackService) PlanShipment(itemsOrdered int, options calculator.Options, carrierName string, objective calculator.Objective) (*ShipmentPlan, error) {
+	if carrierName == "" {
+		carrierName = s.Carrier
+	}
+	carrier, err := models.GetCarrier(s.DB, carrierName)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w %q", ErrUnknownCarrier, carrierName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	packSizes, err := s.GetPackSizes()
+	if err != nil {
+		return nil, err
+	}
+	sizes := make([]int, len(packSizes))
+	specs := make(map[int]calculator.PackSpec, len(packSizes))
+	for i, packSize := range packSizes {
+		sizes[i] = packSize.Size
+		specs[packSize.Size] = calculator.PackSpec{Weight: packSize.Weight, Volume: packSize.Volume(), Cost: packSize.Cost}
+	}
+
+	// Carriers state volumes in cubic centimetres, packs in cubic millimetres
+	limits := calculator.ParcelLimits{MaxWeight: carrier.MaxWeight, MaxVolume: carrier.MaxVolume * 1000}
+	rates := newRateCard(carrier)
+
+	if objective == calculator.ObjectiveCheapest {
+		cheapest, err := calculator.CalculateCheapest(itemsOrdered, sizes, options, specs, limits, rates)
+		if err != nil {
+			return nil, err
+		}
+		return &ShipmentPlan{
+			Result:     &cheapest.Cheapest.PackResult,
+			Carrier:    carrier,
+			Plan:       cheapest.Cheapest.Plan,
+			Cost:       &cheapest.Cheapest.Cost,
+			Objective:  objective,
+			Fewest:     &cheapest.Fewest,
+			Candidates: cheapest.Candidates,
+		}, nil
+	}
+
+	result, err := calculator.CalculatePacksWithOptions(itemsOrdered, sizes, options)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := calculator.PlanParcels(result.PackCounts, specs, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	shipment := &ShipmentPlan{Result: &result, Carrier: carrier, Plan: plan, Objective: calculator.ObjectiveFewestPacks}
+	if !rates.IsZero() {
+		cost, err := rates.Cost(result.PackCounts, specs, plan)
+		if err != nil && !errors.Is(err, calculator.ErrNoRate) {
+			return nil, err
+		}
+		if err == nil {
+			shipment.Cost = &cost
+		}
+	}
+	return shipment, nil
+}
+
+// newRateCard converts the rate bands of a carrier
+func newRateCard(carrier models.Carrier) calculator.RateCard {
+	rates := calculator.RateCard{ParcelFee: carrier.ParcelFee}
+	for _, band := range carrier.Rates {
+		rates.Bands = append(rates.Bands, calculator.RateBand{MaxWeight: band.MaxWeight, Price: band.Price})
+	}
+	return rates
+}
+
+// GetRateCard returns the rate card of a carrier
+func (s *PackService) GetRateCard(carrierName string) (calculator.RateCard, error) {
+	carrier, err := models.GetCarrier(s.DB, carrierName)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return calculator.RateCard{}, fmt.Errorf("%w %q", ErrUnknownCarrier, carrierName)
+	}
+	if err != nil {
+		return calculator.RateCard{}, err
+	}
+	return newRateCard(carrier), nil
+}
+
+// SetRateCard replaces the rate card of a carrier
+func (s *PackService) SetRateCard(carrierName string, rates calculator.RateCard) error {
+	if err := rates.Validate(); err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		carrier, err := models.GetCarrier(tx, carrierName)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w %q", ErrUnknownCarrier, carrierName)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&carrier).Update("parcel_fee", rates.ParcelFee).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("carrier_id = ?", carrier.ID).Delete(&models.RateBand{}).Error; err != nil {
+			return err
+		}
+		for _, band := range rates.Bands {
+			rate := models.RateBand{CarrierID: carrier.ID, MaxWeight: band.MaxWeight, Price: band.Price}
+			if err := tx.Create(&rate).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetCarriers returns all carrier profiles
+func (s *PackService) GetCarriers() ([]models.Carrier, error) {
+	var carriers []models.Carrier
+	if err := s.DB.Order("name").Find(&carriers).Error; err != nil {
+		return nil, err
+	}
+	return carriers, nil
+}
+
+// AddCarrier adds a new carrier profile
+func (s *PackService) AddCarrier(carrier models.Carrier) error {
+	return s.DB.Create(&carrier).Error
+}
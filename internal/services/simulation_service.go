@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"packify/internal/models"
+	"packify/pkg/calculator"
+
+	"gorm.io/gorm"
+)
+
+// ErrUnknownOrderHistory is returned when an order history that does not exist is simulated
+var ErrUnknownOrderHistory = errors.New("unknown order history")
+
+// ErrInvalidOrders is returned when an order history is saved without orders or with an order that is not positive
+var ErrInvalidOrders = errors.New("invalid orders")
+
+// historyBatchSize is the number of orders of a history inserted at once
+const historyBatchSize = 1000
+
+// SimulatePackSets compares packing orders with the available pack sizes and with candidate ones. The orders
+// are those given, or those of the order history with a name when none are.
+func (s *PackService) SimulatePackSets(candidatePackSizes []int, orders []int, historyName string, options calculator.Options) (*calculator.Simulation, error) {
+	orders, err := s.resolveOrders(orders, historyName)
+	if err != nil {
+		return nil, err
+	}
+
+	packSizes, err := models.GetPackSizes(s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	simulation, err := calculator.SimulatePackSets(orders, packSizes, candidatePackSizes, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &simulation, nil
+}
+
+// RecommendPackSets recommends the sets of at most maxSizes pack sizes that pack orders best. The orders are those
+// given, or those of the order history with a name when none are.
+func (s *PackService) RecommendPackSets(orders []int, historyName string, maxSizes int, candidates []int, top int, options calculator.Options) (*calculator.Recommendation, error) {
+	orders, err := s.resolveOrders(orders, historyName)
+	if err != nil {
+		return nil, err
+	}
+
+	recommendation, err := calculator.RecommendPackSets(orders, maxSizes, candidates, top, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &recommendation, nil
+}
+
+// resolveOrders returns the orders given, or those of the order history with a name when none are
+func (s *PackService) resolveOrders(orders []int, historyName string) ([]int, error) {
+	if len(orders) > 0 {
+		return orders, nil
+	}
+
+	history, err := models.GetOrderHistory(s.DB, historyName)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w %q", ErrUnknownOrderHistory, historyName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range history.Orders {
+		orders = append(orders, order.ItemsOrdered)
+	}
+	return orders, nil
+}
+
+// GetOrderHistories returns the name and number of orders of every order history
+func (s *PackService) GetOrderHistories() ([]models.OrderHistorySummary, error) {
+	return models.GetOrderHistories(s.DB)
+}
+
+// SaveOrderHistory replaces the orders of an order history, creating it if there is none with the name
+func (s *PackService) SaveOrderHistory(name string, orders []int) error {
+	if len(orders) == 0 {
+		return fmt.Errorf("%w: an order history needs at least one order", ErrInvalidOrders)
+	}
+	for _, itemsOrdered := range orders {
+		if itemsOrdered <= 0 {
+			return fmt.Errorf("%w: items of every order must be greater than 0, got %d", ErrInvalidOrders, itemsOrdered)
+		}
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		history := models.OrderHistory{Name: name}
+		if err := tx.Where("name = ?", name).FirstOrCreate(&history).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("order_history_id = ?", history.ID).Delete(&models.HistoricalOrder{}).Error; err != nil {
+			return err
+		}
+		rows := make([]models.HistoricalOrder, len(orders))
+		for i, itemsOrdered := range orders {
+			rows[i] = models.HistoricalOrder{OrderHistoryID: history.ID, ItemsOrdered: itemsOrdered}
+		}
+		return tx.CreateInBatches(rows, historyBatchSize).Error
+	})
+}
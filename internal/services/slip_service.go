@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"packify/internal/models"
+	"packify/internal/printing"
+	"packify/pkg/calculator"
+)
+
+// ErrOrderNotCalculated is returned when a slip is requested for an order that has no packs calculated yet
+var ErrOrderNotCalculated = errors.New("order is not calculated")
+
+// CalculationSlip returns the packing slip and pick list of a calculation outside an order. The calculation
+// is not recorded again, it was recorded when it was calculated.
+func (s *PackService) CalculationSlip(itemsOrdered int, reference string, options calculator.Options) (*printing.Slip, error) {
+	packSizes, err := models.GetPackSizes(s.DB)
+	if err != nil {
+		return nil, err
+	}
+	result, err := calculator.CalculatePacksWithOptions(itemsOrdered, packSizes, options)
+	if err != nil {
+		return nil, err
+	}
+
+	slip := &printing.Slip{
+		Reference:    reference,
+		Date:         time.Now(),
+		ItemsOrdered: itemsOrdered,
+		TotalPacks:   result.TotalPacks,
+		TotalItems:   result.TotalItems,
+		ExcessItems:  result.ExcessItems,
+		ShortItems:   result.ShortItems,
+	}
+	if slip.Packs, err = s.slipPacks(result.PackCounts); err != nil {
+		return nil, err
+	}
+	return slip, nil
+}
+
+// OrderSlip returns the packing slip and pick list of the packs calculated for an order
+func (s *PackService) OrderSlip(id uint) (*printing.Slip, error) {
+	order, err := s.GetOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status == models.OrderDraft {
+		return nil, fmt.Errorf("%w: order %s is %s", ErrOrderNotCalculated, order.Reference, order.Status)
+	}
+
+	slip := &printing.Slip{
+		Reference:    order.Reference,
+		Customer:     order.Customer,
+		Date:         time.Now(),
+		ItemsOrdered: order.ItemsOrdered,
+		TotalPacks:   order.TotalPacks,
+		TotalItems:   order.TotalItems,
+		ExcessItems:  order.ExcessItems,
+		ShortItems:   order.ShortItems,
+	}
+	if slip.Packs, err = s.slipPacks(order.PackCounts()); err != nil {
+		return nil, err
+	}
+	return slip, nil
+}
+
+// slipPacks returns the packs of a slip with the shelves they are picked from. Sizes deleted since the packs
+// were calculated have no shelf.
+func (s *PackService) slipPacks(packCounts map[int]int) ([]printing.SlipPack, error) {
+	var packSizes []models.PackSize
+	if err := s.DB.Find(&packSizes).Error; err != nil {
+		return nil, err
+	}
+	shelves := make(map[int]string, len(packSizes))
+	for _, packSize := range packSizes {
+		shelves[packSize.Size] = packSize.Shelf
+	}
+
+	packs := make([]printing.SlipPack, 0, len(packCounts))
+	for size, count := range packCounts {
+		if count > 0 {
+			packs = append(packs, printing.SlipPack{Size: size, Count: count, Shelf: shelves[size]})
+		}
+	}
+	return packs, nil
+}
@@ -9,6 +9,7 @@ import (
 	"packify/internal/handlers"
 	"packify/internal/models"
 	"packify/internal/services"
+	"packify/pkg/calculator"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -26,7 +27,8 @@ func main() {
 	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
 		cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password, cfg.Database.Name)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// TranslateError reports unique violations as gorm.ErrDuplicatedKey
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
@@ -37,7 +39,32 @@ func main() {
 	}
 
 	// Initialize services
-	packService := services.NewPackService(db)
+	tieBreak, err := calculator.ParseTieBreak(cfg.Calculator.TieBreak)
+	if err != nil {
+		log.Fatalf("Invalid calculator configuration: %v", err)
+	}
+	distinctRule, err := calculator.ParseDistinctRule(cfg.Calculator.DistinctRule)
+	if err != nil {
+		log.Fatalf("Invalid calculator configuration: %v", err)
+	}
+	fulfilment, err := calculator.ParseFulfilment(cfg.Calculator.Fulfilment)
+	if err != nil {
+		log.Fatalf("Invalid calculator configuration: %v", err)
+	}
+	tolerance, err := calculator.ParseTolerance(cfg.Calculator.Tolerance)
+	if err != nil {
+		log.Fatalf("Invalid calculator configuration: %v", err)
+	}
+	packaging, err := calculator.ParseHierarchy(cfg.Calculator.Packaging)
+	if err != nil {
+		log.Fatalf("Invalid calculator configuration: %v", err)
+	}
+	packService := services.NewPackService(db, calculator.Options{
+		TieBreak:     tieBreak,
+		DistinctRule: distinctRule,
+		Fulfilment:   fulfilment,
+		Tolerance:    tolerance,
+	}, packaging, cfg.Calculator.Carrier)
 
 	// Initialize template renderer
 	renderer, err := handlers.NewTemplateRenderer()
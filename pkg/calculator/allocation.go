@@ -0,0 +1,234 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// maxAllocationLocations caps the locations PlanAllocation combines, every subset of them may be searched
+const maxAllocationLocations = 12
+
+// maxAllocationTotals caps the DP totals PlanAllocation covers across the sets of locations it searches,
+// about a second of work
+const maxAllocationTotals = 10 * safetyThreshold
+
+// ErrAllocationTooLarge is returned when an order would need more locations or sets of locations searched
+// than PlanAllocation allows
+var ErrAllocationTooLarge = errors.New("allocation too large")
+
+// Location is a stock location such as a warehouse and the packs it holds
+type Location struct {
+	Name  string      // Name of the location
+	Stock map[int]int // Packs in stock per size
+}
+
+// LocationShipment is the part of an order one location ships
+type LocationShipment struct {
+	Location string
+	Result   PackResult // Packs shipped from the location, its share of the order is what it ships up to the rest
+}
+
+// Allocation is how an order ships from one or more locations
+type Allocation struct {
+	Shipments []LocationShipment // One per location that ships, in the order of the locations
+	Total     PackResult         // All packs shipped
+	Split     bool               // Whether more than one location ships
+}
+
+// PlanAllocation decides which locations ship an order. A single location is preferred, in the order given,
+// and the order is split across the fewest locations only when no single one can fulfil it from its stock.
+// Among equally many locations the best packing by the business rules wins. When under-shipping, a location
+// fulfils the order if it ships as many items as all locations together could.
+// Stock bounds the packs of each size like a pack limit and is combined with the limits of the options,
+// unless it covers the whole order. Orders needing more sets of locations searched than maxAllocationTotals
+// allows fail with ErrAllocationTooLarge before they are searched.
+func PlanAllocation(itemsOrdered int, locations []Location, options Options) (Allocation, error) {
+	if len(locations) == 0 {
+		return Allocation{}, fmt.Errorf("no locations to ship from")
+	}
+	if len(locations) > maxAllocationLocations {
+		return Allocation{}, fmt.Errorf("%w: at most %d locations can be combined, got %d",
+			ErrAllocationTooLarge, maxAllocationLocations, len(locations))
+	}
+	for _, location := range locations {
+		for size, count := range location.Stock {
+			if size <= 0 || count < 0 {
+				return Allocation{}, fmt.Errorf("invalid stock of %d packs of size %d at %s", count, size, location.Name)
+			}
+		}
+	}
+
+	// Everything in stock bounds what any set of locations can do
+	everywhere := make([]int, len(locations))
+	for i := range locations {
+		everywhere[i] = i
+	}
+	pooled, err := packFromLocations(itemsOrdered, locations, everywhere, options)
+	if err != nil {
+		return Allocation{}, err
+	}
+
+	// Each set of locations runs a DP over about as many totals as the pooled one, a level of sets is only
+	// searched if it fits in what is left of maxAllocationTotals
+	span := min(itemsOrdered, safetyThreshold) + largestInStock(locations)
+	searched := span
+	for count := 1; count <= len(locations); count++ {
+		if searched += subsets(len(locations), count) * span; searched > maxAllocationTotals {
+			return Allocation{}, fmt.Errorf("%w: splitting %d items across %d of %d locations needs more than %d DP totals searched",
+				ErrAllocationTooLarge, itemsOrdered, count, len(locations), maxAllocationTotals)
+		}
+
+		var best PackResult
+		var bestSet []int
+		forEachSubset(len(locations), count, func(set []int) {
+			result, err := packFromLocations(itemsOrdered, locations, set, options)
+			if err != nil || result.TotalItems != pooled.TotalItems && options.underShip() {
+				return
+			}
+			if bestSet == nil || options.Less(result, best) {
+				best, bestSet = result, append([]int(nil), set...)
+			}
+		})
+		if bestSet != nil {
+			return allocate(itemsOrdered, locations, bestSet, best), nil
+		}
+	}
+
+	// The pooled packing uses every location at worst
+	return allocate(itemsOrdered, locations, everywhere, pooled), nil
+}
+
+// packFromLocations calculates the best packing from the stock of a set of locations.
+// Stock of a size that covers every total the order may ship does not bound the packs of that size,
+// so a large stock of the largest pack does not rule out the bulk of a large order.
+func packFromLocations(itemsOrdered int, locations []Location, set []int, options Options) (PackResult, error) {
+	stock := make(map[int]int)
+	for _, i := range set {
+		for size, count := range locations[i].Stock {
+			stock[size] += count
+		}
+	}
+
+	shipped := mostShipped(itemsOrdered, locations, options)
+
+	var packSizes []int
+	limits := make(map[int]PackLimit)
+	for size, count := range stock {
+		if count == 0 {
+			continue
+		}
+		limit, ok := options.Limits[size]
+		if !ok {
+			limit = NoLimit()
+		}
+		covers := new(big.Int).Mul(big.NewInt(int64(count)), big.NewInt(int64(size))).Cmp(shipped) >= 0
+		if !covers && (limit.Max == Unlimited || limit.Max > count) {
+			limit.Max = count
+		}
+		if limit.Max != Unlimited && limit.Min > limit.Max {
+			return PackResult{}, fmt.Errorf("%w: %d packs of size %d required, %d in stock", ErrInfeasible, limit.Min, size, count)
+		}
+		packSizes = append(packSizes, size)
+		limits[size] = limit
+	}
+	for size, limit := range options.Limits {
+		if _, ok := limits[size]; !ok && limit.Min > 0 {
+			return PackResult{}, fmt.Errorf("%w: %d packs of size %d required, none in stock", ErrInfeasible, limit.Min, size)
+		}
+	}
+	if len(packSizes) == 0 {
+		return PackResult{}, fmt.Errorf("%w: nothing in stock", ErrInfeasible)
+	}
+
+	options.Limits = limits
+	return CalculatePacksWithOptions(itemsOrdered, packSizes, options)
+}
+
+// allocate splits a packing across a set of locations, taking each size from the first location that stocks it
+func allocate(itemsOrdered int, locations []Location, set []int, result PackResult) Allocation {
+	remaining := make(map[int]int, len(result.PackCounts))
+	for size, count := range result.PackCounts {
+		remaining[size] = count
+	}
+
+	allocation := Allocation{Total: result}
+	for _, i := range set {
+		packCounts := make(map[int]int)
+		for size, count := range remaining {
+			if take := min(count, locations[i].Stock[size]); take > 0 {
+				packCounts[size] = take
+				remaining[size] -= take
+			}
+		}
+		if len(packCounts) > 0 {
+			allocation.Shipments = append(allocation.Shipments, LocationShipment{Location: locations[i].Name, Result: newPackResult(0, packCounts)})
+		}
+	}
+
+	// Each location's share of the order is what it ships, the last one also takes the excess or shortfall
+	rest := itemsOrdered
+	for i := range allocation.Shipments {
+		shipment := &allocation.Shipments[i]
+		share := min(shipment.Result.TotalItems, rest)
+		if i == len(allocation.Shipments)-1 {
+			share = rest
+		}
+		shipment.Result = newPackResult(share, shipment.Result.PackCounts)
+		rest -= share
+	}
+
+	allocation.Split = len(allocation.Shipments) > 1
+	return allocation
+}
+
+// mostShipped returns more items than any packing of an order ships: the order or the minimum packs,
+// its tolerance and a largest pack
+func mostShipped(itemsOrdered int, locations []Location, options Options) *big.Int {
+	minimums := new(big.Int)
+	for size, limit := range options.Limits {
+		minimums.Add(minimums, new(big.Int).Mul(big.NewInt(int64(limit.Min)), big.NewInt(int64(size))))
+	}
+
+	shipped := big.NewInt(int64(itemsOrdered))
+	if minimums.Cmp(shipped) > 0 {
+		shipped.Set(minimums)
+	}
+	shipped.Add(shipped, options.Tolerance.allowance(big.NewInt(int64(itemsOrdered))))
+	return shipped.Add(shipped, big.NewInt(int64(largestInStock(locations))))
+}
+
+// largestInStock returns the largest pack size any location stocks, 0 if none does
+func largestInStock(locations []Location) int {
+	largest := 0
+	for _, location := range locations {
+		for size, count := range location.Stock {
+			if count > 0 {
+				largest = max(largest, size)
+			}
+		}
+	}
+	return largest
+}
+
+// subsets returns the number of subsets of count indexes below n
+func subsets(n, count int) int {
+	return int(new(big.Int).Binomial(int64(n), int64(count)).Int64())
+}
+
+// forEachSubset visits every subset of count indexes below n in lexicographic order
+func forEachSubset(n, count int, visit func(set []int)) {
+	set := make([]int, count)
+	var walk func(position, start int)
+	walk = func(position, start int) {
+		if position == count {
+			visit(set)
+			return
+		}
+		for i := start; i <= n-(count-position); i++ {
+			set[position] = i
+			walk(position+1, i+1)
+		}
+	}
+	walk(0, 0)
+}
@@ -0,0 +1,180 @@
+package calculator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPlanAllocation(t *testing.T) {
+	testCases := []struct {
+		name           string
+		itemsOrdered   int
+		locations      []Location
+		fulfilment     Fulfilment
+		limits         map[int]PackLimit
+		expectedShips  []string // location: packs
+		expectedShort  []int    // short items per shipment
+		expectedExcess []int    // excess items per shipment
+		expectSplit    bool
+		expectError    error
+	}{
+		{
+			name:         "Only one location has enough stock",
+			itemsOrdered: 5001,
+			locations: []Location{
+				{Name: "north", Stock: map[int]int{5000: 1}},
+				{Name: "south", Stock: map[int]int{5000: 5, 250: 5}},
+			},
+			expectedShips:  []string{"south: 1x5000 1x250"},
+			expectedShort:  []int{0},
+			expectedExcess: []int{249},
+		},
+		{
+			name:         "First location among equals",
+			itemsOrdered: 500,
+			locations: []Location{
+				{Name: "north", Stock: map[int]int{500: 1}},
+				{Name: "south", Stock: map[int]int{500: 1}},
+			},
+			expectedShips:  []string{"north: 1x500"},
+			expectedShort:  []int{0},
+			expectedExcess: []int{0},
+		},
+		{
+			name:         "Best packing among single locations",
+			itemsOrdered: 1000,
+			locations: []Location{
+				{Name: "north", Stock: map[int]int{250: 4}},
+				{Name: "south", Stock: map[int]int{1000: 1}},
+			},
+			expectedShips:  []string{"south: 1x1000"},
+			expectedShort:  []int{0},
+			expectedExcess: []int{0},
+		},
+		{
+			name:         "Single location preferred over a better split",
+			itemsOrdered: 1000,
+			locations: []Location{
+				{Name: "north", Stock: map[int]int{250: 4}},
+				{Name: "south", Stock: map[int]int{500: 1}},
+				{Name: "east", Stock: map[int]int{500: 1}},
+			},
+			expectedShips:  []string{"north: 4x250"},
+			expectedShort:  []int{0},
+			expectedExcess: []int{0},
+		},
+		{
+			name:         "Split when no location has enough",
+			itemsOrdered: 10001,
+			locations: []Location{
+				{Name: "north", Stock: map[int]int{5000: 1}},
+				{Name: "south", Stock: map[int]int{5000: 1, 250: 2}},
+				{Name: "east", Stock: map[int]int{250: 10}},
+			},
+			expectedShips:  []string{"north: 1x5000", "south: 1x5000 1x250"},
+			expectedShort:  []int{0, 0},
+			expectedExcess: []int{0, 249},
+			expectSplit:    true,
+		},
+		{
+			name:         "Under-shipment splits to ship the most items",
+			itemsOrdered: 800,
+			fulfilment:   FulfilmentUnderShip,
+			locations: []Location{
+				{Name: "north", Stock: map[int]int{250: 1}},
+				{Name: "south", Stock: map[int]int{500: 1}},
+			},
+			expectedShips:  []string{"north: 1x250", "south: 1x500"},
+			expectedShort:  []int{0, 50},
+			expectedExcess: []int{0, 0},
+			expectSplit:    true,
+		},
+		{
+			name:         "Pack limits apply to the whole order",
+			itemsOrdered: 500,
+			limits:       map[int]PackLimit{250: {Min: 2, Max: Unlimited}},
+			locations: []Location{
+				{Name: "north", Stock: map[int]int{500: 1, 250: 1}},
+				{Name: "south", Stock: map[int]int{250: 2}},
+			},
+			expectedShips:  []string{"south: 2x250"},
+			expectedShort:  []int{0},
+			expectedExcess: []int{0},
+		},
+		{
+			name:         "Large order from a location with plenty of stock",
+			itemsOrdered: 2000001,
+			locations: []Location{
+				{Name: "north", Stock: map[int]int{5000: 10}},
+				{Name: "south", Stock: map[int]int{5000: 1000, 250: 2}},
+			},
+			expectedShips:  []string{"south: 400x5000 1x250"},
+			expectedShort:  []int{0},
+			expectedExcess: []int{249},
+		},
+		{
+			name:         "Too many sets of locations to search",
+			itemsOrdered: 900000,
+			locations: []Location{
+				{Name: "north", Stock: map[int]int{5000: 50}},
+				{Name: "south", Stock: map[int]int{5000: 50}},
+				{Name: "east", Stock: map[int]int{5000: 50}},
+				{Name: "west", Stock: map[int]int{5000: 50}},
+			},
+			expectError: ErrAllocationTooLarge,
+		},
+		{
+			name:         "Not enough stock anywhere",
+			itemsOrdered: 20000,
+			locations: []Location{
+				{Name: "north", Stock: map[int]int{5000: 1}},
+				{Name: "south", Stock: map[int]int{5000: 1}},
+			},
+			expectError: ErrInfeasible,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options := DefaultOptions()
+			if tc.fulfilment != "" {
+				options.Fulfilment = tc.fulfilment
+			}
+			options.Limits = tc.limits
+
+			allocation, err := PlanAllocation(tc.itemsOrdered, tc.locations, options)
+			if tc.expectError != nil {
+				if !errors.Is(err, tc.expectError) {
+					t.Errorf("Expected %v, got %v", tc.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(allocation.Shipments) != len(tc.expectedShips) {
+				t.Fatalf("Expected shipments %v, got %+v", tc.expectedShips, allocation.Shipments)
+			}
+			shipped := make(map[int]int)
+			for i, shipment := range allocation.Shipments {
+				if got := shipment.Location + ": " + formatPackCounts(shipment.Result.PackCounts); got != tc.expectedShips[i] {
+					t.Errorf("Expected shipment %s, got %s", tc.expectedShips[i], got)
+				}
+				if shipment.Result.ShortItems != tc.expectedShort[i] || shipment.Result.ExcessItems != tc.expectedExcess[i] {
+					t.Errorf("Expected %s to be short %d and over %d items, got %+v",
+						shipment.Location, tc.expectedShort[i], tc.expectedExcess[i], shipment.Result)
+				}
+				for size, count := range shipment.Result.PackCounts {
+					shipped[size] += count
+				}
+			}
+			if formatPackCounts(shipped) != formatPackCounts(allocation.Total.PackCounts) {
+				t.Errorf("Expected shipments to add up to %v, got %v", allocation.Total.PackCounts, shipped)
+			}
+			if allocation.Split != tc.expectSplit {
+				t.Errorf("Expected split %v, got %v", tc.expectSplit, allocation.Split)
+			}
+		})
+	}
+}
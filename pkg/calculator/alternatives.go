@@ -0,0 +1,170 @@
+package calculator
+
+import (
+	"fmt"
+	"sort"
+)
+
+// maxOptimalPackings caps the number of tied optimal packings returned by CalculateAlternatives
+const maxOptimalPackings = 100
+
+// maxRankedPackings caps the number of packings of a total that are ranked when distinct pack sizes
+// are compared, otherwise packings are enumerated already in ranking order. Beyond it the ranking is
+// approximate, but the first optimal packing is always the one CalculatePacksWithOptions returns.
+const maxRankedPackings = 1000
+
+// Alternatives holds every optimal packing of an order and the next best ones
+type Alternatives struct {
+	Optimal   []PackResult // Packings tied on every business rule, in tie-break order
+	NextBest  []PackResult // The next best packings ranked by the business rules, then the tie-break policy
+	Truncated bool         // Whether Optimal was capped at maxOptimalPackings
+}
+
+// CalculateAlternatives returns all optimal packings of an order plus the topK next best ones.
+// Packings are ranked by the business rules: fewest items first (rule 2), or most items at or below
+// the order when under-shipping, then fewest packs (rule 3)
+// and fewest distinct pack sizes in the order set by the distinct rule.
+// Packings tied on every rule are ordered by the tie-break policy, so the first optimal packing
+// is the one CalculatePacksWithOptions returns.
+// Every packing holds the per-size minimums of the pack limits and no more than their maximums.
+// Packings within the excess tolerance are ranked together, up to the safety threshold of excess items.
+// Orders above the safety threshold keep the bulk of largest packs that every optimal packing
+// shares (see CalculatePacksBig), so their next best packings only vary the remainder.
+func CalculateAlternatives(itemsOrdered int, availablePackSizes []int, topK int, options Options) (Alternatives, error) {
+	packSizes, err := preparePackSizes(itemsOrdered, availablePackSizes)
+	if err != nil {
+		return Alternatives{}, err
+	}
+
+	if topK < 0 {
+		return Alternatives{}, fmt.Errorf("number of alternatives must not be negative")
+	}
+
+	if err := options.validate(); err != nil {
+		return Alternatives{}, err
+	}
+
+	p, err := newIntProblem(itemsOrdered, packSizes, options)
+	if err != nil {
+		return Alternatives{}, err
+	}
+	fixed, _ := p.fixedPacks()
+	target := p.target
+
+	// Every total within the tolerance is ranked, not only the ones the DP searches
+	limit := p.limit(options) + p.tolerance - p.allowance
+	minPacks := packsTable(limit, packSizes)
+	maxPacks := maxPacksTable(limit, packSizes)
+
+	capacity := maxRankedPackings
+	if !options.countsDistinct() {
+		capacity = maxOptimalPackings + topK + 1
+	}
+
+	// The first optimal packing is the solver's, a group of tied packings may be capped before the best
+	// of them by the tie-break policy is ranked
+	best, err := CalculatePacksWithOptions(itemsOrdered, packSizes, options)
+	if err != nil {
+		return Alternatives{}, err
+	}
+	alternatives := Alternatives{Optimal: []PackResult{best}}
+
+	// rank ranks the packings of totals from first to last with packCount to lastCount packs as one group
+	// and places them, it returns false once the alternatives are complete
+	rank := func(first, last, packCount, lastCount int) bool {
+		var group []PackResult
+		for count := packCount; count <= lastCount && len(group) < capacity; count++ {
+			for total := first; total <= last && len(group) < capacity; total++ {
+				if minPacks[total] == unreachable || count < minPacks[total] || count > maxPacks[total] {
+					continue
+				}
+				enumeratePackingsWithCount(total, count, packSizes, p.caps, minPacks, maxPacks, options.preferMore(), func(packCounts map[int]int) bool {
+					group = append(group, newPackResult(itemsOrdered, withFixedPacks(packCounts, fixed)))
+					return len(group) < capacity
+				})
+			}
+		}
+		sort.SliceStable(group, func(i, j int) bool {
+			return options.Less(group[i], group[j])
+		})
+
+		for _, packing := range group {
+			if samePacks(packing.PackCounts, best.PackCounts) {
+				continue
+			}
+			if options.Tied(packing, best) {
+				if len(alternatives.Optimal) == maxOptimalPackings {
+					alternatives.Truncated = true
+					continue
+				}
+				alternatives.Optimal = append(alternatives.Optimal, packing)
+				continue
+			}
+
+			if len(alternatives.NextBest) == topK {
+				return false
+			}
+			alternatives.NextBest = append(alternatives.NextBest, packing)
+		}
+		return true
+	}
+
+	// rankTotals ranks the packings of totals from first to last, one pack count at a time,
+	// or all together when distinct pack sizes are minimised before rule 3
+	rankTotals := func(first, last int) bool {
+		fewest, most := unreachable, unreachable
+		for total := first; total <= last; total++ {
+			if minPacks[total] == unreachable {
+				continue
+			}
+			if fewest == unreachable || minPacks[total] < fewest {
+				fewest = minPacks[total]
+			}
+			if maxPacks[total] > most {
+				most = maxPacks[total]
+			}
+		}
+		if fewest == unreachable {
+			return true
+		}
+		if options.distinctFirst() {
+			return rank(first, last, fewest, most)
+		}
+		for packCount := fewest; packCount <= most; packCount++ {
+			if !rank(first, last, packCount, packCount) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Totals ascending is rule 2, descending when under-shipping. Totals within the excess tolerance
+	// are ranked together, rule 3 and the distinct rule come before their number of items.
+	if options.underShip() {
+		for total := target; total >= 0; total-- {
+			if !rankTotals(total, total) {
+				return alternatives, nil
+			}
+		}
+		return alternatives, nil
+	}
+
+	total := target
+	if p.tolerance > 0 {
+		for total < limit && minPacks[total] == unreachable {
+			total++
+		}
+		last := max(total, target+p.tolerance)
+		if !rankTotals(total, last) {
+			return alternatives, nil
+		}
+		total = last + 1
+	}
+	for ; total < limit; total++ {
+		if !rankTotals(total, total) {
+			return alternatives, nil
+		}
+	}
+
+	return alternatives, nil
+}
@@ -0,0 +1,147 @@
+package calculator
+
+import (
+	"testing"
+)
+
+func TestCalculateAlternatives(t *testing.T) {
+	testCases := []struct {
+		name               string
+		itemsOrdered       int
+		availablePackSizes []int
+		topK               int
+		expectedOptimal    []map[int]int
+		expectedNextBest   []map[int]int
+		expectError        bool
+	}{
+		{
+			name:               "Order 501 items",
+			itemsOrdered:       501,
+			availablePackSizes: []int{250, 500, 1000, 2000, 5000},
+			topK:               3,
+			expectedOptimal:    []map[int]int{{500: 1, 250: 1}},
+			expectedNextBest: []map[int]int{
+				{250: 3},  // Same items, more packs
+				{1000: 1}, // More items
+				{500: 2},
+			},
+		},
+		{
+			name:               "Tied optimal packings",
+			itemsOrdered:       1250,
+			availablePackSizes: []int{250, 500, 750, 1000},
+			topK:               2,
+			expectedOptimal:    []map[int]int{{1000: 1, 250: 1}, {750: 1, 500: 1}},
+			expectedNextBest: []map[int]int{
+				{750: 1, 250: 2}, // Same items in 3 packs, largest packs first
+				{500: 2, 250: 1},
+			},
+		},
+		{
+			name:               "No alternatives requested",
+			itemsOrdered:       250,
+			availablePackSizes: []int{250, 500, 1000, 2000, 5000},
+			topK:               0,
+			expectedOptimal:    []map[int]int{{250: 1}},
+			expectedNextBest:   nil,
+		},
+		{
+			name:               "Order 0 items",
+			itemsOrdered:       0,
+			availablePackSizes: []int{250, 500},
+			expectError:        true,
+		},
+		{
+			name:               "No pack sizes available",
+			itemsOrdered:       100,
+			availablePackSizes: []int{},
+			expectError:        true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			alternatives, err := CalculateAlternatives(tc.itemsOrdered, tc.availablePackSizes, tc.topK, DefaultOptions())
+
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			comparePackings(t, "optimal", tc.expectedOptimal, alternatives.Optimal)
+			comparePackings(t, "next best", tc.expectedNextBest, alternatives.NextBest)
+
+			// Every packing must cover the order and be ranked by rule 2 then rule 3
+			all := append(append([]PackResult(nil), alternatives.Optimal...), alternatives.NextBest...)
+			for i, packing := range all {
+				if packing.TotalItems < tc.itemsOrdered {
+					t.Errorf("Packing %v does not cover %d items", packing, tc.itemsOrdered)
+				}
+				if i > 0 {
+					previous := all[i-1]
+					if packing.ExcessItems < previous.ExcessItems ||
+						(packing.ExcessItems == previous.ExcessItems && packing.TotalPacks < previous.TotalPacks) {
+						t.Errorf("Packing %v is ranked after worse packing %v", packing, previous)
+					}
+				}
+			}
+		})
+	}
+}
+
+// comparePackings checks that got holds the expected pack counts in order
+func comparePackings(t *testing.T, name string, expected []map[int]int, got []PackResult) {
+	t.Helper()
+
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d %s packings, got %d: %v", len(expected), name, len(got), got)
+	}
+
+	for i, packCounts := range expected {
+		for size, count := range packCounts {
+			if got[i].PackCounts[size] != count {
+				t.Errorf("Expected %s packing %d to have %d packs of size %d, got %v", name, i, count, size, got[i])
+			}
+		}
+	}
+}
+
+// TestAlternativesMatchSolverWithManyTies checks that the first optimal packing is the one CalculatePacksWithOptions
+// returns when more packings tie than are ranked
+func TestAlternativesMatchSolverWithManyTies(t *testing.T) {
+	var packSizes []int
+	for size := 1; size <= 100; size++ {
+		packSizes = append(packSizes, size)
+	}
+
+	for _, tieBreak := range TieBreaks {
+		for _, rule := range DistinctRules {
+			options := DefaultOptions()
+			options.TieBreak = tieBreak
+			options.DistinctRule = rule
+
+			expected, err := CalculatePacksWithOptions(950, packSizes, options)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			alternatives, err := CalculateAlternatives(950, packSizes, 1, options)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(alternatives.Optimal) == 0 || !samePacks(alternatives.Optimal[0].PackCounts, expected.PackCounts) {
+				t.Errorf("%s, %s: expected %v first, got %v", tieBreak, rule, expected.PackCounts, alternatives.Optimal)
+			}
+			for i := 1; i < len(alternatives.Optimal); i++ {
+				if !options.Tied(alternatives.Optimal[i], alternatives.Optimal[0]) {
+					t.Errorf("%s, %s: %v is not tied with %v", tieBreak, rule, alternatives.Optimal[i], alternatives.Optimal[0])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package calculator
+
+import (
+	"fmt"
+)
+
+// Amendment is how the packs of an order change when its quantity is amended
+type Amendment struct {
+	Result    PackResult  // Packs of the amended order
+	Keep      map[int]int // Packs of the original packing that stay
+	Add       map[int]int // Packs to add
+	Remove    map[int]int // Packs of the original packing to remove
+	Rework    int         // Packs added plus packs removed
+	Truncated bool        // Whether the optimal packings were capped, so less rework may be possible
+}
+
+// AmendPacks calculates the packs of an order amended to itemsOrdered that was already packed as original.
+// The amended order follows the business rules like a new one, and among its optimal packings the one that
+// adds and removes the fewest packs is chosen, the first in tie-break order among equals. Original packs of
+// sizes that are no longer available are removed.
+func AmendPacks(original map[int]int, itemsOrdered int, availablePackSizes []int, options Options) (Amendment, error) {
+	for size, count := range original {
+		if size <= 0 || count < 0 {
+			return Amendment{}, fmt.Errorf("invalid original packing of %d packs of size %d", count, size)
+		}
+	}
+
+	alternatives, err := CalculateAlternatives(itemsOrdered, availablePackSizes, 0, options)
+	if err != nil {
+		return Amendment{}, err
+	}
+
+	var best Amendment
+	for i, packing := range alternatives.Optimal {
+		amendment := newAmendment(original, packing)
+		if i == 0 || amendment.Rework < best.Rework {
+			best = amendment
+		}
+	}
+	best.Truncated = alternatives.Truncated
+	return best, nil
+}
+
+// newAmendment compares an original packing with the amended one
+func newAmendment(original map[int]int, result PackResult) Amendment {
+	amendment := Amendment{Result: result, Keep: make(map[int]int), Add: make(map[int]int), Remove: make(map[int]int)}
+	for size, count := range result.PackCounts {
+		if kept := min(count, original[size]); kept > 0 {
+			amendment.Keep[size] = kept
+		}
+		if count > original[size] {
+			amendment.Add[size] = count - original[size]
+			amendment.Rework += count - original[size]
+		}
+	}
+	for size, count := range original {
+		if count > result.PackCounts[size] {
+			amendment.Remove[size] = count - result.PackCounts[size]
+			amendment.Rework += count - result.PackCounts[size]
+		}
+	}
+	return amendment
+}
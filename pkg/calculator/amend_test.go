@@ -0,0 +1,119 @@
+package calculator
+
+import (
+	"testing"
+)
+
+func TestAmendPacks(t *testing.T) {
+	packSizes := []int{250, 500, 750, 1000}
+
+	testCases := []struct {
+		name           string
+		original       map[int]int
+		itemsOrdered   int
+		expectedResult string
+		expectedAdd    string
+		expectedRemove string
+		expectedRework int
+	}{
+		{
+			name:           "Unchanged optimal packing is kept",
+			original:       map[int]int{750: 1, 500: 1},
+			itemsOrdered:   1250,
+			expectedResult: "1x750 1x500",
+		},
+		{
+			name:           "Fewest changes among optimal packings",
+			original:       map[int]int{1000: 1, 250: 1},
+			itemsOrdered:   1500,
+			expectedResult: "1x1000 1x500",
+			expectedAdd:    "1x500",
+			expectedRemove: "1x250",
+			expectedRework: 2,
+		},
+		{
+			name:           "Reduced order removes packs",
+			original:       map[int]int{1000: 1, 250: 1},
+			itemsOrdered:   1000,
+			expectedResult: "1x1000",
+			expectedRemove: "1x250",
+			expectedRework: 1,
+		},
+		{
+			name:           "Increased order adds packs",
+			original:       map[int]int{1000: 1},
+			itemsOrdered:   1100,
+			expectedResult: "1x1000 1x250",
+			expectedAdd:    "1x250",
+			expectedRework: 1,
+		},
+		{
+			name:           "Rules come before rework",
+			original:       map[int]int{500: 2},
+			itemsOrdered:   1000,
+			expectedResult: "1x1000",
+			expectedAdd:    "1x1000",
+			expectedRemove: "2x500",
+			expectedRework: 3,
+		},
+		{
+			name:           "Unavailable sizes are removed",
+			original:       map[int]int{2000: 1},
+			itemsOrdered:   2000,
+			expectedResult: "2x1000",
+			expectedAdd:    "2x1000",
+			expectedRemove: "1x2000",
+			expectedRework: 3,
+		},
+		{
+			name:           "Nothing packed yet",
+			itemsOrdered:   1250,
+			expectedResult: "1x1000 1x250",
+			expectedAdd:    "1x1000 1x250",
+			expectedRework: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			amendment, err := AmendPacks(tc.original, tc.itemsOrdered, packSizes, DefaultOptions())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got := formatPackCounts(amendment.Result.PackCounts); got != tc.expectedResult {
+				t.Errorf("Expected %s, got %s", tc.expectedResult, got)
+			}
+			if got := formatPackCounts(amendment.Add); got != tc.expectedAdd {
+				t.Errorf("Expected to add %s, got %s", tc.expectedAdd, got)
+			}
+			if got := formatPackCounts(amendment.Remove); got != tc.expectedRemove {
+				t.Errorf("Expected to remove %s, got %s", tc.expectedRemove, got)
+			}
+			if amendment.Rework != tc.expectedRework {
+				t.Errorf("Expected rework %d, got %d", tc.expectedRework, amendment.Rework)
+			}
+
+			// Kept and added packs make up the amended packing
+			packCounts := make(map[int]int)
+			for size, count := range amendment.Keep {
+				packCounts[size] += count
+			}
+			for size, count := range amendment.Add {
+				packCounts[size] += count
+			}
+			if formatPackCounts(packCounts) != formatPackCounts(amendment.Result.PackCounts) {
+				t.Errorf("Expected kept and added packs to make up %v, got %v", amendment.Result.PackCounts, packCounts)
+			}
+		})
+	}
+}
+
+func TestAmendPacksInvalid(t *testing.T) {
+	if _, err := AmendPacks(map[int]int{250: -1}, 500, []int{250}, DefaultOptions()); err == nil {
+		t.Errorf("Expected error for a negative pack count")
+	}
+	if _, err := AmendPacks(nil, 0, []int{250}, DefaultOptions()); err == nil {
+		t.Errorf("Expected error for an empty order")
+	}
+}
@@ -0,0 +1,151 @@
+package calculator
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PackSetAnalysis describes how a set of pack sizes behaves
+type PackSetAnalysis struct {
+	PackSizes []int // Pack sizes analysed, largest first
+	GCD       int   // Greatest common divisor of the sizes, only its multiples can pack exactly
+
+	// Exactness of the sizes, independent of the options
+	LargestInexact int // Largest multiple of GCD no packing holds exactly, 0 if every multiple packs exactly
+	ExactThreshold int // Every multiple of GCD from here on packs exactly
+	InexactCount   int // Multiples of GCD that never pack exactly
+
+	// Packings of every order from 1 to Range items, calculated with the options
+	Range          int
+	ExactOrders    int         // Orders packed without excess or short items
+	Usage          map[int]int // Orders whose packing uses each size
+	UnusedSizes    []int       // Sizes no order of the range is packed with, largest first
+	MaxExcess      int         // Worst excess of an order
+	MaxExcessItems int         // Smallest order with the worst excess
+	MeanExcess     float64     // Average excess of an order
+}
+
+// DefaultAnalysisRange is the range AnalyzePackSizes calculates for a set of pack sizes when none is given:
+// up to a largest pack above the exact threshold, beyond which over-shipped orders keep less than GCD excess,
+// within what CalculateRange covers
+func DefaultAnalysisRange(exactThreshold int, packSizes []int) int {
+	largest := 0
+	for _, size := range packSizes {
+		largest = max(largest, size)
+	}
+	return min(exactThreshold+largest, safetyThreshold-largest)
+}
+
+// AnalyzePackSizes reports the exactness of a set of pack sizes and how the packings of every order from 1 to
+// rangeEnd items use them, 0 for DefaultAnalysisRange
+func AnalyzePackSizes(availablePackSizes []int, rangeEnd int, options Options) (PackSetAnalysis, error) {
+	packSizes, err := preparePackSizes(1, availablePackSizes)
+	if err != nil {
+		return PackSetAnalysis{}, err
+	}
+
+	analysis := PackSetAnalysis{PackSizes: packSizes, Usage: make(map[int]int, len(packSizes))}
+	for _, size := range packSizes {
+		analysis.GCD = gcd(analysis.GCD, size)
+		analysis.Usage[size] = 0
+	}
+	if smallest := packSizes[len(packSizes)-1] / analysis.GCD; smallest > safetyThreshold {
+		return PackSetAnalysis{}, fmt.Errorf("smallest pack size exceeds the safety threshold of %d multiples of %d",
+			safetyThreshold, analysis.GCD)
+	}
+	largestInexact, inexactCount := frobenius(packSizes, analysis.GCD)
+	analysis.LargestInexact = max(largestInexact, 0)
+	analysis.ExactThreshold = analysis.LargestInexact + analysis.GCD
+	analysis.InexactCount = inexactCount
+
+	if rangeEnd == 0 {
+		rangeEnd = DefaultAnalysisRange(analysis.ExactThreshold, packSizes)
+	}
+	if rangeEnd < 0 {
+		return PackSetAnalysis{}, fmt.Errorf("%w: range must not be negative, got %d", ErrInvalidRange, rangeEnd)
+	}
+	analysis.Range = rangeEnd
+
+	itemsOrdered := 1
+	totalExcess := 0
+	err = CalculateRange(1, rangeEnd, packSizes, options, func(result PackResult) error {
+		if result.ExcessItems == 0 && result.ShortItems == 0 {
+			analysis.ExactOrders++
+		}
+		for size, count := range result.PackCounts {
+			if count > 0 {
+				analysis.Usage[size]++
+			}
+		}
+		if result.ExcessItems > analysis.MaxExcess {
+			analysis.MaxExcess = result.ExcessItems
+			analysis.MaxExcessItems = itemsOrdered
+		}
+		totalExcess += result.ExcessItems
+		itemsOrdered++
+		return nil
+	})
+	if err != nil {
+		return PackSetAnalysis{}, err
+	}
+	analysis.MeanExcess = float64(totalExcess) / float64(rangeEnd)
+
+	for _, size := range packSizes {
+		if analysis.Usage[size] == 0 {
+			analysis.UnusedSizes = append(analysis.UnusedSizes, size)
+		}
+	}
+	return analysis, nil
+}
+
+// frobenius returns the largest multiple of divisor that no packing holds exactly, negative if there is none,
+// and how many multiples there are that none holds. It finds the smallest exact total of every remainder modulo
+// the smallest size with the round robin algorithm of Böcker and Lipták, one pass over the remainders per size;
+// every larger total of a remainder adds smallest packs to it.
+func frobenius(packSizes []int, divisor int) (int, int) {
+	sizes := make([]int, len(packSizes))
+	for i, size := range packSizes {
+		sizes[i] = size / divisor
+	}
+	sort.Ints(sizes)
+	smallest := sizes[0]
+
+	const none = -1
+	totals := make([]int, smallest)
+	for r := range totals {
+		totals[r] = none
+	}
+	totals[0] = 0
+
+	for _, size := range sizes[1:] {
+		d := gcd(smallest, size)
+		for class := range d {
+			// Start the cycle of the class at its smallest exact total
+			start := none
+			for r := class; r < smallest; r += d {
+				if totals[r] != none && (start == none || totals[r] < totals[start]) {
+					start = r
+				}
+			}
+			if start == none {
+				continue
+			}
+			total := totals[start]
+			for range smallest/d - 1 {
+				total += size
+				r := total % smallest
+				if totals[r] != none && totals[r] < total {
+					total = totals[r]
+				}
+				totals[r] = total
+			}
+		}
+	}
+
+	largest, count := none, 0
+	for _, total := range totals {
+		largest = max(largest, total-smallest)
+		count += total / smallest
+	}
+	return largest * divisor, count
+}
@@ -0,0 +1,100 @@
+package calculator
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestFrobenius(t *testing.T) {
+	testCases := []struct {
+		packSizes      []int
+		largestInexact int
+		exactThreshold int
+		inexactCount   int
+	}{
+		{packSizes: []int{250, 500, 1000, 2000, 5000}, largestInexact: 0, exactThreshold: 250, inexactCount: 0},
+		{packSizes: []int{6, 9, 20}, largestInexact: 43, exactThreshold: 44, inexactCount: 22},
+		{packSizes: []int{3, 5}, largestInexact: 7, exactThreshold: 8, inexactCount: 4},
+		{packSizes: []int{300, 500}, largestInexact: 700, exactThreshold: 800, inexactCount: 4},
+		{packSizes: []int{1}, largestInexact: 0, exactThreshold: 1, inexactCount: 0},
+	}
+
+	for _, tc := range testCases {
+		analysis, err := AnalyzePackSizes(tc.packSizes, 100, DefaultOptions())
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", tc.packSizes, err)
+		}
+		if analysis.LargestInexact != tc.largestInexact || analysis.ExactThreshold != tc.exactThreshold ||
+			analysis.InexactCount != tc.inexactCount {
+			t.Errorf("%v: expected largest inexact %d, threshold %d and %d inexact, got %d, %d and %d",
+				tc.packSizes, tc.largestInexact, tc.exactThreshold, tc.inexactCount,
+				analysis.LargestInexact, analysis.ExactThreshold, analysis.InexactCount)
+		}
+	}
+}
+
+func TestFrobeniusMatchesPacksTable(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	for range 200 {
+		packSizes := make([]int, 1+rng.Intn(4))
+		for i := range packSizes {
+			packSizes[i] = 1 + rng.Intn(60)
+		}
+		prepared, _ := preparePackSizes(1, packSizes)
+		divisor := 0
+		for _, size := range prepared {
+			divisor = gcd(divisor, size)
+		}
+
+		largest, count := frobenius(prepared, divisor)
+
+		limit := 60 * 60 * 2
+		packs := packsTable(limit, prepared)
+		expectedLargest, expectedCount := -1, 0
+		for total := divisor; total < limit; total += divisor {
+			if packs[total] == unreachable {
+				expectedLargest = total
+				expectedCount++
+			}
+		}
+		if largest != expectedLargest && !(largest < 0 && expectedLargest < 0) || count != expectedCount {
+			t.Errorf("%v: expected largest inexact %d and %d inexact, got %d and %d",
+				packSizes, expectedLargest, expectedCount, largest, count)
+		}
+	}
+}
+
+func TestAnalyzePackSizes(t *testing.T) {
+	analysis, err := AnalyzePackSizes([]int{250, 500, 1000, 2000, 5000}, 0, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if analysis.GCD != 250 || analysis.Range != 250+5000 {
+		t.Errorf("Expected GCD 250 and range 5250, got %d and %d", analysis.GCD, analysis.Range)
+	}
+	if analysis.MaxExcess != 249 || analysis.MaxExcessItems != 1 {
+		t.Errorf("Expected a worst excess of 249 items at 1 item, got %d at %d", analysis.MaxExcess, analysis.MaxExcessItems)
+	}
+	if analysis.ExactOrders != 21 || analysis.UnusedSizes != nil {
+		t.Errorf("Expected 21 exact orders and every size used, got %d and unused %v", analysis.ExactOrders, analysis.UnusedSizes)
+	}
+	if analysis.MeanExcess != 124.5 {
+		t.Errorf("Expected a mean excess of 124.5, got %v", analysis.MeanExcess)
+	}
+
+	// A size the limits rule out is never used
+	options := DefaultOptions()
+	options.Limits = map[int]PackLimit{2000: {Max: 0}}
+	analysis, err = AnalyzePackSizes([]int{250, 500, 1000, 2000, 5000}, 10000, options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(analysis.UnusedSizes, []int{2000}) {
+		t.Errorf("Expected 2000 to be unused, got %v", analysis.UnusedSizes)
+	}
+
+	if _, err := AnalyzePackSizes([]int{250}, -1, DefaultOptions()); err == nil {
+		t.Errorf("Expected error for a negative range")
+	}
+}
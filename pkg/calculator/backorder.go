@@ -0,0 +1,24 @@
+package calculator
+
+// CalculateFollowUp calculates the shipment that completes an under-shipped order.
+// The short items are packed over-shipping, so the two shipments together cover the order.
+// The minimum pack counts apply to the order, so the packs the first shipment already holds count
+// towards them; the other options, including the maximums, apply as they did to the first shipment.
+func CalculateFollowUp(shortItems int, shipped map[int]int, availablePackSizes []int, options Options) (PackResult, error) {
+	options.Fulfilment = FulfilmentOverShip
+	options.Limits = remainingMinimums(options.Limits, shipped)
+	return CalculatePacksWithOptions(shortItems, availablePackSizes, options)
+}
+
+// remainingMinimums returns the limits with the packs already shipped taken off their minimums
+func remainingMinimums(limits map[int]PackLimit, shipped map[int]int) map[int]PackLimit {
+	if len(limits) == 0 {
+		return limits
+	}
+	remaining := make(map[int]PackLimit, len(limits))
+	for size, limit := range limits {
+		limit.Min = max(limit.Min-shipped[size], 0)
+		remaining[size] = limit
+	}
+	return remaining
+}
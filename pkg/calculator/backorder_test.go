@@ -0,0 +1,143 @@
+package calculator
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestUnderShip(t *testing.T) {
+	standardPacks := []int{250, 500, 1000, 2000, 5000}
+
+	testCases := []struct {
+		name           string
+		itemsOrdered   int
+		packSizes      []int
+		limits         map[int]PackLimit
+		expectedCounts string
+		expectedShort  int
+		expectError    error
+	}{
+		{name: "Exact match", itemsOrdered: 750, packSizes: standardPacks, expectedCounts: "1x500 1x250"},
+		{name: "One item short", itemsOrdered: 501, packSizes: standardPacks, expectedCounts: "1x500", expectedShort: 1},
+		{name: "Smaller than every pack", itemsOrdered: 249, packSizes: standardPacks, expectedCounts: "", expectedShort: 249},
+		{name: "Large order", itemsOrdered: 12001, packSizes: standardPacks, expectedCounts: "2x5000 1x2000", expectedShort: 1},
+		{
+			name:           "Above the safety threshold",
+			itemsOrdered:   2000001,
+			packSizes:      []int{23, 31, 53},
+			expectedCounts: "37735x53 2x23",
+		},
+		{
+			name:         "Minimums above the order",
+			itemsOrdered: 400,
+			packSizes:    standardPacks,
+			limits:       map[int]PackLimit{500: {Min: 1, Max: Unlimited}},
+			expectError:  ErrInfeasible,
+		},
+		{
+			name:           "Maximums below the order",
+			itemsOrdered:   1001,
+			packSizes:      []int{250, 500},
+			limits:         map[int]PackLimit{250: {Min: 0, Max: 1}, 500: {Min: 0, Max: 1}},
+			expectedCounts: "1x500 1x250",
+			expectedShort:  251,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options := DefaultOptions()
+			options.Fulfilment = FulfilmentUnderShip
+			options.Limits = tc.limits
+
+			result, err := CalculatePacksWithOptions(tc.itemsOrdered, tc.packSizes, options)
+			if tc.expectError != nil {
+				if !errors.Is(err, tc.expectError) {
+					t.Errorf("Expected %v, got %v", tc.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got := formatPackCounts(result.PackCounts); got != tc.expectedCounts {
+				t.Errorf("Expected %s, got %s", tc.expectedCounts, got)
+			}
+			if result.ShortItems != tc.expectedShort || result.ExcessItems != 0 {
+				t.Errorf("Expected %d short and no excess items, got %d short and %d excess",
+					tc.expectedShort, result.ShortItems, result.ExcessItems)
+			}
+			if result.TotalItems+result.ShortItems != tc.itemsOrdered {
+				t.Errorf("Expected %d items shipped and short, got %d", tc.itemsOrdered, result.TotalItems+result.ShortItems)
+			}
+		})
+	}
+}
+
+func TestCalculateFollowUp(t *testing.T) {
+	standardPacks := []int{250, 500, 1000, 2000, 5000}
+
+	testCases := []struct {
+		name           string
+		limits         map[int]PackLimit
+		expectedFirst  string
+		expectedCounts string
+		expectedExcess int
+	}{
+		{name: "No limits", expectedFirst: "2x5000 1x2000", expectedCounts: "1x250", expectedExcess: 249},
+		{
+			name:           "Minimum met by the first shipment",
+			limits:         map[int]PackLimit{250: {Min: 2, Max: Unlimited}},
+			expectedFirst:  "2x5000 1x1000 1x500 2x250",
+			expectedCounts: "1x250",
+			expectedExcess: 249,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options := DefaultOptions()
+			options.Fulfilment = FulfilmentUnderShip
+			options.Limits = tc.limits
+
+			result, err := CalculatePacksWithOptions(12001, standardPacks, options)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := formatPackCounts(result.PackCounts); got != tc.expectedFirst {
+				t.Errorf("Expected a first shipment of %s, got %s", tc.expectedFirst, got)
+			}
+
+			followUp, err := CalculateFollowUp(result.ShortItems, result.PackCounts, standardPacks, options)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := formatPackCounts(followUp.PackCounts); got != tc.expectedCounts {
+				t.Errorf("Expected a follow-up of %s, got %s", tc.expectedCounts, got)
+			}
+			if followUp.ShortItems != 0 || followUp.ExcessItems != tc.expectedExcess {
+				t.Errorf("Expected the follow-up to complete the order with %d excess items, got %v", tc.expectedExcess, followUp)
+			}
+		})
+	}
+}
+
+func TestCalculatePacksBigUnderShip(t *testing.T) {
+	// 10^30
+	huge, _ := new(big.Int).SetString("1000000000000000000000000000000", 10)
+	options := DefaultOptions()
+	options.Fulfilment = FulfilmentUnderShip
+
+	result, err := CalculatePacksBig(new(big.Int).Add(huge, big.NewInt(1)), []int{250, 500, 1000, 2000, 5000}, options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TotalItems.Cmp(huge) != 0 {
+		t.Errorf("Expected %s items, got %s", huge, result.TotalItems)
+	}
+	if result.ShortItems.Cmp(big.NewInt(1)) != 0 || result.ExcessItems.Sign() != 0 {
+		t.Errorf("Expected 1 short and no excess items, got %s short and %s excess", result.ShortItems, result.ExcessItems)
+	}
+}
@@ -0,0 +1,170 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// ErrOverflow is returned when an order is too large to be calculated with int arithmetic
+var ErrOverflow = errors.New("order size overflows int, use CalculatePacksBig instead")
+
+// ErrFineGrained is returned for orders beyond int when optimal packings may hold more items in packs other than
+// the largest than the exact DP covers, so the order cannot be reduced exactly
+var ErrFineGrained = errors.New("pack sizes are too fine-grained for arbitrary-precision orders")
+
+// BigPackResult represents the result of a pack calculation with arbitrary-precision counts
+type BigPackResult struct {
+	PackCounts        map[int]*big.Int // Map of pack size to count
+	TotalPacks        *big.Int         // Total number of packs
+	TotalItems        *big.Int         // Total number of items
+	ExcessItems       *big.Int         // Number of excess items
+	ShortItems        *big.Int         // Number of items left to backorder when under-shipping
+	DistinctPackTypes int              // Number of different pack sizes used
+}
+
+// String returns a string representation of the pack result
+func (pr BigPackResult) String() string {
+	return fmt.Sprintf("Packs: %v, Total packs: %s, Total items: %s, Excess items: %s, Short items: %s, Distinct pack types: %d",
+		pr.PackCounts, pr.TotalPacks, pr.TotalItems, pr.ExcessItems, pr.ShortItems, pr.DistinctPackTypes)
+}
+
+// Big converts the pack result to its arbitrary-precision form
+func (pr PackResult) Big() BigPackResult {
+	packCounts := make(map[int]*big.Int, len(pr.PackCounts))
+	for size, count := range pr.PackCounts {
+		packCounts[size] = big.NewInt(int64(count))
+	}
+
+	return BigPackResult{
+		PackCounts:        packCounts,
+		TotalPacks:        big.NewInt(int64(pr.TotalPacks)),
+		TotalItems:        big.NewInt(int64(pr.TotalItems)),
+		ExcessItems:       big.NewInt(int64(pr.ExcessItems)),
+		ShortItems:        big.NewInt(int64(pr.ShortItems)),
+		DistinctPackTypes: pr.DistinctPackTypes,
+	}
+}
+
+// CalculatePacksBig calculates the optimal packs for orders of any size.
+// Orders that fit in an int are delegated to CalculatePacksWithOptions.
+// Larger orders are reduced: an optimal packing never holds more than
+// (largest/gcd - 1) packs smaller than the largest one, otherwise a subset of them
+// would sum to a multiple of the largest pack and could be swapped for fewer large packs.
+// So the bulk of the order is always shipped in largest packs and only the remainder
+// needs the exact DP.
+func CalculatePacksBig(itemsOrdered *big.Int, availablePackSizes []int, options Options) (BigPackResult, error) {
+	if itemsOrdered == nil || itemsOrdered.Sign() <= 0 {
+		return BigPackResult{}, fmt.Errorf("items ordered must be positive")
+	}
+
+	// Use the int algorithm while it is safe from overflow
+	if itemsOrdered.Cmp(big.NewInt(math.MaxInt)) <= 0 {
+		result, err := CalculatePacksWithOptions(int(itemsOrdered.Int64()), availablePackSizes, options)
+		if err == nil {
+			return result.Big(), nil
+		}
+		if !errors.Is(err, ErrOverflow) {
+			return BigPackResult{}, err
+		}
+	}
+
+	// Validate against a placeholder order, the order itself does not fit in an int
+	packSizes, err := preparePackSizes(1, availablePackSizes)
+	if err != nil {
+		return BigPackResult{}, err
+	}
+
+	if err := options.validate(); err != nil {
+		return BigPackResult{}, err
+	}
+
+	return reducePacks(itemsOrdered, packSizes, options)
+}
+
+// reducePacks sets aside the packs every optimal packing holds, including the bulk of largest packs,
+// and solves the remainder exactly. packSizes must be sorted in descending order.
+func reducePacks(itemsOrdered *big.Int, packSizes []int, options Options) (BigPackResult, error) {
+	p, err := newProblem(itemsOrdered, packSizes, options)
+	if err != nil {
+		return BigPackResult{}, err
+	}
+
+	// The remainder lands below the safety threshold, small enough for the exact DP
+	table := newSolver(p, options).solve(p.limit(options))
+	total := table.bestTotal(p, options)
+	if total == unreachable {
+		return BigPackResult{}, fmt.Errorf("%w: no total of at least %s items can be packed", ErrInfeasible, itemsOrdered)
+	}
+
+	result := BigPackResult{
+		PackCounts: make(map[int]*big.Int),
+		TotalPacks: new(big.Int),
+		TotalItems: new(big.Int),
+	}
+	add := func(size int, count *big.Int) {
+		if _, ok := result.PackCounts[size]; !ok {
+			result.PackCounts[size] = new(big.Int)
+		}
+		result.PackCounts[size].Add(result.PackCounts[size], count)
+		result.TotalPacks.Add(result.TotalPacks, count)
+		result.TotalItems.Add(result.TotalItems, new(big.Int).Mul(count, big.NewInt(int64(size))))
+	}
+
+	if p.bulk.Sign() > 0 {
+		add(packSizes[0], p.bulk)
+	}
+	for size, count := range p.fixed {
+		add(size, big.NewInt(int64(count)))
+	}
+	for size, count := range table.reconstruct(total, nil) {
+		add(size, big.NewInt(int64(count)))
+	}
+	result.ExcessItems = new(big.Int).Sub(result.TotalItems, itemsOrdered)
+	result.ShortItems = new(big.Int)
+	if result.ExcessItems.Sign() < 0 {
+		result.ShortItems.Neg(result.ExcessItems)
+		result.ExcessItems.SetInt64(0)
+	}
+	result.DistinctPackTypes = len(result.PackCounts)
+
+	return result, nil
+}
+
+// bulkBound returns the most items an optimal packing can hold in packs other than the largest.
+// packSizes must be sorted in descending order.
+func bulkBound(packSizes []int) (int, error) {
+	if len(packSizes) == 1 {
+		return 0, nil
+	}
+
+	largestPack := packSizes[0]
+	divisor := largestPack
+	for _, size := range packSizes[1:] {
+		divisor = gcd(divisor, size)
+	}
+
+	// At most (largest/gcd - 1) smaller packs, each at most the second largest size
+	smallerPacks := largestPack/divisor - 1
+	if smallerPacks > dpBound(largestPack)/packSizes[1] {
+		return 0, fmt.Errorf("%w: up to %d items may be packed in sizes other than %d, the exact DP covers %d",
+			ErrFineGrained, smallerPacks*packSizes[1], largestPack, dpBound(largestPack))
+	}
+
+	return smallerPacks * packSizes[1], nil
+}
+
+// dpBound returns the most items in packs other than the largest the exact DP on the remainder covers
+// within the safety threshold
+func dpBound(largestPack int) int {
+	return max(safetyThreshold-2*largestPack, 0)
+}
+
+// gcd returns the greatest common divisor of a and b
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,183 @@
+package calculator
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestCalculatePacksBig(t *testing.T) {
+	standardPacks := []int{250, 500, 1000, 2000, 5000}
+
+	// 10^30
+	huge, _ := new(big.Int).SetString("1000000000000000000000000000000", 10)
+
+	testCases := []struct {
+		name               string
+		itemsOrdered       *big.Int
+		availablePackSizes []int
+		expectedCounts     map[int]string
+		expectedPacks      string
+		expectedItems      string
+		expectedExcess     string
+		expectError        bool
+	}{
+		{
+			name:               "Small order delegates to int algorithm",
+			itemsOrdered:       big.NewInt(501),
+			availablePackSizes: standardPacks,
+			expectedCounts:     map[int]string{500: "1", 250: "1"},
+			expectedPacks:      "2",
+			expectedItems:      "750",
+			expectedExcess:     "249",
+		},
+		{
+			name:               "Order beyond int64",
+			itemsOrdered:       new(big.Int).Add(huge, big.NewInt(1)),
+			availablePackSizes: standardPacks,
+			expectedCounts:     map[int]string{5000: "200000000000000000000000000", 250: "1"},
+			expectedPacks:      "200000000000000000000000001",
+			expectedItems:      "1000000000000000000000000000250",
+			expectedExcess:     "249",
+		},
+		{
+			name:               "Order beyond int64 with custom pack sizes",
+			itemsOrdered:       new(big.Int).Add(huge, big.NewInt(7)),
+			availablePackSizes: []int{3, 7},
+			expectedCounts:     map[int]string{7: "142857142857142857142857142856", 3: "5"},
+			expectedPacks:      "142857142857142857142857142861",
+			expectedItems:      "1000000000000000000000000000007",
+			expectedExcess:     "0",
+		},
+		{
+			name:               "Zero items",
+			itemsOrdered:       big.NewInt(0),
+			availablePackSizes: standardPacks,
+			expectError:        true,
+		},
+		{
+			name:               "No pack sizes available",
+			itemsOrdered:       huge,
+			availablePackSizes: []int{},
+			expectError:        true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := CalculatePacksBig(tc.itemsOrdered, tc.availablePackSizes, DefaultOptions())
+
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if result.TotalPacks.String() != tc.expectedPacks {
+				t.Errorf("Expected %s total packs, got %s", tc.expectedPacks, result.TotalPacks)
+			}
+			if result.TotalItems.String() != tc.expectedItems {
+				t.Errorf("Expected %s total items, got %s", tc.expectedItems, result.TotalItems)
+			}
+			if result.ExcessItems.String() != tc.expectedExcess {
+				t.Errorf("Expected %s excess items, got %s", tc.expectedExcess, result.ExcessItems)
+			}
+
+			if len(result.PackCounts) != len(tc.expectedCounts) {
+				t.Errorf("Expected %d different pack sizes, got %d", len(tc.expectedCounts), len(result.PackCounts))
+			}
+			for size, count := range tc.expectedCounts {
+				if got := result.PackCounts[size]; got == nil || got.String() != count {
+					t.Errorf("Expected %s packs of size %d, got %v", count, size, got)
+				}
+			}
+		})
+	}
+}
+
+// TestReducePacksMatchesExactDP checks the largest pack reduction against the full DP
+// in both fulfilment modes
+func TestReducePacksMatchesExactDP(t *testing.T) {
+	packSets := [][]int{
+		{5000, 2000, 1000, 500, 250},
+		{53, 31, 23},
+		{7, 3},
+	}
+
+	for _, packSizes := range packSets {
+		full := problem{packSizes: packSizes, bulk: new(big.Int), caps: make([]int, len(packSizes))}
+		for i := range full.caps {
+			full.caps[i] = Unlimited
+		}
+		table := newSolver(full, DefaultOptions()).solve(50000 + packSizes[0])
+
+		for _, fulfilment := range Fulfilments {
+			options := DefaultOptions()
+			options.Fulfilment = fulfilment
+
+			for itemsOrdered := 1; itemsOrdered <= 50000; itemsOrdered += 499 {
+				items := itemsOrdered
+				if options.underShip() {
+					items -= packSizes[0]
+				}
+				bulk, err := bulkPacks(big.NewInt(int64(items)), packSizes)
+				if err != nil {
+					t.Fatalf("Unexpected error for %d items with packs %v: %v", itemsOrdered, packSizes, err)
+				}
+
+				reduced := full
+				reduced.bulk = bulk
+				reduced.target = itemsOrdered - int(bulk.Int64())*packSizes[0]
+				reducedTable := newSolver(reduced, options).solve(reduced.limit(options))
+				reducedTotal := reducedTable.bestTotal(reduced, options)
+				reducedItems := reducedTotal + int(bulk.Int64())*packSizes[0]
+				reducedPacks := reducedTable.costs[reducedTotal].packs + int(bulk.Int64())
+
+				exact := full
+				exact.target = itemsOrdered
+				total := table.bestTotal(exact, options)
+				if reducedItems != total || reducedPacks != table.costs[total].packs {
+					t.Errorf("%s, packs %v, %d items: reduction gave %d items in %d packs, exact DP gave %d items in %d packs",
+						fulfilment, packSizes, itemsOrdered, reducedItems, reducedPacks, total, table.costs[total].packs)
+				}
+			}
+		}
+	}
+}
+
+// TestFineGrainedPackSizes checks that int orders are still calculated when the pack sizes are too fine-grained
+// to bound the bulk, and that orders beyond int fail with ErrFineGrained
+func TestFineGrainedPackSizes(t *testing.T) {
+	packSizes := []int{5000, 4999}
+
+	testCases := []struct {
+		itemsOrdered   int
+		expectedCounts map[int]int
+	}{
+		{999999, map[int]int{5000: 199, 4999: 1}},
+		{2000000, map[int]int{5000: 400}},
+	}
+
+	for _, tc := range testCases {
+		result, err := CalculatePacksWithOptions(tc.itemsOrdered, packSizes, DefaultOptions())
+		if err != nil {
+			t.Fatalf("Unexpected error for %d items: %v", tc.itemsOrdered, err)
+		}
+		if !reflect.DeepEqual(result.PackCounts, tc.expectedCounts) {
+			t.Errorf("Expected %v for %d items, got %v", tc.expectedCounts, tc.itemsOrdered, result.PackCounts)
+		}
+		if result.ExcessItems != 0 {
+			t.Errorf("Expected no excess items for %d items, got %d", tc.itemsOrdered, result.ExcessItems)
+		}
+	}
+
+	huge, _ := new(big.Int).SetString("1000000000000000000000000000000", 10)
+	if _, err := CalculatePacksBig(huge, packSizes, DefaultOptions()); !errors.Is(err, ErrFineGrained) {
+		t.Errorf("Expected ErrFineGrained beyond int, got %v", err)
+	}
+}
@@ -2,21 +2,29 @@ package calculator
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 	"sort"
 )
 
+// Safety threshold to prevent memory issues with extremely large values
+// This is a reasonable upper limit for the DP approach
+const safetyThreshold int = 1000000
+
 // PackResult represents the result of a pack calculation
 type PackResult struct {
-	PackCounts  map[int]int // Map of pack size to count
-	TotalPacks  int         // Total number of packs
-	TotalItems  int         // Total number of items
-	ExcessItems int         // Number of excess items
+	PackCounts        map[int]int // Map of pack size to count
+	TotalPacks        int         // Total number of packs
+	TotalItems        int         // Total number of items
+	ExcessItems       int         // Number of excess items
+	ShortItems        int         // Number of items left to backorder when under-shipping
+	DistinctPackTypes int         // Number of different pack sizes used
 }
 
 // String returns a string representation of the pack result
 func (pr PackResult) String() string {
-	return fmt.Sprintf("Packs: %v, Total packs: %d, Total items: %d, Excess items: %d",
-		pr.PackCounts, pr.TotalPacks, pr.TotalItems, pr.ExcessItems)
+	return fmt.Sprintf("Packs: %v, Total packs: %d, Total items: %d, Excess items: %d, Short items: %d, Distinct pack types: %d",
+		pr.PackCounts, pr.TotalPacks, pr.TotalItems, pr.ExcessItems, pr.ShortItems, pr.DistinctPackTypes)
 }
 
 // OptimalCalculatePacks chooses between CalculatePacks and CalculatePacksOptimized
@@ -35,10 +43,6 @@ func OptimalCalculatePacks(itemsOrdered int, availablePackSizes []int) (PackResu
 		threshold = 1000
 	}
 
-	// Safety threshold to prevent memory issues with extremely large values
-	// This is a reasonable upper limit for the DP approach
-	var safetyThreshold int = 1000000
-
 	// Choose the appropriate algorithm based on order size
 	if itemsOrdered <= threshold && itemsOrdered <= safetyThreshold {
 		return CalculatePacks(itemsOrdered, availablePackSizes)
@@ -61,8 +65,6 @@ func CalculatePacks(itemsOrdered int, availablePackSizes []int) (PackResult, err
 	}
 
 	// Safety check to prevent memory issues with extremely large values
-	// This is a reasonable upper limit for the DP approach
-	var safetyThreshold int = 1000000
 	if itemsOrdered > safetyThreshold {
 		return PackResult{}, fmt.Errorf("order size too large for this algorithm, use CalculatePacksOptimized instead")
 	}
@@ -76,9 +78,10 @@ func CalculatePacks(itemsOrdered int, availablePackSizes []int) (PackResult, err
 	minItems, packsUsed := findMinimumItems(itemsOrdered, availablePackSizes)
 
 	result := PackResult{
-		PackCounts:  packsUsed,
-		TotalItems:  minItems,
-		ExcessItems: minItems - itemsOrdered,
+		PackCounts:        packsUsed,
+		TotalItems:        minItems,
+		ExcessItems:       minItems - itemsOrdered,
+		DistinctPackTypes: distinctSizes(packsUsed),
 	}
 
 	// Calculate total number of packs
@@ -109,6 +112,11 @@ func CalculatePacksOptimized(itemsOrdered int, availablePackSizes []int) (PackRe
 
 	smallestPack := availablePackSizes[len(availablePackSizes)-1]
 
+	// The DP below may overshoot the order by up to dpLimit items, make sure the totals still fit in an int
+	if overflows(itemsOrdered, availablePackSizes[0], smallestPack*10) {
+		return PackResult{}, ErrOverflow
+	}
+
 	// First use greedy approach for the bulk of the order
 	packCounts := make(map[int]int)
 	remaining := itemsOrdered
@@ -177,10 +185,11 @@ func CalculatePacksOptimized(itemsOrdered int, availablePackSizes []int) (PackRe
 	}
 
 	return PackResult{
-		PackCounts:  packCounts,
-		TotalPacks:  totalPacks,
-		TotalItems:  totalItems,
-		ExcessItems: totalItems - itemsOrdered,
+		PackCounts:        packCounts,
+		TotalPacks:        totalPacks,
+		TotalItems:        totalItems,
+		ExcessItems:       totalItems - itemsOrdered,
+		DistinctPackTypes: distinctSizes(packCounts),
 	}, nil
 }
 
@@ -270,3 +279,12 @@ func findMinimumItems(target int, packSizes []int) (int, map[int]int) {
 
 	return totalItems, packCounts
 }
+
+// overflows reports whether itemsOrdered plus the headroom needed by the algorithms
+// (largest pack and DP window) no longer fits in an int
+func overflows(itemsOrdered int, largestPack int, dpLimit int) bool {
+	total := big.NewInt(int64(itemsOrdered))
+	total.Add(total, big.NewInt(int64(largestPack)))
+	total.Add(total, big.NewInt(int64(dpLimit)))
+	return total.Cmp(big.NewInt(math.MaxInt)) > 0
+}
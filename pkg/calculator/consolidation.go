@@ -0,0 +1,121 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Order is one of several orders that may ship together
+type Order struct {
+	ID    string // Identifies the order in the groups, required and unique
+	Key   string // Consolidation key, such as a customer and a ship date, orders with the same key may ship together
+	Items int
+}
+
+// OrderGroup compares shipping the orders with one consolidation key separately and together
+type OrderGroup struct {
+	Key          string
+	Orders       []string     // IDs of the orders, in the order given
+	ItemsOrdered int          // Items of all orders
+	Separate     []PackResult // One per order, nil if an order cannot be packed on its own
+	Combined     PackResult   // All orders packed as one, zero if they cannot be
+	Consolidate  bool         // Whether shipping the orders together is better by the business rules
+	ExcessSaved  int          // Excess items saved by consolidating, 0 if not recommended, negative if the tolerance trades them for packs
+	PacksSaved   int          // Packs saved by consolidating, 0 if not recommended
+}
+
+// Consolidation is the recommended grouping of a set of orders
+type Consolidation struct {
+	Groups      []OrderGroup // In the order their keys first appear
+	ExcessSaved int          // Excess items saved over all groups
+	PacksSaved  int          // Packs saved over all groups
+}
+
+// ConsolidateOrders groups orders by consolidation key and recommends shipping a group together when its
+// combined packing is better by the business rules than the packings of its orders added up. The packings of
+// any split of a group add up to a packing of the whole group, so a group ships either whole or per order.
+// Pack limits apply to each shipment.
+func ConsolidateOrders(orders []Order, packSizes []int, options Options) (Consolidation, error) {
+	var consolidation Consolidation
+	groups := make(map[string]int)
+	seen := make(map[string]bool, len(orders))
+	for _, order := range orders {
+		if order.ID == "" {
+			return Consolidation{}, fmt.Errorf("an order of %d items has no ID", order.Items)
+		}
+		if order.Items <= 0 {
+			return Consolidation{}, fmt.Errorf("items of order %q must be greater than 0, got %d", order.ID, order.Items)
+		}
+		if seen[order.ID] {
+			return Consolidation{}, fmt.Errorf("order %q is listed more than once", order.ID)
+		}
+		seen[order.ID] = true
+
+		i, ok := groups[order.Key]
+		if !ok {
+			i = len(consolidation.Groups)
+			groups[order.Key] = i
+			consolidation.Groups = append(consolidation.Groups, OrderGroup{Key: order.Key})
+		}
+		consolidation.Groups[i].Orders = append(consolidation.Groups[i].Orders, order.ID)
+		consolidation.Groups[i].ItemsOrdered += order.Items
+	}
+
+	items := make(map[string]int, len(orders))
+	for _, order := range orders {
+		items[order.ID] = order.Items
+	}
+	for i := range consolidation.Groups {
+		group := &consolidation.Groups[i]
+		if err := compareGroup(group, items, packSizes, options); err != nil {
+			return Consolidation{}, err
+		}
+		consolidation.ExcessSaved += group.ExcessSaved
+		consolidation.PacksSaved += group.PacksSaved
+	}
+	return consolidation, nil
+}
+
+// compareGroup packs the orders of a group separately and together and recommends the better one
+func compareGroup(group *OrderGroup, items map[string]int, packSizes []int, options Options) error {
+	combined, combinedErr := CalculatePacksWithOptions(group.ItemsOrdered, packSizes, options)
+	if combinedErr != nil && !errors.Is(combinedErr, ErrInfeasible) {
+		return combinedErr
+	}
+
+	packCounts := make(map[int]int)
+	var separateErr error
+	for _, id := range group.Orders {
+		result, err := CalculatePacksWithOptions(items[id], packSizes, options)
+		if err != nil {
+			if !errors.Is(err, ErrInfeasible) {
+				return err
+			}
+			group.Separate, separateErr = nil, fmt.Errorf("order %q: %w", id, err)
+			break
+		}
+		group.Separate = append(group.Separate, result)
+		for size, count := range result.PackCounts {
+			packCounts[size] += count
+		}
+	}
+
+	switch {
+	case combinedErr != nil && separateErr != nil:
+		return fmt.Errorf("orders with key %q cannot be packed together or apart: %w", group.Key, combinedErr)
+	case combinedErr != nil:
+		return nil
+	case separateErr != nil:
+		group.Combined, group.Consolidate = combined, true
+		return nil
+	}
+
+	group.Combined = combined
+	separate := newPackResult(group.ItemsOrdered, packCounts)
+	if len(group.Orders) > 1 && options.Less(combined, separate) {
+		group.Consolidate = true
+		group.ExcessSaved = separate.ExcessItems - combined.ExcessItems
+		group.PacksSaved = separate.TotalPacks - combined.TotalPacks
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package calculator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestConsolidateOrders(t *testing.T) {
+	packSizes := []int{250, 500, 1000, 2000, 5000}
+
+	testCases := []struct {
+		name                string
+		orders              []Order
+		expectedKeys        []string
+		expectedCombined    []string
+		expectedConsolidate []bool
+		expectedExcessSaved int
+		expectedPacksSaved  int
+	}{
+		{
+			name: "Small orders ship together",
+			orders: []Order{
+				{ID: "a", Key: "alice", Items: 1},
+				{ID: "b", Key: "alice", Items: 1},
+				{ID: "c", Key: "alice", Items: 1},
+			},
+			expectedKeys:        []string{"alice"},
+			expectedCombined:    []string{"1x250"},
+			expectedConsolidate: []bool{true},
+			expectedExcessSaved: 3*249 - 247,
+			expectedPacksSaved:  2,
+		},
+		{
+			name: "Fewer packs together",
+			orders: []Order{
+				{ID: "a", Key: "alice", Items: 500},
+				{ID: "b", Key: "alice", Items: 500},
+			},
+			expectedKeys:        []string{"alice"},
+			expectedCombined:    []string{"1x1000"},
+			expectedConsolidate: []bool{true},
+			expectedPacksSaved:  1,
+		},
+		{
+			name: "Nothing to gain together",
+			orders: []Order{
+				{ID: "a", Key: "alice", Items: 250},
+				{ID: "b", Key: "alice", Items: 500},
+			},
+			expectedKeys:        []string{"alice"},
+			expectedCombined:    []string{"1x500 1x250"},
+			expectedConsolidate: []bool{false},
+		},
+		{
+			name: "Groups by key in order of appearance",
+			orders: []Order{
+				{ID: "a", Key: "bob", Items: 1},
+				{ID: "b", Key: "alice", Items: 1},
+				{ID: "c", Key: "bob", Items: 1},
+			},
+			expectedKeys:        []string{"bob", "alice"},
+			expectedCombined:    []string{"1x250", "1x250"},
+			expectedConsolidate: []bool{true, false},
+			expectedExcessSaved: 2*249 - 248,
+			expectedPacksSaved:  1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			consolidation, err := ConsolidateOrders(tc.orders, packSizes, DefaultOptions())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(consolidation.Groups) != len(tc.expectedKeys) {
+				t.Fatalf("Expected groups %v, got %+v", tc.expectedKeys, consolidation.Groups)
+			}
+			for i, group := range consolidation.Groups {
+				if group.Key != tc.expectedKeys[i] {
+					t.Errorf("Expected group %d to be %s, got %s", i, tc.expectedKeys[i], group.Key)
+				}
+				if got := formatPackCounts(group.Combined.PackCounts); got != tc.expectedCombined[i] {
+					t.Errorf("Expected %s combined to be %s, got %s", group.Key, tc.expectedCombined[i], got)
+				}
+				if group.Consolidate != tc.expectedConsolidate[i] {
+					t.Errorf("Expected %s consolidate %v, got %v", group.Key, tc.expectedConsolidate[i], group.Consolidate)
+				}
+				if len(group.Separate) != len(group.Orders) {
+					t.Errorf("Expected a separate packing per order of %s, got %d", group.Key, len(group.Separate))
+				}
+			}
+			if consolidation.ExcessSaved != tc.expectedExcessSaved || consolidation.PacksSaved != tc.expectedPacksSaved {
+				t.Errorf("Expected to save %d excess items and %d packs, got %d and %d",
+					tc.expectedExcessSaved, tc.expectedPacksSaved, consolidation.ExcessSaved, consolidation.PacksSaved)
+			}
+		})
+	}
+}
+
+func TestConsolidateOrdersWithLimits(t *testing.T) {
+	options := DefaultOptions()
+	options.Limits = map[int]PackLimit{500: {Min: 0, Max: 1}}
+	orders := []Order{{ID: "a", Key: "alice", Items: 500}, {ID: "b", Key: "alice", Items: 500}}
+
+	// 2x500 exceeds the limit in one shipment, 1x500 each does not
+	consolidation, err := ConsolidateOrders(orders, []int{500}, options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if group := consolidation.Groups[0]; group.Consolidate || len(group.Separate) != 2 {
+		t.Errorf("Expected separate shipments, got %+v", group)
+	}
+
+	// 2x500 is required in every shipment
+	options.Limits = map[int]PackLimit{500: {Min: 2, Max: Unlimited}}
+	consolidation, err = ConsolidateOrders(orders, []int{500}, options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if group := consolidation.Groups[0]; !group.Consolidate || group.ExcessSaved != 1000 || group.PacksSaved != 2 {
+		t.Errorf("Expected a combined shipment saving 1000 items and 2 packs, got %+v", group)
+	}
+
+	options.Limits = map[int]PackLimit{500: {Min: 0, Max: 0}}
+	if _, err := ConsolidateOrders(orders, []int{500}, options); !errors.Is(err, ErrInfeasible) {
+		t.Errorf("Expected %v, got %v", ErrInfeasible, err)
+	}
+}
+
+func TestConsolidateOrdersInvalid(t *testing.T) {
+	if _, err := ConsolidateOrders([]Order{{ID: "a", Items: 0}}, []int{250}, DefaultOptions()); err == nil {
+		t.Errorf("Expected error for an empty order")
+	}
+	if _, err := ConsolidateOrders([]Order{{ID: "a", Items: 1}, {ID: "a", Items: 1}}, []int{250}, DefaultOptions()); err == nil {
+		t.Errorf("Expected error for a duplicate order")
+	}
+	_, err := ConsolidateOrders([]Order{{Items: 1}, {Items: 1}}, []int{250}, DefaultOptions())
+	if err == nil || !strings.Contains(err.Error(), "no ID") {
+		t.Errorf("Expected error for orders without an ID, got %v", err)
+	}
+}
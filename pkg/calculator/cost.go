@@ -0,0 +1,204 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoRate is returned when a rate card has no band for the weight of a parcel
+var ErrNoRate = errors.New("no rate for parcel")
+
+// maxCostCandidates caps the packings CalculateCheapest prices
+const maxCostCandidates = 200
+
+// Objective is what a shipment plan minimises
+type Objective string
+
+const (
+	// ObjectiveFewestPacks ships the packing the business rules pick
+	ObjectiveFewestPacks Objective = "fewest-packs"
+	// ObjectiveCheapest ships the packing with the lowest packaging plus shipping cost among those that
+	// respect rule 2 within the tolerance, rule 3 only decides between equally cheap packings
+	ObjectiveCheapest Objective = "cheapest"
+)
+
+// Objectives lists the supported shipment objectives
+var Objectives = []Objective{ObjectiveFewestPacks, ObjectiveCheapest}
+
+// ParseObjective parses a shipment objective name, an empty name is the fewest packs objective
+func ParseObjective(name string) (Objective, error) {
+	if name == "" {
+		return ObjectiveFewestPacks, nil
+	}
+
+	for _, objective := range Objectives {
+		if string(objective) == name {
+			return objective, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown objective %q, expected one of %v", name, Objectives)
+}
+
+// RateBand is the price of a parcel weighing up to MaxWeight
+type RateBand struct {
+	MaxWeight int // Grams, inclusive
+	Price     int // Cents per parcel
+}
+
+// RateCard is what a carrier charges per parcel: a fee plus the price of the parcel's weight band
+type RateCard struct {
+	ParcelFee int        // Cents per parcel
+	Bands     []RateBand // Ascending by MaxWeight
+}
+
+// IsZero reports whether the rate card has no bands, so shipping cannot be priced
+func (r RateCard) IsZero() bool {
+	return len(r.Bands) == 0
+}
+
+// Validate checks that the fee and prices are not negative and the bands ascend by weight
+func (r RateCard) Validate() error {
+	if r.ParcelFee < 0 {
+		return fmt.Errorf("parcel fee must not be negative, got %d", r.ParcelFee)
+	}
+	for i, band := range r.Bands {
+		if band.MaxWeight <= 0 {
+			return fmt.Errorf("weight band %d must have a positive maximum weight, got %d", i+1, band.MaxWeight)
+		}
+		if band.Price < 0 {
+			return fmt.Errorf("price of weight band %d must not be negative, got %d", i+1, band.Price)
+		}
+		if i > 0 && band.MaxWeight <= r.Bands[i-1].MaxWeight {
+			return fmt.Errorf("weight bands must ascend by maximum weight, %d follows %d", band.MaxWeight, r.Bands[i-1].MaxWeight)
+		}
+	}
+	return nil
+}
+
+// price returns the price of the lightest band a parcel fits in
+func (r RateCard) price(weight int) (int, error) {
+	for _, band := range r.Bands {
+		if weight <= band.MaxWeight {
+			return band.Price, nil
+		}
+	}
+	return 0, fmt.Errorf("%w weighing %d g", ErrNoRate, weight)
+}
+
+// ParcelCost is the cost of each parcel of a group
+type ParcelCost struct {
+	Count  int // Number of identical parcels in the group
+	Weight int // Grams in each parcel
+	Fee    int // Cents per parcel
+	Price  int // Cents for the weight band of each parcel
+}
+
+// CostBreakdown is what a shipment costs to pack and ship, in cents
+type CostBreakdown struct {
+	Packaging int          // Cost of the packs
+	Shipping  int          // Parcel fees and band prices of all parcels
+	Total     int          // Packaging plus shipping
+	Parcels   []ParcelCost // Shipping cost per group of parcels
+}
+
+// Cost prices the packs and parcels of a shipment
+func (r RateCard) Cost(packCounts map[int]int, specs map[int]PackSpec, plan ParcelPlan) (CostBreakdown, error) {
+	var cost CostBreakdown
+	for size, count := range packCounts {
+		cost.Packaging += specs[size].Cost * count
+	}
+	for _, parcel := range plan.Parcels {
+		price, err := r.price(parcel.Weight)
+		if err != nil {
+			return CostBreakdown{}, err
+		}
+		cost.Parcels = append(cost.Parcels, ParcelCost{Count: parcel.Count, Weight: parcel.Weight, Fee: r.ParcelFee, Price: price})
+		cost.Shipping += (r.ParcelFee + price) * parcel.Count
+	}
+	cost.Total = cost.Packaging + cost.Shipping
+	return cost, nil
+}
+
+// Shipment is a packing, the parcels it ships in and what that costs
+type Shipment struct {
+	PackResult
+	Plan ParcelPlan
+	Cost CostBreakdown
+}
+
+// CheapestShipment is the cheapest shipment of an order and the one the business rules pick
+type CheapestShipment struct {
+	Cheapest   Shipment // Lowest total cost, the first in ranking order among equally cheap ones
+	Fewest     Shipment // The packing CalculatePacksWithOptions returns, for comparison, unpriced if it cannot ship
+	Candidates int      // Packings priced
+	Truncated  bool     // Whether candidates were capped at maxCostCandidates
+}
+
+// CalculateCheapest picks the packing with the lowest packaging plus shipping cost among the packings that
+// respect rule 2: the rule 2 total, or any total within the excess tolerance. Every such packing is priced,
+// whatever its number of packs, up to maxCostCandidates in ranking order. Packings whose parcels the rate
+// card cannot price are skipped.
+func CalculateCheapest(itemsOrdered int, packSizes []int, options Options, specs map[int]PackSpec, limits ParcelLimits, rates RateCard) (CheapestShipment, error) {
+	if err := rates.Validate(); err != nil {
+		return CheapestShipment{}, err
+	}
+	if rates.IsZero() {
+		return CheapestShipment{}, fmt.Errorf("%w: the rate card has no weight bands", ErrNoRate)
+	}
+
+	alternatives, err := CalculateAlternatives(itemsOrdered, packSizes, maxCostCandidates, options)
+	if err != nil {
+		return CheapestShipment{}, err
+	}
+
+	packings := append(alternatives.Optimal, alternatives.NextBest...)
+	rule2Items := packings[0].TotalItems
+
+	cheapest := CheapestShipment{Fewest: Shipment{PackResult: packings[0]}, Truncated: alternatives.Truncated}
+	found := false
+	var lastErr error
+	for i, packing := range packings {
+		// Ranking puts every packing that respects rule 2 first
+		if packing.TotalItems != rule2Items && !options.withinTolerance(packing) {
+			break
+		}
+		if cheapest.Candidates == maxCostCandidates {
+			cheapest.Truncated = true
+			break
+		}
+		cheapest.Candidates++
+
+		shipment, err := priceShipment(packing, specs, limits, rates)
+		if err != nil {
+			if errors.Is(err, ErrNoRate) || errors.Is(err, ErrPackTooLarge) {
+				lastErr = err
+				continue
+			}
+			return CheapestShipment{}, err
+		}
+		if i == 0 {
+			cheapest.Fewest = shipment
+		}
+		if !found || shipment.Cost.Total < cheapest.Cheapest.Cost.Total {
+			cheapest.Cheapest, found = shipment, true
+		}
+	}
+	if !found {
+		return CheapestShipment{}, lastErr
+	}
+	return cheapest, nil
+}
+
+// priceShipment plans the parcels of a packing and prices them
+func priceShipment(packing PackResult, specs map[int]PackSpec, limits ParcelLimits, rates RateCard) (Shipment, error) {
+	plan, err := PlanParcels(packing.PackCounts, specs, limits)
+	if err != nil {
+		return Shipment{}, err
+	}
+	cost, err := rates.Cost(packing.PackCounts, specs, plan)
+	if err != nil {
+		return Shipment{}, err
+	}
+	return Shipment{PackResult: packing, Plan: plan, Cost: cost}, nil
+}
@@ -0,0 +1,184 @@
+package calculator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseObjective(t *testing.T) {
+	for _, objective := range Objectives {
+		if parsed, err := ParseObjective(string(objective)); err != nil || parsed != objective {
+			t.Errorf("Expected %s, got %s (%v)", objective, parsed, err)
+		}
+	}
+	if parsed, err := ParseObjective(""); err != nil || parsed != ObjectiveFewestPacks {
+		t.Errorf("Expected %s for an empty name, got %s (%v)", ObjectiveFewestPacks, parsed, err)
+	}
+	if _, err := ParseObjective("fastest"); err == nil {
+		t.Errorf("Expected error for an unknown objective")
+	}
+}
+
+func TestRateCardValidate(t *testing.T) {
+	testCases := []struct {
+		name  string
+		rates RateCard
+		valid bool
+	}{
+		{name: "Valid", rates: RateCard{ParcelFee: 50, Bands: []RateBand{{MaxWeight: 1000, Price: 300}, {MaxWeight: 5000, Price: 700}}}, valid: true},
+		{name: "Negative fee", rates: RateCard{ParcelFee: -1}},
+		{name: "Negative price", rates: RateCard{Bands: []RateBand{{MaxWeight: 1000, Price: -1}}}},
+		{name: "Zero weight", rates: RateCard{Bands: []RateBand{{MaxWeight: 0, Price: 100}}}},
+		{name: "Descending bands", rates: RateCard{Bands: []RateBand{{MaxWeight: 5000, Price: 700}, {MaxWeight: 1000, Price: 300}}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.rates.Validate(); (err == nil) != tc.valid {
+				t.Errorf("Expected valid %v, got %v", tc.valid, err)
+			}
+		})
+	}
+}
+
+func TestCalculateCheapest(t *testing.T) {
+	specs := map[int]PackSpec{
+		500: {Weight: 4000, Cost: 50},
+		250: {Weight: 1000, Cost: 20},
+	}
+	rates := RateCard{ParcelFee: 10, Bands: []RateBand{{MaxWeight: 2000, Price: 300}, {MaxWeight: 5000, Price: 1000}}}
+
+	testCases := []struct {
+		name              string
+		itemsOrdered      int
+		tolerance         Tolerance
+		limits            ParcelLimits
+		expectedCheapest  string
+		expectedFewest    string
+		expectedCost      int
+		expectedCandidate int
+	}{
+		{
+			name:              "More packs ship cheaper",
+			itemsOrdered:      500,
+			expectedCheapest:  "2x250",
+			expectedFewest:    "1x500",
+			expectedCost:      2*20 + 10 + 300,
+			expectedCandidate: 2,
+		},
+		{
+			name:              "Fewest packs are cheapest",
+			itemsOrdered:      250,
+			expectedCheapest:  "1x250",
+			expectedFewest:    "1x250",
+			expectedCost:      20 + 10 + 300,
+			expectedCandidate: 1,
+		},
+		{
+			name:              "Fewest packs too heavy for a parcel",
+			itemsOrdered:      1000,
+			limits:            ParcelLimits{MaxWeight: 2000},
+			expectedCheapest:  "4x250",
+			expectedFewest:    "2x500",
+			expectedCost:      4*20 + 2*(10+300),
+			expectedCandidate: 3,
+		},
+		{
+			name:              "Tolerance widens the candidates",
+			itemsOrdered:      260,
+			tolerance:         Tolerance{Items: 240},
+			expectedCheapest:  "2x250",
+			expectedFewest:    "1x500",
+			expectedCost:      2*20 + 10 + 300,
+			expectedCandidate: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options := DefaultOptions()
+			options.Tolerance = tc.tolerance
+
+			cheapest, err := CalculateCheapest(tc.itemsOrdered, []int{250, 500}, options, specs, tc.limits, rates)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got := formatPackCounts(cheapest.Cheapest.PackCounts); got != tc.expectedCheapest {
+				t.Errorf("Expected cheapest %s, got %s", tc.expectedCheapest, got)
+			}
+			if got := formatPackCounts(cheapest.Fewest.PackCounts); got != tc.expectedFewest {
+				t.Errorf("Expected fewest %s, got %s", tc.expectedFewest, got)
+			}
+			if cheapest.Cheapest.Cost.Total != tc.expectedCost {
+				t.Errorf("Expected cost %d, got %+v", tc.expectedCost, cheapest.Cheapest.Cost)
+			}
+			if cost := cheapest.Cheapest.Cost; cost.Packaging+cost.Shipping != cost.Total {
+				t.Errorf("Expected packaging and shipping to add up to the total, got %+v", cost)
+			}
+			if cheapest.Candidates != tc.expectedCandidate {
+				t.Errorf("Expected %d candidates, got %d", tc.expectedCandidate, cheapest.Candidates)
+			}
+		})
+	}
+}
+
+func TestCalculateCheapestWithoutRate(t *testing.T) {
+	specs := map[int]PackSpec{500: {Weight: 4000}, 250: {Weight: 1000}}
+
+	_, err := CalculateCheapest(500, []int{250, 500}, DefaultOptions(), specs, ParcelLimits{}, RateCard{})
+	if !errors.Is(err, ErrNoRate) {
+		t.Errorf("Expected %v for an empty rate card, got %v", ErrNoRate, err)
+	}
+
+	rates := RateCard{Bands: []RateBand{{MaxWeight: 1000, Price: 300}}}
+	_, err = CalculateCheapest(500, []int{250, 500}, DefaultOptions(), specs, ParcelLimits{}, rates)
+	if !errors.Is(err, ErrNoRate) {
+		t.Errorf("Expected %v for parcels heavier than every band, got %v", ErrNoRate, err)
+	}
+}
+
+// TestCalculateCheapestMatchesBruteForce checks the cheapest shipment against pricing every packing of the rule 2 total
+func TestCalculateCheapestMatchesBruteForce(t *testing.T) {
+	packSizes := []int{7, 5, 3}
+	specs := map[int]PackSpec{7: {Weight: 700, Cost: 9}, 5: {Weight: 450, Cost: 4}, 3: {Weight: 200, Cost: 5}}
+	limits := ParcelLimits{MaxWeight: 1500}
+	rates := RateCard{ParcelFee: 25, Bands: []RateBand{{MaxWeight: 500, Price: 40}, {MaxWeight: 1000, Price: 70}, {MaxWeight: 1500, Price: 90}}}
+
+	for itemsOrdered := 1; itemsOrdered <= 40; itemsOrdered++ {
+		cheapest, err := CalculateCheapest(itemsOrdered, packSizes, DefaultOptions(), specs, limits, rates)
+		if err != nil {
+			t.Fatalf("%d items: unexpected error: %v", itemsOrdered, err)
+		}
+
+		best := -1
+		for a := 0; a*7 <= cheapest.Cheapest.TotalItems; a++ {
+			for b := 0; a*7+b*5 <= cheapest.Cheapest.TotalItems; b++ {
+				rest := cheapest.Cheapest.TotalItems - a*7 - b*5
+				if rest%3 != 0 {
+					continue
+				}
+				packCounts := map[int]int{}
+				for size, count := range map[int]int{7: a, 5: b, 3: rest / 3} {
+					if count > 0 {
+						packCounts[size] = count
+					}
+				}
+				shipment, err := priceShipment(newPackResult(itemsOrdered, packCounts), specs, limits, rates)
+				if err != nil {
+					t.Fatalf("%d items: unexpected error: %v", itemsOrdered, err)
+				}
+				if best < 0 || shipment.Cost.Total < best {
+					best = shipment.Cost.Total
+				}
+			}
+		}
+
+		if cheapest.Cheapest.Cost.Total != best {
+			t.Errorf("%d items: expected cost %d, got %d for %v", itemsOrdered, best, cheapest.Cheapest.Cost.Total, cheapest.Cheapest.PackResult)
+		}
+		if cheapest.Cheapest.TotalItems != cheapest.Fewest.TotalItems {
+			t.Errorf("%d items: cheapest ships %d items, rule 2 %d", itemsOrdered, cheapest.Cheapest.TotalItems, cheapest.Fewest.TotalItems)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package calculator
+
+// unreachable marks a DP entry that cannot be packed exactly
+const unreachable = -1
+
+// newPackResult builds the pack result of a packing for an order
+func newPackResult(itemsOrdered int, packCounts map[int]int) PackResult {
+	result := PackResult{PackCounts: packCounts}
+	for size, count := range packCounts {
+		result.TotalPacks += count
+		result.TotalItems += size * count
+	}
+	if result.TotalItems >= itemsOrdered {
+		result.ExcessItems = result.TotalItems - itemsOrdered
+	} else {
+		result.ShortItems = itemsOrdered - result.TotalItems
+	}
+	result.DistinctPackTypes = distinctSizes(packCounts)
+	return result
+}
+
+// withFixedPacks adds the packs every packing holds to a packing
+func withFixedPacks(packCounts map[int]int, fixed map[int]int) map[int]int {
+	for size, count := range fixed {
+		packCounts[size] += count
+	}
+	return packCounts
+}
+
+// packsTable builds the DP table of the fewest packs that sum exactly to each total below limit,
+// or unreachable. packSizes must be sorted in descending order.
+func packsTable(limit int, packSizes []int) []int {
+	packs := make([]int, limit)
+	for i := 1; i < limit; i++ {
+		packs[i] = unreachable
+		for _, size := range packSizes {
+			if size > i || packs[i-size] == unreachable {
+				continue
+			}
+			if packs[i] == unreachable || packs[i-size]+1 < packs[i] {
+				packs[i] = packs[i-size] + 1
+			}
+		}
+	}
+	return packs
+}
+
+// maxPacksTable builds the DP table of the most packs that sum exactly to each total below limit,
+// or unreachable. packSizes must be sorted in descending order.
+func maxPacksTable(limit int, packSizes []int) []int {
+	maxPacks := make([]int, limit)
+	for i := 1; i < limit; i++ {
+		maxPacks[i] = unreachable
+		for _, size := range packSizes {
+			if size <= i && maxPacks[i-size] != unreachable && maxPacks[i-size]+1 > maxPacks[i] {
+				maxPacks[i] = maxPacks[i-size] + 1
+			}
+		}
+	}
+	return maxPacks
+}
+
+// enumeratePackingsWithCount calls visit for every packing of exactly packCount packs
+// that sums exactly to total, with at most caps[i] packs of packSizes[i] (Unlimited for no cap),
+// until visit returns false. Packings come most largest packs first,
+// or fewest largest packs first when preferMore is false.
+// minPacks and maxPacks must be tables covering total, they prune totals that cannot be
+// packed in the packs left. packSizes must be sorted in descending order.
+func enumeratePackingsWithCount(total int, packCount int, packSizes []int, caps []int, minPacks []int, maxPacks []int,
+	preferMore bool, visit func(map[int]int) bool) {
+	counts := make([]int, len(packSizes))
+
+	var walk func(index int, remaining int, packsLeft int) bool
+	walk = func(index int, remaining int, packsLeft int) bool {
+		if remaining == 0 {
+			if packsLeft != 0 {
+				return true
+			}
+			packCounts := make(map[int]int)
+			for i, count := range counts {
+				if count > 0 {
+					packCounts[packSizes[i]] += count
+				}
+			}
+			return visit(packCounts)
+		}
+		if index == len(packSizes) || minPacks[remaining] == unreachable ||
+			minPacks[remaining] > packsLeft || maxPacks[remaining] < packsLeft {
+			return true
+		}
+
+		size := packSizes[index]
+		least, most := 0, remaining/size
+		if most > packsLeft {
+			most = packsLeft
+		}
+		if caps[index] != Unlimited && most > caps[index] {
+			most = caps[index]
+		}
+		if index+1 < len(packSizes) {
+			// The packs left after this size hold between smallest and nextSize items each
+			nextSize, smallest := packSizes[index+1], packSizes[len(packSizes)-1]
+			if excess := remaining - packsLeft*nextSize; excess > 0 && size > nextSize {
+				least = (excess + size - nextSize - 1) / (size - nextSize)
+			}
+			if size > smallest {
+				if bound := (remaining - packsLeft*smallest) / (size - smallest); bound < most {
+					most = bound
+				}
+			}
+		}
+		for i := 0; i <= most-least; i++ {
+			count := least + i
+			if preferMore {
+				count = most - i
+			}
+			counts[index] = count
+			if !walk(index+1, remaining-count*size, packsLeft-count) {
+				counts[index] = 0
+				return false
+			}
+		}
+		counts[index] = 0
+		return true
+	}
+
+	walk(0, total, packCount)
+}
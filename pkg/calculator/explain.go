@@ -0,0 +1,240 @@
+package calculator
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Phases of the steps recorded in an explanation
+const (
+	PhaseLimits      = "limits"
+	PhaseBulk        = "bulk"
+	PhaseDP          = "dp"
+	PhaseTieBreak    = "tie-break"
+	PhaseReconstruct = "reconstruct"
+)
+
+// explainLimit caps the number of candidates and alternatives listed in an explanation
+const explainLimit = 5
+
+// Explanation is a structured trace of how a pack result was derived
+type Explanation struct {
+	Algorithm              string        // Algorithm used by CalculatePacksWithOptions
+	AlgorithmReason        string        // Why the algorithm was chosen
+	Candidates             []Candidate   // Totals near the order size that can be packed exactly
+	RejectedByRule2        []Alternative // Packings rejected because they ship more items, or fewer when under-shipping
+	RejectedByRule3        []Alternative // Packings rejected because they ship the same items in more packs
+	RejectedByDistinctRule []Alternative // Packings rejected because they use more distinct pack sizes
+	Steps                  []Step        // Steps the algorithm took to build the result
+}
+
+// Candidate is a total near the order size that can be packed exactly
+type Candidate struct {
+	TotalItems  int  // Total number of items
+	ExcessItems int  // Number of excess items
+	ShortItems  int  // Number of items left to backorder
+	MinPacks    int  // Fewest packs that sum exactly to TotalItems
+	Chosen      bool // Whether this is the total that is shipped
+}
+
+// Alternative is a packing that was considered and rejected
+type Alternative struct {
+	PackResult
+	Reason string // Why the packing was rejected
+}
+
+// Step is a single step of the algorithm that produced a pack result
+type Step struct {
+	Phase       string // limits, bulk, dp, tie-break or reconstruct
+	PackSize    int    // Pack taken in this step, 0 if none
+	Count       int    // Number of packs taken in this step
+	Remaining   int    // Items left after this step
+	Description string // Human-readable description of the step
+}
+
+// tracer records the decisions of an algorithm into an explanation.
+// A nil tracer records nothing, so the algorithms can always call it.
+type tracer struct {
+	explanation *Explanation
+}
+
+// algorithm records the chosen algorithm and why
+func (tr *tracer) algorithm(name string, reason string) {
+	if tr == nil {
+		return
+	}
+	tr.explanation.Algorithm = name
+	tr.explanation.AlgorithmReason = reason
+}
+
+// step records a single step of the algorithm
+func (tr *tracer) step(phase string, packSize int, count int, remaining int, format string, args ...interface{}) {
+	if tr == nil {
+		return
+	}
+	tr.explanation.Steps = append(tr.explanation.Steps, Step{
+		Phase:       phase,
+		PackSize:    packSize,
+		Count:       count,
+		Remaining:   remaining,
+		Description: fmt.Sprintf(format, args...),
+	})
+}
+
+// ExplainCalculatePacks calculates the packs exactly like CalculatePacksWithOptions
+// and explains how the result was derived: the algorithm used, the candidate totals
+// near the order size, the packings rejected by rule 2 and rule 3 and the reconstruction steps.
+func ExplainCalculatePacks(itemsOrdered int, availablePackSizes []int, options Options) (PackResult, Explanation, error) {
+	var explanation Explanation
+	result, err := calculatePacksWithOptions(itemsOrdered, availablePackSizes, options, &tracer{explanation: &explanation})
+	if err != nil {
+		return PackResult{}, Explanation{}, err
+	}
+
+	// Work on a sorted copy, descending
+	packSizes := append([]int(nil), availablePackSizes...)
+	sort.Sort(sort.Reverse(sort.IntSlice(packSizes)))
+
+	explainAlternatives(itemsOrdered, packSizes, options, result, &explanation)
+
+	return result, explanation, nil
+}
+
+// explainAlternatives lists the candidate totals and the rejected packings near the result.
+// Orders are reduced the same way as in CalculatePacksWithOptions, the minimum packs and the bulk
+// of largest packs are shared by every candidate and only the remainder is explored.
+func explainAlternatives(itemsOrdered int, packSizes []int, options Options, result PackResult, explanation *Explanation) {
+	p, err := newIntProblem(itemsOrdered, packSizes, options)
+	if err != nil {
+		return
+	}
+
+	fixed, offset := p.fixedPacks()
+	fixedCount := 0
+	for _, count := range fixed {
+		fixedCount += count
+	}
+	target := p.target
+	table := newSolver(p, options).solve(p.limit(options))
+	limit := len(table.costs)
+
+	// Rule 2 walks up from the target, or down from it when under-shipping
+	direction := 1
+	if options.underShip() {
+		direction = -1
+	}
+
+	// withFixed turns a packing of the remainder into a packing of the whole order
+	withFixed := func(packCounts map[int]int) PackResult {
+		return newPackResult(itemsOrdered, withFixedPacks(packCounts, fixed))
+	}
+
+	// Rule 2: every total further from the order than the result loses
+	chosenSeen := false
+	for total := target; total >= 0 && total < limit; total += direction {
+		if table.costs[total].packs == unreachable {
+			continue
+		}
+		if len(explanation.Candidates) >= explainLimit && chosenSeen {
+			break
+		}
+
+		candidate := Candidate{
+			TotalItems: total + offset,
+			MinPacks:   table.costs[total].packs + fixedCount,
+			Chosen:     total+offset == result.TotalItems,
+		}
+		if candidate.TotalItems >= itemsOrdered {
+			candidate.ExcessItems = candidate.TotalItems - itemsOrdered
+		} else {
+			candidate.ShortItems = itemsOrdered - candidate.TotalItems
+		}
+		explanation.Candidates = append(explanation.Candidates, candidate)
+		chosenSeen = chosenSeen || candidate.Chosen
+
+		if candidate.Chosen {
+			continue
+		}
+		alternative := withFixed(table.reconstruct(total, nil))
+		switch {
+		case options.withinTolerance(alternative):
+			explainWithinTolerance(alternative, options, result, explanation)
+		case alternative.TotalItems > result.TotalItems:
+			explanation.RejectedByRule2 = append(explanation.RejectedByRule2, Alternative{
+				PackResult: alternative,
+				Reason: fmt.Sprintf("Ships %d items, %d more than %d (rule 2)",
+					alternative.TotalItems, alternative.TotalItems-result.TotalItems, result.TotalItems),
+			})
+		default:
+			explanation.RejectedByRule2 = append(explanation.RejectedByRule2, Alternative{
+				PackResult: alternative,
+				Reason: fmt.Sprintf("Ships %d items, %d fewer than %d (rule 2, under-ship)",
+					alternative.TotalItems, result.TotalItems-alternative.TotalItems, result.TotalItems),
+			})
+		}
+	}
+
+	// Rule 3 and the distinct rule: other packings of the same total, fewest packs first
+	chosenTotal := result.TotalItems - offset
+	if chosenTotal < 0 || chosenTotal >= limit || table.costs[chosenTotal].packs == unreachable {
+		return
+	}
+	minPacks := packsTable(limit, packSizes)
+	maxPacks := maxPacksTable(limit, packSizes)
+	for packCount := minPacks[chosenTotal]; packCount <= maxPacks[chosenTotal]; packCount++ {
+		if len(explanation.RejectedByRule3) == explainLimit {
+			break
+		}
+		enumeratePackingsWithCount(chosenTotal, packCount, packSizes, p.caps, minPacks, maxPacks, options.preferMore(), func(packCounts map[int]int) bool {
+			alternative := withFixed(packCounts)
+			switch {
+			case options.distinctIsRule() && alternative.DistinctPackTypes > result.DistinctPackTypes &&
+				(options.distinctFirst() || alternative.TotalPacks == result.TotalPacks):
+				if len(explanation.RejectedByDistinctRule) < explainLimit {
+					explanation.RejectedByDistinctRule = append(explanation.RejectedByDistinctRule, Alternative{
+						PackResult: alternative,
+						Reason: fmt.Sprintf("Uses %d pack sizes instead of %d (fewest distinct pack sizes)",
+							alternative.DistinctPackTypes, result.DistinctPackTypes),
+					})
+				}
+			case alternative.TotalPacks > result.TotalPacks:
+				explanation.RejectedByRule3 = append(explanation.RejectedByRule3, Alternative{
+					PackResult: alternative,
+					Reason: fmt.Sprintf("Ships the same %d items in %d packs instead of %d (rule 3)",
+						alternative.TotalItems, alternative.TotalPacks, result.TotalPacks),
+				})
+			}
+			return len(explanation.RejectedByRule3) < explainLimit
+		})
+	}
+}
+
+// explainWithinTolerance records why a packing of another total within the excess tolerance lost:
+// more packs, more distinct pack sizes, or more items for the same packs
+func explainWithinTolerance(alternative PackResult, options Options, result PackResult, explanation *Explanation) {
+	switch {
+	case alternative.TotalPacks > result.TotalPacks && !options.distinctFirst() ||
+		alternative.TotalPacks > result.TotalPacks && alternative.DistinctPackTypes == result.DistinctPackTypes:
+		if len(explanation.RejectedByRule3) < explainLimit {
+			explanation.RejectedByRule3 = append(explanation.RejectedByRule3, Alternative{
+				PackResult: alternative,
+				Reason: fmt.Sprintf("Ships %d items within the tolerance of %s, but in %d packs instead of %d (rule 3)",
+					alternative.TotalItems, options.Tolerance, alternative.TotalPacks, result.TotalPacks),
+			})
+		}
+	case options.distinctIsRule() && alternative.DistinctPackTypes > result.DistinctPackTypes:
+		if len(explanation.RejectedByDistinctRule) < explainLimit {
+			explanation.RejectedByDistinctRule = append(explanation.RejectedByDistinctRule, Alternative{
+				PackResult: alternative,
+				Reason: fmt.Sprintf("Ships %d items within the tolerance of %s, but uses %d pack sizes instead of %d (fewest distinct pack sizes)",
+					alternative.TotalItems, options.Tolerance, alternative.DistinctPackTypes, result.DistinctPackTypes),
+			})
+		}
+	default:
+		explanation.RejectedByRule2 = append(explanation.RejectedByRule2, Alternative{
+			PackResult: alternative,
+			Reason: fmt.Sprintf("Ships %d items, %d more than %d in no fewer packs (rule 2 within the tolerance of %s)",
+				alternative.TotalItems, alternative.TotalItems-result.TotalItems, result.TotalItems, options.Tolerance),
+		})
+	}
+}
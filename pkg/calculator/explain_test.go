@@ -0,0 +1,159 @@
+package calculator
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestExplainCalculatePacks(t *testing.T) {
+	packSizes := []int{250, 500, 1000, 2000, 5000}
+
+	t.Run("Order 501 items", func(t *testing.T) {
+		result, explanation, err := ExplainCalculatePacks(501, packSizes, DefaultOptions())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		// The explained result must match CalculatePacksWithOptions
+		expected, _ := CalculatePacksWithOptions(501, packSizes, DefaultOptions())
+		if result.TotalItems != expected.TotalItems || result.TotalPacks != expected.TotalPacks {
+			t.Errorf("Expected %v, got %v", expected, result)
+		}
+
+		if explanation.Algorithm != AlgorithmExact {
+			t.Errorf("Expected algorithm %s, got %s", AlgorithmExact, explanation.Algorithm)
+		}
+		if explanation.AlgorithmReason == "" {
+			t.Errorf("Expected a reason for the algorithm choice")
+		}
+
+		// 750 is the smallest total that covers the order, 1000 the next one
+		if len(explanation.Candidates) < 2 {
+			t.Fatalf("Expected at least 2 candidates, got %d", len(explanation.Candidates))
+		}
+		first, second := explanation.Candidates[0], explanation.Candidates[1]
+		if first.TotalItems != 750 || !first.Chosen || first.MinPacks != 2 {
+			t.Errorf("Expected chosen candidate of 750 items in 2 packs, got %+v", first)
+		}
+		if second.TotalItems != 1000 || second.Chosen || second.MinPacks != 1 {
+			t.Errorf("Expected candidate of 1000 items in 1 pack, got %+v", second)
+		}
+
+		// 1x1000 needs fewer packs but ships more items
+		if len(explanation.RejectedByRule2) == 0 || explanation.RejectedByRule2[0].PackCounts[1000] != 1 {
+			t.Errorf("Expected 1x1000 to be rejected by rule 2, got %v", explanation.RejectedByRule2)
+		}
+
+		// 3x250 ships the same items in more packs
+		if len(explanation.RejectedByRule3) == 0 || explanation.RejectedByRule3[0].PackCounts[250] != 3 {
+			t.Errorf("Expected 3x250 to be rejected by rule 3, got %v", explanation.RejectedByRule3)
+		}
+		for _, alternative := range explanation.RejectedByRule3 {
+			if alternative.TotalItems != result.TotalItems || alternative.TotalPacks <= result.TotalPacks {
+				t.Errorf("Rule 3 alternative %v does not ship the same items in more packs", alternative)
+			}
+		}
+
+		if len(explanation.Steps) == 0 {
+			t.Fatalf("Expected reconstruction steps")
+		}
+		if last := explanation.Steps[len(explanation.Steps)-1]; last.Remaining != 0 {
+			t.Errorf("Expected the last step to leave no items, got %+v", last)
+		}
+	})
+
+	t.Run("Order above the safety threshold", func(t *testing.T) {
+		result, explanation, err := ExplainCalculatePacks(2000001, packSizes, DefaultOptions())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if explanation.Algorithm != AlgorithmBulk {
+			t.Errorf("Expected algorithm %s, got %s", AlgorithmBulk, explanation.Algorithm)
+		}
+		if explanation.Steps[0].Phase != PhaseBulk {
+			t.Errorf("Expected the bulk step first, got %+v", explanation.Steps[0])
+		}
+
+		chosen := 0
+		for _, candidate := range explanation.Candidates {
+			if candidate.Chosen {
+				chosen++
+				if candidate.TotalItems != result.TotalItems {
+					t.Errorf("Chosen candidate %+v does not match result %v", candidate, result)
+				}
+			}
+		}
+		if chosen != 1 {
+			t.Errorf("Expected exactly one chosen candidate, got %d", chosen)
+		}
+	})
+
+	t.Run("Distinct pack sizes before rule 3", func(t *testing.T) {
+		options := Options{TieBreak: TieBreakLargerPacks, DistinctRule: DistinctRuleBeforePacks}
+		result, explanation, err := ExplainCalculatePacks(1750, packSizes, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if result.PackCounts[250] != 7 || result.DistinctPackTypes != 1 {
+			t.Errorf("Expected 7x250, got %v", result)
+		}
+
+		// 1x1000 + 1x500 + 1x250 needs fewer packs but three pack sizes
+		rejected := false
+		for _, alternative := range explanation.RejectedByDistinctRule {
+			if alternative.TotalPacks == 3 && alternative.DistinctPackTypes == 3 {
+				rejected = true
+			}
+		}
+		if !rejected {
+			t.Errorf("Expected 1x1000 + 1x500 + 1x250 to be rejected by the distinct rule, got %v", explanation.RejectedByDistinctRule)
+		}
+	})
+
+	t.Run("Invalid order", func(t *testing.T) {
+		if _, _, err := ExplainCalculatePacks(0, packSizes, DefaultOptions()); err == nil {
+			t.Errorf("Expected error but got none")
+		}
+	})
+}
+
+func TestSelectAlgorithm(t *testing.T) {
+	packSizes := []int{250, 500, 1000, 2000, 5000}
+	beyondInt := new(big.Int).Lsh(big.NewInt(1), 70)
+
+	tests := []struct {
+		itemsOrdered *big.Int
+		expected     string
+	}{
+		{big.NewInt(501), AlgorithmExact},
+		{big.NewInt(2000001), AlgorithmBulk},
+		{beyondInt, AlgorithmBig},
+	}
+	for _, test := range tests {
+		algorithm, err := SelectAlgorithm(test.itemsOrdered, packSizes, DefaultOptions())
+		if err != nil {
+			t.Fatalf("Unexpected error for %s items: %v", test.itemsOrdered, err)
+		}
+		if algorithm != test.expected {
+			t.Errorf("Expected algorithm %s for %s items, got %s", test.expected, test.itemsOrdered, algorithm)
+		}
+	}
+
+	// The selected algorithm is the one the explanation reports
+	for _, itemsOrdered := range []int{1, 501, 2000001} {
+		_, explanation, err := ExplainCalculatePacks(itemsOrdered, packSizes, DefaultOptions())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		algorithm, _ := SelectAlgorithm(big.NewInt(int64(itemsOrdered)), packSizes, DefaultOptions())
+		if algorithm != explanation.Algorithm {
+			t.Errorf("Expected algorithm %s for %d items, got %s", explanation.Algorithm, itemsOrdered, algorithm)
+		}
+	}
+
+	if _, err := SelectAlgorithm(big.NewInt(0), packSizes, DefaultOptions()); err == nil {
+		t.Errorf("Expected an error for an empty order")
+	}
+}
@@ -0,0 +1,184 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+)
+
+// ForecastMethod is how the daily consumption of a pack size is forecast from past days
+type ForecastMethod string
+
+const (
+	// ForecastMovingAverage averages the consumption of the last Window days
+	ForecastMovingAverage ForecastMethod = "moving-average"
+	// ForecastExponential smooths the consumption of every day, the latest day weighing Alpha
+	// and each day before it (1 - Alpha) times the day after
+	ForecastExponential ForecastMethod = "exponential-smoothing"
+)
+
+// ForecastMethods lists the supported forecast methods
+var ForecastMethods = []ForecastMethod{ForecastMovingAverage, ForecastExponential}
+
+// ParseForecastMethod parses a forecast method name, an empty name is the default method
+func ParseForecastMethod(name string) (ForecastMethod, error) {
+	if name == "" {
+		return DefaultForecastOptions().Method, nil
+	}
+
+	for _, method := range ForecastMethods {
+		if string(method) == name {
+			return method, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown forecast method %q, expected one of %v", name, ForecastMethods)
+}
+
+// negligibleWeight is the weight below which exponential smoothing ignores older days
+const negligibleWeight = 0.01
+
+// ForecastOptions configure ForecastConsumption
+type ForecastOptions struct {
+	Method ForecastMethod
+	Window int     // Days averaged by the moving average
+	Alpha  float64 // Weight of the latest day in exponential smoothing, in (0, 1]
+	// ServiceFactor is the standard deviations of consumption over the lead time held as safety stock,
+	// 1.65 covers the consumption of 95% of lead times when it is normally distributed
+	ServiceFactor float64
+}
+
+// DefaultForecastOptions returns a 28-day moving average with safety stock for 95% of lead times
+func DefaultForecastOptions() ForecastOptions {
+	return ForecastOptions{
+		Method:        ForecastMovingAverage,
+		Window:        28,
+		Alpha:         0.3,
+		ServiceFactor: 1.65,
+	}
+}
+
+// validate checks the options of the chosen method
+func (o ForecastOptions) validate() error {
+	switch o.Method {
+	case ForecastMovingAverage:
+		if o.Window <= 0 {
+			return fmt.Errorf("moving average window must be at least 1 day, got %d", o.Window)
+		}
+	case ForecastExponential:
+		if o.Alpha <= 0 || o.Alpha > 1 {
+			return fmt.Errorf("smoothing factor must be greater than 0 and at most 1, got %g", o.Alpha)
+		}
+	default:
+		return fmt.Errorf("unknown forecast method %q, expected one of %v", o.Method, ForecastMethods)
+	}
+	if o.ServiceFactor < 0 {
+		return fmt.Errorf("service factor must not be negative, got %g", o.ServiceFactor)
+	}
+	return nil
+}
+
+// HistoryDays returns the number of past days a forecast uses: the window of the moving average, or the days
+// exponential smoothing weighs at least 1% of the latest day
+func (o ForecastOptions) HistoryDays() int {
+	if o.Method != ForecastExponential {
+		return o.Window
+	}
+	if o.Alpha >= 1 {
+		return 1
+	}
+	// Day k before the latest weighs (1 - Alpha)^k times the latest
+	return int(math.Floor(math.Log(negligibleWeight)/math.Log(1-o.Alpha))) + 1
+}
+
+// Forecast is the forecast consumption of a pack size and the stock at which to reorder it
+type Forecast struct {
+	Size             int
+	DailyConsumption float64 // Packs per day
+	Deviation        float64 // Standard deviation of the daily consumption
+	Days             int     // Past days the forecast is based on
+	StockOnHand      int
+	LeadTimeDays     int
+	SafetyStock      int     // Packs held against consumption above the forecast during the lead time
+	ReorderPoint     int     // Forecast consumption over the lead time plus the safety stock
+	DaysOfStock      float64 // Days the stock lasts at the forecast consumption, +Inf without consumption
+	Reorder          bool    // The pack size is consumed and its stock is at or below the reorder point
+}
+
+// ForecastConsumption forecasts the daily consumption of a pack size from the packs consumed on each past
+// day, oldest first, and the stock at which to reorder it so it lasts the supplier's lead time:
+//
+//	reorder point = daily consumption × lead time + service factor × deviation × √lead time
+//
+// The moving average uses the last Window days and their standard deviation. Exponential smoothing starts
+// from the oldest day and uses the exponentially weighted deviation. Without past days the consumption is 0.
+func ForecastConsumption(size int, daily []int, stockOnHand, leadTimeDays int, options ForecastOptions) (Forecast, error) {
+	if err := options.validate(); err != nil {
+		return Forecast{}, err
+	}
+	if stockOnHand < 0 {
+		return Forecast{}, fmt.Errorf("stock on hand must not be negative, got %d", stockOnHand)
+	}
+	if leadTimeDays < 0 {
+		return Forecast{}, fmt.Errorf("lead time must not be negative, got %d days", leadTimeDays)
+	}
+	for _, packs := range daily {
+		if packs < 0 {
+			return Forecast{}, fmt.Errorf("daily consumption must not be negative, got %d", packs)
+		}
+	}
+
+	forecast := Forecast{Size: size, StockOnHand: stockOnHand, LeadTimeDays: leadTimeDays}
+	switch options.Method {
+	case ForecastMovingAverage:
+		window := daily[max(len(daily)-options.Window, 0):]
+		forecast.Days = len(window)
+		forecast.DailyConsumption, forecast.Deviation = meanDeviation(window)
+	case ForecastExponential:
+		forecast.Days = len(daily)
+		forecast.DailyConsumption, forecast.Deviation = smoothedMeanDeviation(daily, options.Alpha)
+	}
+
+	leadTime := float64(leadTimeDays)
+	forecast.SafetyStock = int(math.Ceil(options.ServiceFactor * forecast.Deviation * math.Sqrt(leadTime)))
+	forecast.ReorderPoint = int(math.Ceil(forecast.DailyConsumption*leadTime)) + forecast.SafetyStock
+	forecast.DaysOfStock = math.Inf(1)
+	if forecast.DailyConsumption > 0 {
+		forecast.DaysOfStock = float64(stockOnHand) / forecast.DailyConsumption
+		forecast.Reorder = stockOnHand <= forecast.ReorderPoint
+	}
+	return forecast, nil
+}
+
+// meanDeviation returns the mean and the population standard deviation of the days
+func meanDeviation(daily []int) (float64, float64) {
+	if len(daily) == 0 {
+		return 0, 0
+	}
+
+	sum := 0.0
+	for _, packs := range daily {
+		sum += float64(packs)
+	}
+	mean := sum / float64(len(daily))
+
+	variance := 0.0
+	for _, packs := range daily {
+		variance += (float64(packs) - mean) * (float64(packs) - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(daily)))
+}
+
+// smoothedMeanDeviation returns the exponentially weighted mean and standard deviation of the days, oldest first
+func smoothedMeanDeviation(daily []int, alpha float64) (float64, float64) {
+	if len(daily) == 0 {
+		return 0, 0
+	}
+
+	mean, variance := float64(daily[0]), 0.0
+	for _, packs := range daily[1:] {
+		difference := float64(packs) - mean
+		mean += alpha * difference
+		variance = (1 - alpha) * (variance + alpha*difference*difference)
+	}
+	return mean, math.Sqrt(variance)
+}
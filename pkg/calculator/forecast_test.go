@@ -0,0 +1,100 @@
+package calculator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestForecastConsumption(t *testing.T) {
+	t.Run("Moving average", func(t *testing.T) {
+		options := ForecastOptions{Method: ForecastMovingAverage, Window: 4, ServiceFactor: 2}
+		// Only the last 4 days count: mean 10, deviation 2
+		forecast, err := ForecastConsumption(250, []int{100, 100, 8, 12, 8, 12}, 40, 4, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if forecast.DailyConsumption != 10 || forecast.Deviation != 2 || forecast.Days != 4 {
+			t.Errorf("Expected 10 packs a day with a deviation of 2 over 4 days, got %+v", forecast)
+		}
+		// 10 × 4 days + 2 × 2 × √4
+		if forecast.SafetyStock != 8 || forecast.ReorderPoint != 48 {
+			t.Errorf("Expected a safety stock of 8 and a reorder point of 48, got %+v", forecast)
+		}
+		if forecast.DaysOfStock != 4 || !forecast.Reorder {
+			t.Errorf("Expected 4 days of stock and a reorder, got %+v", forecast)
+		}
+	})
+
+	t.Run("Exponential smoothing", func(t *testing.T) {
+		options := ForecastOptions{Method: ForecastExponential, Alpha: 0.5, ServiceFactor: 1.65}
+		forecast, err := ForecastConsumption(500, []int{0, 8, 8}, 100, 2, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		// 0, then 4, then 6
+		if forecast.DailyConsumption != 6 {
+			t.Errorf("Expected 6 packs a day, got %+v", forecast)
+		}
+		if forecast.Reorder {
+			t.Errorf("Expected no reorder with 100 packs in stock, got %+v", forecast)
+		}
+	})
+
+	t.Run("Steady consumption needs no safety stock", func(t *testing.T) {
+		forecast, err := ForecastConsumption(1000, []int{3, 3, 3}, 10, 5, DefaultForecastOptions())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if forecast.SafetyStock != 0 || forecast.ReorderPoint != 15 || !forecast.Reorder {
+			t.Errorf("Expected a reorder point of 15 without safety stock, got %+v", forecast)
+		}
+	})
+
+	t.Run("Unused pack size", func(t *testing.T) {
+		forecast, err := ForecastConsumption(5000, nil, 0, 7, DefaultForecastOptions())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if forecast.DailyConsumption != 0 || forecast.Reorder || !math.IsInf(forecast.DaysOfStock, 1) {
+			t.Errorf("Expected no consumption and no reorder, got %+v", forecast)
+		}
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		invalid := []ForecastOptions{
+			{Method: ForecastMovingAverage},
+			{Method: ForecastExponential, Alpha: 1.5},
+			{Method: "median", Window: 7},
+			{Method: ForecastMovingAverage, Window: 7, ServiceFactor: -1},
+		}
+		for _, options := range invalid {
+			if _, err := ForecastConsumption(250, []int{1}, 0, 1, options); err == nil {
+				t.Errorf("Expected an error for %+v", options)
+			}
+		}
+		if _, err := ForecastConsumption(250, []int{1}, -1, 1, DefaultForecastOptions()); err == nil {
+			t.Errorf("Expected an error for negative stock")
+		}
+	})
+}
+
+func TestForecastHistoryDays(t *testing.T) {
+	tests := []struct {
+		options  ForecastOptions
+		expected int
+	}{
+		{ForecastOptions{Method: ForecastMovingAverage, Window: 28}, 28},
+		{ForecastOptions{Method: ForecastExponential, Alpha: 1}, 1},
+		// 0.7^12 is above 1%, 0.7^13 below
+		{ForecastOptions{Method: ForecastExponential, Alpha: 0.3}, 13},
+	}
+	for _, test := range tests {
+		if days := test.options.HistoryDays(); days != test.expected {
+			t.Errorf("Expected %d days for %+v, got %d", test.expected, test.options, days)
+		}
+	}
+}
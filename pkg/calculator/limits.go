@@ -0,0 +1,277 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Unlimited marks a PackLimit without a maximum
+const Unlimited = -1
+
+// ErrInfeasible is returned when no packing satisfies the per-size pack limits
+var ErrInfeasible = errors.New("no packing satisfies the pack limits")
+
+// ErrInvalidLimit is returned for a pack limit that is inconsistent or names a size that is not available
+var ErrInvalidLimit = errors.New("invalid pack limit")
+
+// ErrUnsupportedOrder is returned for orders above the safety threshold that the options rule out reducing to the
+// exact DP: a maximum on the largest pack size or the before-packs distinct pack sizes rule
+var ErrUnsupportedOrder = errors.New("order too large for these options")
+
+// PackLimit bounds how many packs of one size a packing may hold
+type PackLimit struct {
+	Min int // Fewest packs of the size, 0 for no minimum
+	Max int // Most packs of the size, Unlimited for no maximum
+}
+
+// NoLimit returns a PackLimit that does not constrain the size
+func NoLimit() PackLimit {
+	return PackLimit{Min: 0, Max: Unlimited}
+}
+
+// validate checks that the limit is consistent
+func (l PackLimit) validate(size int) error {
+	if l.Min < 0 {
+		return fmt.Errorf("%w: minimum for pack size %d must not be negative", ErrInvalidLimit, size)
+	}
+	if l.Max != Unlimited && l.Max < l.Min {
+		return fmt.Errorf("%w: maximum for pack size %d must be at least its minimum %d or unlimited",
+			ErrInvalidLimit, size, l.Min)
+	}
+	return nil
+}
+
+// problem is an order prepared for the DP. The fixed packs and the bulk are part of every optimal packing,
+// the DP only packs the target on top of them.
+type problem struct {
+	packSizes  []int       // Sorted in descending order
+	fixed      map[int]int // Packs every packing holds because of the per-size minimums
+	fixedItems *big.Int    // Items in the fixed packs
+	bulk       *big.Int    // Largest packs every optimal packing holds on top of the fixed packs
+	bounded    bool        // The bulk only brings the order within the DP, the pack sizes are too fine-grained to prove it optimal
+	caps       []int       // Most packs of each size on top of the fixed packs, Unlimited if not capped
+	target     int         // Items left for the DP, 0 if the fixed packs already cover the order
+	tolerance  int         // Excess items above the target within the tolerance, at most the safety threshold
+	allowance  int         // Part of the tolerance the DP must search, see Tolerance
+}
+
+// newProblem sets aside the packs every optimal packing holds: the per-size minimums first,
+// then, for orders above the safety threshold, the bulk of largest packs (see CalculatePacksBig).
+// packSizes must be sorted in descending order.
+func newProblem(itemsOrdered *big.Int, packSizes []int, options Options) (problem, error) {
+	p := problem{
+		packSizes:  packSizes,
+		fixed:      make(map[int]int),
+		fixedItems: new(big.Int),
+		bulk:       new(big.Int),
+		caps:       make([]int, len(packSizes)),
+	}
+
+	available := make(map[int]bool, len(packSizes))
+	for _, size := range packSizes {
+		available[size] = true
+	}
+	for _, size := range options.limitSizes() {
+		if !available[size] {
+			return problem{}, fmt.Errorf("%w: pack size %d is not available", ErrInvalidLimit, size)
+		}
+	}
+
+	capped := true
+	capacity := new(big.Int)
+	for i, size := range packSizes {
+		limit, ok := options.Limits[size]
+		if !ok {
+			limit = NoLimit()
+		}
+
+		if limit.Min > 0 {
+			p.fixed[size] = limit.Min
+			p.fixedItems.Add(p.fixedItems, new(big.Int).Mul(big.NewInt(int64(limit.Min)), big.NewInt(int64(size))))
+		}
+
+		p.caps[i] = Unlimited
+		if limit.Max == Unlimited {
+			capped = false
+			continue
+		}
+		p.caps[i] = limit.Max - limit.Min
+		capacity.Add(capacity, new(big.Int).Mul(big.NewInt(int64(p.caps[i])), big.NewInt(int64(size))))
+	}
+
+	remaining := new(big.Int).Sub(itemsOrdered, p.fixedItems)
+	overshoot := new(big.Int)
+	if remaining.Sign() < 0 {
+		overshoot.Neg(remaining)
+		if options.underShip() {
+			return problem{}, fmt.Errorf("%w: the minimums hold %s items, more than the %s ordered",
+				ErrInfeasible, p.fixedItems, itemsOrdered)
+		}
+		remaining.SetInt64(0)
+	}
+
+	// Under-shipping never runs out of capacity, it ships less
+	if capped && !options.underShip() && remaining.Cmp(capacity) > 0 {
+		return problem{}, fmt.Errorf("%w: the maximums leave room for %s more items after the minimums, %s are needed",
+			ErrInfeasible, capacity, remaining)
+	}
+
+	largestPack := packSizes[0]
+	if remaining.Cmp(big.NewInt(int64(safetyThreshold-largestPack))) > 0 {
+		// Every packing with the fewest packs holds the bulk, but a packing with fewer distinct sizes
+		// and more packs may not, and a maximum on the largest pack may rule the bulk out
+		if options.distinctFirst() {
+			return problem{}, fmt.Errorf("%w: the %s distinct pack sizes rule supports orders of up to %d items, got %s",
+				ErrUnsupportedOrder, options.DistinctRule, safetyThreshold-largestPack, itemsOrdered)
+		}
+		if p.caps[0] != Unlimited {
+			return problem{}, fmt.Errorf("%w: a maximum on the largest pack size %d is only supported for orders of up to %d items, got %s",
+				ErrUnsupportedOrder, largestPack, safetyThreshold-largestPack, itemsOrdered)
+		}
+
+		// The best total of an under-shipped order may be up to a largest pack below it,
+		// so the bulk is taken from that total
+		items := remaining
+		if options.underShip() {
+			items = new(big.Int).Sub(remaining, big.NewInt(int64(largestPack)))
+		}
+		bulk, err := bulkPacks(items, packSizes)
+		if errors.Is(err, ErrFineGrained) && itemsOrdered.Cmp(big.NewInt(math.MaxInt)) <= 0 {
+			// Orders that fit in an int are still calculated, as OptimalCalculatePacks did: the largest packs ship
+			// the order down to what the exact DP covers. The result is then the best packing holding them.
+			bulk, err = packsAbove(items, dpBound(largestPack), largestPack), nil
+			p.bounded = true
+		}
+		if err != nil {
+			return problem{}, err
+		}
+		p.bulk = bulk
+		remaining.Sub(remaining, new(big.Int).Mul(bulk, big.NewInt(int64(largestPack))))
+	}
+
+	// remaining is now within the safety threshold, small enough for the exact DP
+	p.target = int(remaining.Int64())
+
+	if !options.Tolerance.IsZero() {
+		// The allowance counts from the order, minimums above it already use part of it
+		allowance := options.Tolerance.allowance(itemsOrdered)
+		allowance.Sub(allowance, overshoot)
+		if allowance.Sign() < 0 {
+			allowance.SetInt64(0)
+		}
+		p.tolerance = safetyThreshold
+		if allowance.Cmp(big.NewInt(int64(safetyThreshold))) < 0 {
+			p.tolerance = int(allowance.Int64())
+		}
+
+		if p.caps[0] == Unlimited && !options.distinctFirst() {
+			// Packings that beat the largest packs rounding up the target hold no more packs than them,
+			// so they ship no more items and the allowance can stop there
+			if roundUp := int64((largestPack - p.target%largestPack) % largestPack); allowance.Cmp(big.NewInt(roundUp)) > 0 {
+				allowance.SetInt64(roundUp)
+			}
+		} else if allowance.Cmp(big.NewInt(int64(safetyThreshold-p.target))) > 0 {
+			return problem{}, fmt.Errorf("%w: a tolerance of %s is too large for this order, at most %d excess items are supported "+
+				"with a maximum on the largest pack size or the %s distinct pack sizes rule",
+				ErrInvalidTolerance, options.Tolerance, safetyThreshold-p.target, DistinctRuleBeforePacks)
+		}
+		p.allowance = int(allowance.Int64())
+	}
+
+	return p, nil
+}
+
+// newIntProblem is newProblem for orders that fit in an int, it returns ErrOverflow
+// when the fixed packs would not
+func newIntProblem(itemsOrdered int, packSizes []int, options Options) (problem, error) {
+	if overflows(itemsOrdered, packSizes[0], 0) {
+		return problem{}, ErrOverflow
+	}
+
+	p, err := newProblem(big.NewInt(int64(itemsOrdered)), packSizes, options)
+	if err != nil {
+		return problem{}, err
+	}
+	if !p.fits() {
+		return problem{}, ErrOverflow
+	}
+	return p, nil
+}
+
+// bulkPacks returns how many largest packs every optimal packing of items holds:
+// (items - bound) / largest, where bound is the most items in other packs (see CalculatePacksBig).
+// packSizes must be sorted in descending order.
+func bulkPacks(items *big.Int, packSizes []int) (*big.Int, error) {
+	bound, err := bulkBound(packSizes)
+	if err != nil {
+		return nil, err
+	}
+	return packsAbove(items, bound, packSizes[0]), nil
+}
+
+// packsAbove returns how many largest packs ship items down to at most bound items, or 0 if there are no more
+func packsAbove(items *big.Int, bound int, largestPack int) *big.Int {
+	bulk := new(big.Int).Sub(items, big.NewInt(int64(bound)))
+	bulk.Quo(bulk, big.NewInt(int64(largestPack)))
+	if bulk.Sign() < 0 {
+		bulk.SetInt64(0)
+	}
+	return bulk
+}
+
+// limit returns the totals the DP must cover: over-shipping may need up to a largest pack
+// above the target and its allowance, under-shipping only the target itself
+func (p problem) limit(options Options) int {
+	if options.underShip() {
+		return p.target + 1
+	}
+	return p.target + p.allowance + p.packSizes[0]
+}
+
+// present reports whether every packing already holds packs of the size at index,
+// so more packs of it add no distinct size
+func (p problem) present(index int) bool {
+	return p.fixed[p.packSizes[index]] > 0 || (index == 0 && p.bulk.Sign() > 0)
+}
+
+// fits reports whether the fixed packs and the bulk fit in an int next to a full DP table
+func (p problem) fits() bool {
+	items := new(big.Int).Mul(p.bulk, big.NewInt(int64(p.packSizes[0])))
+	items.Add(items, p.fixedItems)
+	return items.Cmp(big.NewInt(int64(math.MaxInt-2*safetyThreshold))) <= 0
+}
+
+// fixedPacks returns the fixed packs and the bulk as int pack counts with their items.
+// The problem must fit in an int.
+func (p problem) fixedPacks() (map[int]int, int) {
+	packCounts := make(map[int]int, len(p.fixed)+1)
+	for size, count := range p.fixed {
+		packCounts[size] = count
+	}
+	if p.bulk.Sign() > 0 {
+		packCounts[p.packSizes[0]] += int(p.bulk.Int64())
+	}
+
+	items := 0
+	for size, count := range packCounts {
+		items += size * count
+	}
+	return packCounts, items
+}
+
+// describeLimits returns a human-readable description of the per-size limits
+func (o Options) describeLimits() string {
+	var parts []string
+	for _, size := range o.limitSizes() {
+		limit := o.Limits[size]
+		if limit.Max == Unlimited {
+			parts = append(parts, fmt.Sprintf("at least %d × %d", limit.Min, size))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d to %d × %d", limit.Min, limit.Max, size))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
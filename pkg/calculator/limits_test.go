@@ -0,0 +1,228 @@
+package calculator
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestCalculatePacksWithLimits(t *testing.T) {
+	standardPacks := []int{250, 500, 1000, 2000, 5000}
+
+	testCases := []struct {
+		name           string
+		itemsOrdered   int
+		packSizes      []int
+		limits         map[int]PackLimit
+		expectedCounts string
+		expectError    error
+	}{
+		{
+			name:           "Minimum of a small pack",
+			itemsOrdered:   501,
+			packSizes:      standardPacks,
+			limits:         map[int]PackLimit{250: {Min: 2, Max: Unlimited}},
+			expectedCounts: "3x250",
+		},
+		{
+			name:           "Minimum already covering the order",
+			itemsOrdered:   100,
+			packSizes:      standardPacks,
+			limits:         map[int]PackLimit{1000: {Min: 1, Max: Unlimited}},
+			expectedCounts: "1x1000",
+		},
+		{
+			name:           "Maximum of the largest pack",
+			itemsOrdered:   12001,
+			packSizes:      standardPacks,
+			limits:         map[int]PackLimit{5000: {Min: 0, Max: 1}},
+			expectedCounts: "1x5000 3x2000 1x1000 1x250",
+		},
+		{
+			name:           "Largest pack excluded",
+			itemsOrdered:   5000,
+			packSizes:      standardPacks,
+			limits:         map[int]PackLimit{5000: {Min: 0, Max: 0}},
+			expectedCounts: "2x2000 1x1000",
+		},
+		{
+			name:           "Exact count of a pack",
+			itemsOrdered:   1000,
+			packSizes:      standardPacks,
+			limits:         map[int]PackLimit{500: {Min: 1, Max: 1}},
+			expectedCounts: "1x500 2x250",
+		},
+		{
+			name:         "Maximums too small for the order",
+			itemsOrdered: 1001,
+			packSizes:    []int{250, 500},
+			limits:       map[int]PackLimit{250: {Min: 0, Max: 1}, 500: {Min: 0, Max: 1}},
+			expectError:  ErrInfeasible,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options := DefaultOptions()
+			options.Limits = tc.limits
+
+			result, err := CalculatePacksWithOptions(tc.itemsOrdered, tc.packSizes, options)
+			if tc.expectError != nil {
+				if !errors.Is(err, tc.expectError) {
+					t.Errorf("Expected %v, got %v", tc.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got := formatPackCounts(result.PackCounts); got != tc.expectedCounts {
+				t.Errorf("Expected %s, got %s", tc.expectedCounts, got)
+			}
+		})
+	}
+}
+
+func TestInvalidPackLimits(t *testing.T) {
+	testCases := []struct {
+		name   string
+		limits map[int]PackLimit
+	}{
+		{name: "Negative minimum", limits: map[int]PackLimit{250: {Min: -1, Max: Unlimited}}},
+		{name: "Maximum below minimum", limits: map[int]PackLimit{250: {Min: 3, Max: 2}}},
+		{name: "Size not available", limits: map[int]PackLimit{300: {Min: 1, Max: Unlimited}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options := DefaultOptions()
+			options.Limits = tc.limits
+			if _, err := CalculatePacksWithOptions(1000, []int{250, 500}, options); !errors.Is(err, ErrInvalidLimit) {
+				t.Errorf("Expected %v, got %v", ErrInvalidLimit, err)
+			}
+		})
+	}
+}
+
+// bestWithinLimits finds the best packing within the limits and the tolerance by trying every pack count
+func bestWithinLimits(itemsOrdered int, packSizes []int, options Options) (PackResult, bool) {
+	var best PackResult
+	found := false
+	counts := make(map[int]int)
+
+	var walk func(index int)
+	walk = func(index int) {
+		if index == len(packSizes) {
+			packCounts := make(map[int]int)
+			items := 0
+			for size, count := range counts {
+				if count > 0 {
+					packCounts[size] = count
+					items += size * count
+				}
+			}
+			if (items < itemsOrdered) != options.underShip() && items != itemsOrdered {
+				return
+			}
+			result := newPackResult(itemsOrdered, packCounts)
+			if !found || options.Less(result, best) {
+				best, found = result, true
+			}
+			return
+		}
+
+		size := packSizes[index]
+		limit, ok := options.Limits[size]
+		if !ok {
+			limit = NoLimit()
+		}
+		most := limit.Min + (itemsOrdered+int(options.Tolerance.allowance(big.NewInt(int64(itemsOrdered))).Int64()))/size + 1
+		if limit.Max != Unlimited && limit.Max < most {
+			most = limit.Max
+		}
+		for count := limit.Min; count <= most; count++ {
+			counts[size] = count
+			walk(index + 1)
+		}
+		counts[size] = 0
+	}
+
+	walk(0)
+	return best, found
+}
+
+// TestLimitsMatchBruteForce checks the limited DP against trying every packing
+func TestLimitsMatchBruteForce(t *testing.T) {
+	packSizes := []int{7, 5, 3}
+	limitSets := []map[int]PackLimit{
+		{7: {Min: 0, Max: 1}},
+		{3: {Min: 2, Max: Unlimited}},
+		{5: {Min: 1, Max: 2}, 3: {Min: 0, Max: 0}},
+		{7: {Min: 0, Max: 2}, 5: {Min: 0, Max: 1}, 3: {Min: 1, Max: 3}},
+	}
+
+	for _, limits := range limitSets {
+		for _, options := range optionCombinations() {
+			for _, fulfilment := range Fulfilments {
+				options.Limits = limits
+				options.Fulfilment = fulfilment
+				for itemsOrdered := 1; itemsOrdered <= 40; itemsOrdered++ {
+					expected, found := bestWithinLimits(itemsOrdered, packSizes, options)
+					result, err := CalculatePacksWithOptions(itemsOrdered, packSizes, options)
+					if !found {
+						if !errors.Is(err, ErrInfeasible) {
+							t.Errorf("%+v, %d items: expected %v, got %v", options, itemsOrdered, ErrInfeasible, err)
+						}
+						continue
+					}
+					if err != nil {
+						t.Fatalf("%+v, %d items: unexpected error: %v", options, itemsOrdered, err)
+					}
+					if formatPackCounts(result.PackCounts) != formatPackCounts(expected.PackCounts) {
+						t.Errorf("%+v, %d items: expected %v, got %v", options, itemsOrdered, expected, result)
+					}
+
+					alternatives, err := CalculateAlternatives(itemsOrdered, packSizes, 0, options)
+					if err != nil {
+						t.Fatalf("%+v, %d items: unexpected error: %v", options, itemsOrdered, err)
+					}
+					if formatPackCounts(alternatives.Optimal[0].PackCounts) != formatPackCounts(expected.PackCounts) {
+						t.Errorf("%+v, %d items: expected %v as the first optimal packing, got %v",
+							options, itemsOrdered, expected, alternatives.Optimal[0])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestCalculatePacksBigWithLimits(t *testing.T) {
+	// 10^30
+	huge, _ := new(big.Int).SetString("1000000000000000000000000000000", 10)
+	standardPacks := []int{250, 500, 1000, 2000, 5000}
+
+	options := DefaultOptions()
+	options.Limits = map[int]PackLimit{250: {Min: 3, Max: Unlimited}}
+	result, err := CalculatePacksBig(new(big.Int).Add(huge, big.NewInt(1)), standardPacks, options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.PackCounts[250].Cmp(big.NewInt(3)) < 0 {
+		t.Errorf("Expected at least 3 packs of 250, got %s", result.PackCounts[250])
+	}
+	if result.ExcessItems.Cmp(big.NewInt(249)) != 0 {
+		t.Errorf("Expected 249 excess items, got %s", result.ExcessItems)
+	}
+
+	options.Limits = map[int]PackLimit{5000: {Min: 0, Max: 10}}
+	if _, err := CalculatePacksBig(huge, standardPacks, options); !errors.Is(err, ErrUnsupportedOrder) {
+		t.Errorf("Expected %v for a maximum on the largest pack of a huge order, got %v", ErrUnsupportedOrder, err)
+	}
+
+	options = DefaultOptions()
+	options.DistinctRule = DistinctRuleBeforePacks
+	if _, err := CalculatePacksWithOptions(2000000, standardPacks, options); !errors.Is(err, ErrUnsupportedOrder) {
+		t.Errorf("Expected %v for the %s rule above the safety threshold, got %v", ErrUnsupportedOrder, options.DistinctRule, err)
+	}
+}
@@ -0,0 +1,237 @@
+package calculator
+
+import (
+	"fmt"
+	"sort"
+)
+
+// TieBreak decides between packings that tie on every business rule
+type TieBreak string
+
+const (
+	// TieBreakLargerPacks prefers the most of the largest pack, then the most of the next largest, and so on
+	TieBreakLargerPacks TieBreak = "larger-packs"
+	// TieBreakSmallerPacks prefers the fewest of the largest pack, then the fewest of the next largest, and so on
+	TieBreakSmallerPacks TieBreak = "smaller-packs"
+	// TieBreakFewerSizes prefers the fewest distinct pack sizes, remaining ties prefer larger packs
+	TieBreakFewerSizes TieBreak = "fewer-sizes"
+)
+
+// TieBreaks lists the supported tie-break policies
+var TieBreaks = []TieBreak{TieBreakLargerPacks, TieBreakSmallerPacks, TieBreakFewerSizes}
+
+// ParseTieBreak parses a tie-break policy name, an empty name is the default policy
+func ParseTieBreak(name string) (TieBreak, error) {
+	if name == "" {
+		return DefaultOptions().TieBreak, nil
+	}
+
+	for _, tieBreak := range TieBreaks {
+		if string(tieBreak) == name {
+			return tieBreak, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown tie-break policy %q, expected one of %v", name, TieBreaks)
+}
+
+// DistinctRule places the "fewest distinct pack sizes" rule among the business rules
+type DistinctRule string
+
+const (
+	// DistinctRuleIgnore leaves the number of distinct pack sizes to the tie-break policy
+	DistinctRuleIgnore DistinctRule = "ignore"
+	// DistinctRuleAfterPacks minimises distinct pack sizes after rules 2 and 3
+	DistinctRuleAfterPacks DistinctRule = "after-packs"
+	// DistinctRuleBeforePacks minimises distinct pack sizes after rule 2 but before rule 3,
+	// so 7x250 beats 1x1000 + 1x500 + 1x250
+	DistinctRuleBeforePacks DistinctRule = "before-packs"
+)
+
+// DistinctRules lists the supported positions of the distinct pack sizes rule
+var DistinctRules = []DistinctRule{DistinctRuleIgnore, DistinctRuleAfterPacks, DistinctRuleBeforePacks}
+
+// ParseDistinctRule parses a distinct pack sizes rule name, an empty name is the default rule
+func ParseDistinctRule(name string) (DistinctRule, error) {
+	if name == "" {
+		return DefaultOptions().DistinctRule, nil
+	}
+
+	for _, rule := range DistinctRules {
+		if string(rule) == name {
+			return rule, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown distinct pack sizes rule %q, expected one of %v", name, DistinctRules)
+}
+
+// Fulfilment decides whether an order may be shipped over or under the quantity ordered
+type Fulfilment string
+
+const (
+	// FulfilmentOverShip ships the fewest items at or above the order (rule 2)
+	FulfilmentOverShip Fulfilment = "over-ship"
+	// FulfilmentUnderShip ships the most items at or below the order and backorders the rest
+	FulfilmentUnderShip Fulfilment = "under-ship"
+)
+
+// Fulfilments lists the supported fulfilment modes
+var Fulfilments = []Fulfilment{FulfilmentOverShip, FulfilmentUnderShip}
+
+// ParseFulfilment parses a fulfilment mode name, an empty name is the default mode
+func ParseFulfilment(name string) (Fulfilment, error) {
+	if name == "" {
+		return DefaultOptions().Fulfilment, nil
+	}
+
+	for _, fulfilment := range Fulfilments {
+		if string(fulfilment) == name {
+			return fulfilment, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown fulfilment mode %q, expected one of %v", name, Fulfilments)
+}
+
+// Options configure how CalculatePacksWithOptions picks between packings
+type Options struct {
+	TieBreak     TieBreak          // How packings that tie on every business rule are decided
+	DistinctRule DistinctRule      // Where the fewest distinct pack sizes rule applies, if at all
+	Limits       map[int]PackLimit // Per-size minimum and maximum pack counts, sizes without one are unlimited
+	Fulfilment   Fulfilment        // Whether rule 2 over-ships or under-ships the order
+	Tolerance    Tolerance         // Excess items accepted to save packs, the zero value is the strict rule 2
+}
+
+// DefaultOptions returns the options used when nothing else is configured
+func DefaultOptions() Options {
+	return Options{
+		TieBreak:     TieBreakLargerPacks,
+		DistinctRule: DistinctRuleIgnore,
+		Fulfilment:   FulfilmentOverShip,
+	}
+}
+
+// validate checks that the options are supported
+func (o Options) validate() error {
+	if _, err := ParseTieBreak(string(o.TieBreak)); err != nil {
+		return err
+	}
+	if _, err := ParseDistinctRule(string(o.DistinctRule)); err != nil {
+		return err
+	}
+	if _, err := ParseFulfilment(string(o.Fulfilment)); err != nil {
+		return err
+	}
+	if err := o.Tolerance.validate(); err != nil {
+		return err
+	}
+	if o.underShip() && !o.Tolerance.IsZero() {
+		return fmt.Errorf("%w: an excess tolerance is only supported when over-shipping", ErrInvalidTolerance)
+	}
+	for _, size := range o.limitSizes() {
+		if err := o.Limits[size].validate(size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// limitSizes returns the sizes with a pack limit in descending order
+func (o Options) limitSizes() []int {
+	sizes := make([]int, 0, len(o.Limits))
+	for size := range o.Limits {
+		sizes = append(sizes, size)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	return sizes
+}
+
+// underShip reports whether rule 2 looks for the most items at or below the order
+func (o Options) underShip() bool {
+	return o.Fulfilment == FulfilmentUnderShip
+}
+
+// distinctFirst reports whether distinct pack sizes are minimised before the number of packs
+func (o Options) distinctFirst() bool {
+	return o.DistinctRule == DistinctRuleBeforePacks
+}
+
+// distinctIsRule reports whether the number of distinct pack sizes is a business rule,
+// packings that differ on it are not tied
+func (o Options) distinctIsRule() bool {
+	return o.DistinctRule == DistinctRuleAfterPacks || o.DistinctRule == DistinctRuleBeforePacks
+}
+
+// preferMore reports whether ties on a pack size go to the packing with more packs of that size.
+// Sizes are compared largest first.
+func (o Options) preferMore() bool {
+	return o.TieBreak != TieBreakSmallerPacks
+}
+
+// countsDistinct reports whether the number of distinct pack sizes is compared at all,
+// as a business rule or to break ties
+func (o Options) countsDistinct() bool {
+	return o.distinctIsRule() || o.TieBreak == TieBreakFewerSizes
+}
+
+// Less reports whether packing a is better than packing b for the same order:
+// fewer items (rule 2), or more items when under-shipping, then fewer packs (rule 3) and fewer distinct pack sizes in the order
+// set by the distinct rule, then the tie-break policy.
+// Packings within the excess tolerance compare on rule 3 and the distinct rule before their number of items.
+func (o Options) Less(a, b PackResult) bool {
+	if a.TotalItems != b.TotalItems && !(o.withinTolerance(a) && o.withinTolerance(b)) {
+		return (a.TotalItems < b.TotalItems) != o.underShip()
+	}
+	if o.distinctFirst() && a.DistinctPackTypes != b.DistinctPackTypes {
+		return a.DistinctPackTypes < b.DistinctPackTypes
+	}
+	if a.TotalPacks != b.TotalPacks {
+		return a.TotalPacks < b.TotalPacks
+	}
+	if o.distinctIsRule() && a.DistinctPackTypes != b.DistinctPackTypes {
+		return a.DistinctPackTypes < b.DistinctPackTypes
+	}
+	if a.TotalItems != b.TotalItems {
+		// Both are within the tolerance and tie on the other rules
+		return a.TotalItems < b.TotalItems
+	}
+	if o.countsDistinct() && a.DistinctPackTypes != b.DistinctPackTypes {
+		return a.DistinctPackTypes < b.DistinctPackTypes
+	}
+
+	// Compare the counts of each size, largest size first
+	sizes := make([]int, 0, len(a.PackCounts)+len(b.PackCounts))
+	for size := range a.PackCounts {
+		sizes = append(sizes, size)
+	}
+	for size := range b.PackCounts {
+		sizes = append(sizes, size)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+
+	for _, size := range sizes {
+		if a.PackCounts[size] != b.PackCounts[size] {
+			return (a.PackCounts[size] > b.PackCounts[size]) == o.preferMore()
+		}
+	}
+	return false
+}
+
+// Tied reports whether packings a and b are equally good on every business rule,
+// so only the tie-break policy decides between them
+func (o Options) Tied(a, b PackResult) bool {
+	return a.TotalItems == b.TotalItems && a.TotalPacks == b.TotalPacks &&
+		(!o.distinctIsRule() || a.DistinctPackTypes == b.DistinctPackTypes)
+}
+
+// distinctSizes returns the number of pack sizes actually used in a packing
+func distinctSizes(packCounts map[int]int) int {
+	distinct := 0
+	for _, count := range packCounts {
+		if count > 0 {
+			distinct++
+		}
+	}
+	return distinct
+}
@@ -0,0 +1,226 @@
+package calculator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Level is a container level of a packaging hierarchy, e.g. a case holding 10 packs
+type Level struct {
+	Name     string // Name of the container, e.g. "case"
+	Capacity int    // Units of the level below a container holds, packs for the innermost level
+}
+
+// Hierarchy lists the container levels packs are shipped in, innermost first, e.g. case then pallet
+type Hierarchy []Level
+
+// ParseHierarchy parses a hierarchy such as "case:10,pallet:40", innermost level first.
+// An empty string is no hierarchy, packs ship loose.
+func ParseHierarchy(value string) (Hierarchy, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	var hierarchy Hierarchy
+	for _, part := range strings.Split(value, ",") {
+		name, capacity, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid packaging level %q, expected name:capacity such as case:10", part)
+		}
+		parsed, err := strconv.Atoi(strings.TrimSpace(capacity))
+		if err != nil {
+			return nil, fmt.Errorf("invalid capacity in packaging level %q: %v", part, err)
+		}
+		hierarchy = append(hierarchy, Level{Name: strings.TrimSpace(name), Capacity: parsed})
+	}
+
+	if err := hierarchy.validate(); err != nil {
+		return nil, err
+	}
+	return hierarchy, nil
+}
+
+// String formats the hierarchy the way ParseHierarchy reads it
+func (h Hierarchy) String() string {
+	parts := make([]string, len(h))
+	for i, level := range h {
+		parts[i] = fmt.Sprintf("%s:%d", level.Name, level.Capacity)
+	}
+	return strings.Join(parts, ",")
+}
+
+// validate checks that every level is named once and holds at least one unit
+func (h Hierarchy) validate() error {
+	seen := make(map[string]bool, len(h))
+	for _, level := range h {
+		if level.Name == "" {
+			return fmt.Errorf("packaging levels must be named")
+		}
+		if seen[level.Name] {
+			return fmt.Errorf("packaging level %q appears more than once", level.Name)
+		}
+		seen[level.Name] = true
+		if level.Capacity <= 0 {
+			return fmt.Errorf("capacity of packaging level %q must be positive", level.Name)
+		}
+	}
+	return nil
+}
+
+// Container is a group of identical containers of one level of a hierarchy
+type Container struct {
+	Level    string      // Name of the level, e.g. "case"
+	Count    int         // Number of identical containers in the group
+	Units    int         // Units of the level below in each container
+	Full     bool        // Whether each container is filled to capacity
+	Packs    map[int]int // Packs per size in each container, innermost level only
+	Contents []Container // Container groups in each container, outer levels only
+}
+
+// LevelSummary counts the containers of one level of a hierarchy
+type LevelSummary struct {
+	Name       string // Name of the level
+	Capacity   int    // Units of the level below a container holds
+	Containers int    // Containers used
+	Partial    int    // Containers not filled to capacity, at most one
+}
+
+// Packaging is how the packs of a result roll up through a hierarchy
+type Packaging struct {
+	Levels     []LevelSummary // Containers per level, innermost first
+	Containers []Container    // Container groups of the outermost level, largest packs first
+}
+
+// RollUp packs the packs of a result into the levels of a hierarchy, innermost first.
+// Every level is filled in order, largest packs first, so all containers but the last of each level
+// are full and no packing needs more containers: ceil(units / capacity) per level is the minimum.
+func RollUp(result PackResult, hierarchy Hierarchy) (Packaging, error) {
+	if err := hierarchy.validate(); err != nil {
+		return Packaging{}, err
+	}
+
+	// Runs of identical units of the current level, starting with the packs largest first
+	var units []Container
+	sizes := make([]int, 0, len(result.PackCounts))
+	for size, count := range result.PackCounts {
+		if count > 0 {
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	for _, size := range sizes {
+		units = append(units, Container{Count: result.PackCounts[size], Packs: map[int]int{size: 1}})
+	}
+
+	var packaging Packaging
+	for _, level := range hierarchy {
+		units = fill(units, level)
+
+		summary := LevelSummary{Name: level.Name, Capacity: level.Capacity}
+		for _, group := range units {
+			summary.Containers += group.Count
+			if !group.Full {
+				summary.Partial += group.Count
+			}
+		}
+		packaging.Levels = append(packaging.Levels, summary)
+	}
+
+	if len(hierarchy) > 0 {
+		packaging.Containers = units
+	}
+	return packaging, nil
+}
+
+// fill puts runs of units into containers of a level in order and returns the runs of containers.
+// A run that fills whole containers on its own becomes one group, the units around it share containers.
+func fill(units []Container, level Level) []Container {
+	var containers []Container
+	var current Container // The container being filled, Units is 0 while it is empty
+
+	add := func(group Container) {
+		if n := len(containers); n > 0 && sameContainer(containers[n-1], group) {
+			containers[n-1].Count += group.Count
+			return
+		}
+		containers = append(containers, group)
+	}
+	put := func(unit Container, count int) {
+		if current.Units == 0 {
+			current = Container{Level: level.Name}
+		}
+		current.Units += count
+		if unit.Level == "" {
+			// Packs go straight into the innermost level
+			if current.Packs == nil {
+				current.Packs = make(map[int]int)
+			}
+			for size, packs := range unit.Packs {
+				current.Packs[size] += packs * count
+			}
+		} else {
+			unit.Count = count
+			current.Contents = append(current.Contents, unit)
+		}
+		if current.Units == level.Capacity {
+			current.Count, current.Full = 1, true
+			add(current)
+			current = Container{}
+		}
+	}
+
+	for _, unit := range units {
+		count := unit.Count
+		if current.Units > 0 {
+			// Top up the container that is already open
+			take := min(count, level.Capacity-current.Units)
+			put(unit, take)
+			count -= take
+		}
+		if whole := count / level.Capacity; whole > 0 {
+			group := Container{Level: level.Name, Count: whole, Units: level.Capacity, Full: true}
+			if unit.Level == "" {
+				group.Packs = make(map[int]int, len(unit.Packs))
+				for size, packs := range unit.Packs {
+					group.Packs[size] = packs * level.Capacity
+				}
+			} else {
+				child := unit
+				child.Count = level.Capacity
+				group.Contents = []Container{child}
+			}
+			add(group)
+			count -= whole * level.Capacity
+		}
+		if count > 0 {
+			put(unit, count)
+		}
+	}
+	if current.Units > 0 {
+		current.Count = 1
+		add(current)
+	}
+	return containers
+}
+
+// sameContainer reports whether two container groups hold the same contents, so they can be merged
+func sameContainer(a, b Container) bool {
+	if a.Level != b.Level || a.Units != b.Units || a.Full != b.Full ||
+		len(a.Packs) != len(b.Packs) || len(a.Contents) != len(b.Contents) {
+		return false
+	}
+	for size, count := range a.Packs {
+		if b.Packs[size] != count {
+			return false
+		}
+	}
+	for i := range a.Contents {
+		if a.Contents[i].Count != b.Contents[i].Count || !sameContainer(a.Contents[i], b.Contents[i]) {
+			return false
+		}
+	}
+	return true
+}
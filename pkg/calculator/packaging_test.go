@@ -0,0 +1,140 @@
+package calculator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHierarchy(t *testing.T) {
+	testCases := []struct {
+		value       string
+		expected    Hierarchy
+		expectError bool
+	}{
+		{value: "", expected: nil},
+		{value: "case:10", expected: Hierarchy{{Name: "case", Capacity: 10}}},
+		{value: " case:10 , pallet:40 ", expected: Hierarchy{{Name: "case", Capacity: 10}, {Name: "pallet", Capacity: 40}}},
+		{value: "case", expectError: true},
+		{value: "case:ten", expectError: true},
+		{value: "case:0", expectError: true},
+		{value: ":10", expectError: true},
+		{value: "case:10,case:4", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			hierarchy, err := ParseHierarchy(tc.value)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %q", tc.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(hierarchy, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, hierarchy)
+			}
+			if hierarchy.String() != tc.expected.String() {
+				t.Errorf("Expected %q, got %q", tc.expected.String(), hierarchy.String())
+			}
+		})
+	}
+}
+
+func TestRollUp(t *testing.T) {
+	hierarchy := Hierarchy{{Name: "case", Capacity: 3}, {Name: "pallet", Capacity: 2}}
+	result := newPackResult(12001, map[int]int{5000: 2, 2000: 1, 250: 1})
+
+	packaging, err := RollUp(result, hierarchy)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedLevels := []LevelSummary{
+		{Name: "case", Capacity: 3, Containers: 2, Partial: 1},
+		{Name: "pallet", Capacity: 2, Containers: 1, Partial: 0},
+	}
+	if !reflect.DeepEqual(packaging.Levels, expectedLevels) {
+		t.Errorf("Expected levels %+v, got %+v", expectedLevels, packaging.Levels)
+	}
+
+	expected := []Container{{
+		Level: "pallet", Count: 1, Units: 2, Full: true,
+		Contents: []Container{
+			{Level: "case", Count: 1, Units: 3, Full: true, Packs: map[int]int{5000: 2, 2000: 1}},
+			{Level: "case", Count: 1, Units: 1, Packs: map[int]int{250: 1}},
+		},
+	}}
+	if !reflect.DeepEqual(packaging.Containers, expected) {
+		t.Errorf("Expected containers %+v, got %+v", expected, packaging.Containers)
+	}
+}
+
+func TestRollUpGroupsIdenticalContainers(t *testing.T) {
+	hierarchy := Hierarchy{{Name: "case", Capacity: 10}, {Name: "pallet", Capacity: 4}}
+	result := newPackResult(1000000, map[int]int{5000: 200})
+
+	packaging, err := RollUp(result, hierarchy)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []Container{{
+		Level: "pallet", Count: 5, Units: 4, Full: true,
+		Contents: []Container{{Level: "case", Count: 4, Units: 10, Full: true, Packs: map[int]int{5000: 10}}},
+	}}
+	if !reflect.DeepEqual(packaging.Containers, expected) {
+		t.Errorf("Expected containers %+v, got %+v", expected, packaging.Containers)
+	}
+}
+
+// TestRollUpIsMinimal checks every level uses the fewest containers with at most one partial container
+func TestRollUpIsMinimal(t *testing.T) {
+	hierarchies := []Hierarchy{
+		{{Name: "case", Capacity: 1}},
+		{{Name: "case", Capacity: 4}},
+		{{Name: "case", Capacity: 3}, {Name: "pallet", Capacity: 5}},
+		{{Name: "box", Capacity: 2}, {Name: "case", Capacity: 3}, {Name: "pallet", Capacity: 2}},
+	}
+
+	for _, hierarchy := range hierarchies {
+		for itemsOrdered := 1; itemsOrdered <= 3000; itemsOrdered += 37 {
+			result, err := CalculatePacks(itemsOrdered, []int{23, 31, 53})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			packaging, err := RollUp(result, hierarchy)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			units := result.TotalPacks
+			for i, level := range packaging.Levels {
+				containers := (units + hierarchy[i].Capacity - 1) / hierarchy[i].Capacity
+				if level.Containers != containers || level.Partial > 1 {
+					t.Errorf("%v, %d items: %s used %d containers with %d partial, expected %d",
+						hierarchy, itemsOrdered, level.Name, level.Containers, level.Partial, containers)
+				}
+				units = containers
+			}
+
+			packs := make(map[int]int)
+			countPacks(packaging.Containers, 1, packs)
+			if !reflect.DeepEqual(packs, result.PackCounts) {
+				t.Errorf("%v, %d items: containers hold %v, expected %v", hierarchy, itemsOrdered, packs, result.PackCounts)
+			}
+		}
+	}
+}
+
+// countPacks adds up the packs held by groups of containers
+func countPacks(containers []Container, multiplier int, packs map[int]int) {
+	for _, container := range containers {
+		for size, count := range container.Packs {
+			packs[size] += count * container.Count * multiplier
+		}
+		countPacks(container.Contents, container.Count*multiplier, packs)
+	}
+}
@@ -0,0 +1,334 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ErrPackTooLarge is returned when a single pack exceeds what a carrier accepts per parcel
+var ErrPackTooLarge = errors.New("pack does not fit in a parcel")
+
+// exactParcelPacks is the most packs whose parcel assignment is searched exhaustively,
+// larger shipments keep the first fit decreasing assignment
+const exactParcelPacks = 24
+
+// exactParcelNodes bounds the exhaustive search, which gives up and keeps the first fit decreasing assignment
+const exactParcelNodes = 1_000_000
+
+// PackSpec is the physical size of one pack
+type PackSpec struct {
+	Weight int // Grams
+	Volume int // Cubic millimetres
+	Cost   int // Cents of packaging, see CalculateCheapest
+}
+
+// ParcelLimits is what a carrier accepts in one parcel, 0 for no limit
+type ParcelLimits struct {
+	MaxWeight int // Grams
+	MaxVolume int // Cubic millimetres
+}
+
+// Parcel is a group of identical parcels and the packs in each of them
+type Parcel struct {
+	Count  int         // Number of identical parcels in the group
+	Packs  map[int]int // Packs per size in each parcel
+	Weight int         // Grams in each parcel
+	Volume int         // Cubic millimetres in each parcel
+}
+
+// ParcelPlan assigns the packs of a shipment to parcels
+type ParcelPlan struct {
+	Parcels      []Parcel // Groups of identical parcels, heaviest packs first
+	TotalParcels int      // Parcels across all groups
+	LowerBound   int      // No assignment uses fewer parcels than the total weight and volume allow
+	Optimal      bool     // Whether TotalParcels is proven to be the fewest
+}
+
+// PlanParcels assigns packs to the fewest parcels within the limits of a carrier. Packs are placed first fit
+// decreasing, by the larger of their share of the weight and volume limits. When that misses the lower bound,
+// shipments of up to exactParcelPacks packs are searched exhaustively for fewer parcels.
+func PlanParcels(packCounts map[int]int, specs map[int]PackSpec, limits ParcelLimits) (ParcelPlan, error) {
+	if limits.MaxWeight < 0 || limits.MaxVolume < 0 {
+		return ParcelPlan{}, fmt.Errorf("parcel limits must not be negative")
+	}
+
+	sizes := make([]int, 0, len(packCounts))
+	totalPacks := 0
+	for size, count := range packCounts {
+		if count <= 0 {
+			continue
+		}
+		spec, ok := specs[size]
+		if !ok {
+			return ParcelPlan{}, fmt.Errorf("no weight and dimensions for pack size %d", size)
+		}
+		if spec.Weight < 0 || spec.Volume < 0 {
+			return ParcelPlan{}, fmt.Errorf("weight and volume of pack size %d must not be negative", size)
+		}
+		if limits.fits(Parcel{}, spec) == 0 {
+			return ParcelPlan{}, fmt.Errorf("%w: pack size %d weighs %d g in %d mm³, parcels take at most %s",
+				ErrPackTooLarge, size, spec.Weight, spec.Volume, limits)
+		}
+		sizes = append(sizes, size)
+		totalPacks += count
+	}
+	if len(sizes) == 0 {
+		return ParcelPlan{Optimal: true}, nil
+	}
+
+	// Heaviest share of a parcel first, larger packs first among equals
+	sort.Slice(sizes, func(i, j int) bool {
+		a, b := limits.share(specs[sizes[i]]), limits.share(specs[sizes[j]])
+		if a != b {
+			return a > b
+		}
+		return sizes[i] > sizes[j]
+	})
+
+	plan := ParcelPlan{
+		Parcels:    firstFitDecreasing(packCounts, sizes, specs, limits),
+		LowerBound: limits.lowerBound(packCounts, specs),
+	}
+	plan.TotalParcels = countParcels(plan.Parcels)
+	plan.Optimal = plan.TotalParcels == plan.LowerBound
+
+	if !plan.Optimal && totalPacks <= exactParcelPacks {
+		parcels, optimal := exactParcels(packCounts, sizes, specs, limits, plan.LowerBound, plan.TotalParcels)
+		if parcels != nil {
+			plan.Parcels, plan.TotalParcels = parcels, countParcels(parcels)
+		}
+		plan.Optimal = optimal
+	}
+	return plan, nil
+}
+
+// countParcels adds up the parcels of all groups
+func countParcels(parcels []Parcel) int {
+	total := 0
+	for _, parcel := range parcels {
+		total += parcel.Count
+	}
+	return total
+}
+
+// String describes the limits for error messages
+func (l ParcelLimits) String() string {
+	weight, volume := "any weight", "any volume"
+	if l.MaxWeight > 0 {
+		weight = fmt.Sprintf("%d g", l.MaxWeight)
+	}
+	if l.MaxVolume > 0 {
+		volume = fmt.Sprintf("%d mm³", l.MaxVolume)
+	}
+	return weight + " in " + volume
+}
+
+// fits returns how many more packs of a spec fit in a parcel, math.MaxInt when the limits do not bind
+func (l ParcelLimits) fits(parcel Parcel, spec PackSpec) int {
+	fit := math.MaxInt
+	if l.MaxWeight > 0 && spec.Weight > 0 {
+		fit = min(fit, (l.MaxWeight-parcel.Weight)/spec.Weight)
+	}
+	if l.MaxVolume > 0 && spec.Volume > 0 {
+		fit = min(fit, (l.MaxVolume-parcel.Volume)/spec.Volume)
+	}
+	return max(fit, 0)
+}
+
+// share returns the larger of the fractions of a parcel's weight and volume a pack takes
+func (l ParcelLimits) share(spec PackSpec) float64 {
+	share := 0.0
+	if l.MaxWeight > 0 {
+		share = float64(spec.Weight) / float64(l.MaxWeight)
+	}
+	if l.MaxVolume > 0 {
+		share = max(share, float64(spec.Volume)/float64(l.MaxVolume))
+	}
+	return share
+}
+
+// lowerBound returns the parcels the total weight and volume need at least,
+// or the packs of a single size when fewer of them fit in a parcel than their share suggests
+func (l ParcelLimits) lowerBound(packCounts map[int]int, specs map[int]PackSpec) int {
+	weight, volume := 0, 0
+	bound := 1
+	for size, count := range packCounts {
+		if count > 0 {
+			weight += specs[size].Weight * count
+			volume += specs[size].Volume * count
+			perParcel := min(l.fits(Parcel{}, specs[size]), count)
+			bound = max(bound, (count+perParcel-1)/perParcel)
+		}
+	}
+
+	if l.MaxWeight > 0 {
+		bound = max(bound, (weight+l.MaxWeight-1)/l.MaxWeight)
+	}
+	if l.MaxVolume > 0 {
+		bound = max(bound, (volume+l.MaxVolume-1)/l.MaxVolume)
+	}
+	return bound
+}
+
+// add puts count packs of a size into every parcel of a group
+func (p Parcel) add(size int, spec PackSpec, count int) Parcel {
+	packs := make(map[int]int, len(p.Packs)+1)
+	for s, c := range p.Packs {
+		packs[s] = c
+	}
+	packs[size] += count
+	return Parcel{
+		Count:  p.Count,
+		Packs:  packs,
+		Weight: p.Weight + spec.Weight*count,
+		Volume: p.Volume + spec.Volume*count,
+	}
+}
+
+// firstFitDecreasing places every pack in the first parcel it fits, opening a new parcel when none has room.
+// Packs of a size are identical, so each group of identical parcels is topped up in bulk: some parcels of the
+// group take as many as fit, one takes the rest and the others none.
+func firstFitDecreasing(packCounts map[int]int, sizes []int, specs map[int]PackSpec, limits ParcelLimits) []Parcel {
+	var parcels []Parcel
+	for _, size := range sizes {
+		spec, remaining := specs[size], packCounts[size]
+
+		var placed []Parcel
+		for _, group := range parcels {
+			fit := min(limits.fits(group, spec), remaining)
+			if remaining == 0 || fit == 0 {
+				placed = append(placed, group)
+				continue
+			}
+			full := min(group.Count, remaining/fit)
+			if full > 0 {
+				topped := group.add(size, spec, fit)
+				topped.Count = full
+				placed = append(placed, topped)
+				remaining -= full * fit
+			}
+			if rest := group.Count - full; rest > 0 {
+				if remaining > 0 {
+					// remaining < fit here, so one parcel takes the rest
+					topped := group.add(size, spec, remaining)
+					topped.Count = 1
+					placed = append(placed, topped)
+					remaining = 0
+					rest--
+				}
+				if rest > 0 {
+					group.Count = rest
+					placed = append(placed, group)
+				}
+			}
+		}
+
+		if remaining > 0 {
+			perParcel := min(limits.fits(Parcel{}, spec), remaining)
+			if full := remaining / perParcel; full > 0 {
+				parcel := Parcel{Count: full}.add(size, spec, perParcel)
+				placed = append(placed, parcel)
+				remaining -= full * perParcel
+			}
+			if remaining > 0 {
+				placed = append(placed, Parcel{Count: 1}.add(size, spec, remaining))
+			}
+		}
+		parcels = mergeParcels(placed)
+	}
+	return parcels
+}
+
+// mergeParcels merges neighbouring groups of identical parcels
+func mergeParcels(parcels []Parcel) []Parcel {
+	var merged []Parcel
+	for _, parcel := range parcels {
+		if n := len(merged); n > 0 && sameParcel(merged[n-1], parcel) {
+			merged[n-1].Count += parcel.Count
+			continue
+		}
+		merged = append(merged, parcel)
+	}
+	return merged
+}
+
+// sameParcel reports whether two parcels hold the same packs
+func sameParcel(a, b Parcel) bool {
+	if len(a.Packs) != len(b.Packs) {
+		return false
+	}
+	for size, count := range a.Packs {
+		if b.Packs[size] != count {
+			return false
+		}
+	}
+	return true
+}
+
+// exactParcels searches for an assignment to fewer parcels than first fit decreasing found, trying every parcel
+// count from the lower bound up. It returns the parcels of the first assignment found, nil when there is none,
+// and whether the search completed, proving the result (or the first fit decreasing one) is the fewest.
+func exactParcels(packCounts map[int]int, sizes []int, specs map[int]PackSpec, limits ParcelLimits, lowerBound, found int) ([]Parcel, bool) {
+	var packs []int
+	for _, size := range sizes {
+		for range packCounts[size] {
+			packs = append(packs, size)
+		}
+	}
+
+	nodes := 0
+	for parcelCount := lowerBound; parcelCount < found; parcelCount++ {
+		bins := make([]Parcel, parcelCount)
+		firstBin := make([]int, len(packs)) // Identical packs go to the same or a later parcel
+
+		var place func(index int) bool
+		place = func(index int) bool {
+			if index == len(packs) {
+				return true
+			}
+			if nodes++; nodes > exactParcelNodes {
+				return false
+			}
+
+			size := packs[index]
+			start := 0
+			if index > 0 && packs[index-1] == size {
+				start = firstBin[index-1]
+			}
+			for i := start; i < len(bins); i++ {
+				// Parcels holding the same packs are interchangeable, so only the first of them is tried
+				if i > start && sameParcel(bins[i], bins[i-1]) {
+					continue
+				}
+				if limits.fits(bins[i], specs[size]) == 0 {
+					continue
+				}
+				previous := bins[i]
+				bins[i] = bins[i].add(size, specs[size], 1)
+				firstBin[index] = i
+				if place(index + 1) {
+					return true
+				}
+				bins[i] = previous
+			}
+			return false
+		}
+
+		if place(0) {
+			var parcels []Parcel
+			for _, bin := range bins {
+				if len(bin.Packs) > 0 {
+					bin.Count = 1
+					parcels = append(parcels, bin)
+				}
+			}
+			return mergeParcels(parcels), true
+		}
+		if nodes > exactParcelNodes {
+			return nil, false
+		}
+	}
+	return nil, true
+}
@@ -0,0 +1,192 @@
+package calculator
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestPlanParcels(t *testing.T) {
+	testCases := []struct {
+		name            string
+		packCounts      map[int]int
+		specs           map[int]PackSpec
+		limits          ParcelLimits
+		expectedParcels int
+		expectedGroups  int
+		expectOptimal   bool
+		expectError     error
+	}{
+		{
+			name:            "No limits",
+			packCounts:      map[int]int{5000: 2, 250: 1},
+			specs:           map[int]PackSpec{5000: {Weight: 5400, Volume: 60_000_000}, 250: {Weight: 300, Volume: 3_000_000}},
+			expectedParcels: 1,
+			expectedGroups:  1,
+			expectOptimal:   true,
+		},
+		{
+			name:            "Weight limit",
+			packCounts:      map[int]int{3: 3, 2: 3},
+			specs:           map[int]PackSpec{3: {Weight: 3}, 2: {Weight: 2}},
+			limits:          ParcelLimits{MaxWeight: 5},
+			expectedParcels: 3,
+			expectedGroups:  1,
+			expectOptimal:   true,
+		},
+		{
+			name:            "Volume limit",
+			packCounts:      map[int]int{5000: 3, 1000: 2},
+			specs:           map[int]PackSpec{5000: {Weight: 5400, Volume: 60_000_000}, 1000: {Weight: 1100, Volume: 12_000_000}},
+			limits:          ParcelLimits{MaxWeight: 31_500, MaxVolume: 100_000_000},
+			expectedParcels: 3,
+			expectedGroups:  2,
+			expectOptimal:   true,
+		},
+		{
+			name:            "First fit decreasing misses the fewest parcels",
+			packCounts:      map[int]int{50: 1, 40: 2, 30: 1, 20: 2},
+			specs:           map[int]PackSpec{50: {Weight: 5}, 40: {Weight: 4}, 30: {Weight: 3}, 20: {Weight: 2}},
+			limits:          ParcelLimits{MaxWeight: 10},
+			expectedParcels: 2,
+			expectedGroups:  2,
+			expectOptimal:   true,
+		},
+		{
+			name:            "Many identical packs",
+			packCounts:      map[int]int{5000: 200000},
+			specs:           map[int]PackSpec{5000: {Weight: 5400, Volume: 60_000_000}},
+			limits:          ParcelLimits{MaxWeight: 31_500},
+			expectedParcels: 40000,
+			expectedGroups:  1,
+			expectOptimal:   true,
+		},
+		{
+			name:        "Pack heavier than a parcel",
+			packCounts:  map[int]int{5000: 1},
+			specs:       map[int]PackSpec{5000: {Weight: 40_000}},
+			limits:      ParcelLimits{MaxWeight: 31_500},
+			expectError: ErrPackTooLarge,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan, err := PlanParcels(tc.packCounts, tc.specs, tc.limits)
+			if tc.expectError != nil {
+				if !errors.Is(err, tc.expectError) {
+					t.Errorf("Expected %v, got %v", tc.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if plan.TotalParcels != tc.expectedParcels {
+				t.Errorf("Expected %d parcels, got %d: %+v", tc.expectedParcels, plan.TotalParcels, plan.Parcels)
+			}
+			if len(plan.Parcels) != tc.expectedGroups {
+				t.Errorf("Expected %d groups of parcels, got %d: %+v", tc.expectedGroups, len(plan.Parcels), plan.Parcels)
+			}
+			if plan.Optimal != tc.expectOptimal {
+				t.Errorf("Expected optimal %v, got %v", tc.expectOptimal, plan.Optimal)
+			}
+			checkParcels(t, plan, tc.packCounts, tc.specs, tc.limits)
+		})
+	}
+}
+
+// checkParcels checks that a plan ships every pack once within the limits
+func checkParcels(t *testing.T, plan ParcelPlan, packCounts map[int]int, specs map[int]PackSpec, limits ParcelLimits) {
+	t.Helper()
+
+	shipped := make(map[int]int)
+	total := 0
+	for _, parcel := range plan.Parcels {
+		weight, volume := 0, 0
+		for size, count := range parcel.Packs {
+			shipped[size] += count * parcel.Count
+			weight += specs[size].Weight * count
+			volume += specs[size].Volume * count
+		}
+		if weight != parcel.Weight || volume != parcel.Volume {
+			t.Errorf("Parcel %+v should weigh %d g in %d mm³", parcel, weight, volume)
+		}
+		if limits.MaxWeight > 0 && weight > limits.MaxWeight || limits.MaxVolume > 0 && volume > limits.MaxVolume {
+			t.Errorf("Parcel %+v exceeds %s", parcel, limits)
+		}
+		total += parcel.Count
+	}
+	if total != plan.TotalParcels {
+		t.Errorf("Expected %d parcels in the groups, got %d", plan.TotalParcels, total)
+	}
+	for size, count := range packCounts {
+		if shipped[size] != count {
+			t.Errorf("Expected %d packs of %d shipped, got %d", count, size, shipped[size])
+		}
+	}
+}
+
+// fewestParcels finds the fewest parcels by trying every partition of the packs
+func fewestParcels(packs []int, specs map[int]PackSpec, limits ParcelLimits) int {
+	best := len(packs)
+	var bins []Parcel
+
+	var walk func(index int)
+	walk = func(index int) {
+		if len(bins) >= best {
+			return
+		}
+		if index == len(packs) {
+			best = len(bins)
+			return
+		}
+		size := packs[index]
+		for i := range bins {
+			if limits.fits(bins[i], specs[size]) > 0 {
+				previous := bins[i]
+				bins[i] = bins[i].add(size, specs[size], 1)
+				walk(index + 1)
+				bins[i] = previous
+			}
+		}
+		bins = append(bins, Parcel{}.add(size, specs[size], 1))
+		walk(index + 1)
+		bins = bins[:len(bins)-1]
+	}
+
+	walk(0)
+	return best
+}
+
+// TestPlanParcelsMatchesBruteForce checks the plans of small random shipments against every partition
+func TestPlanParcelsMatchesBruteForce(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	sizes := []int{7, 5, 3}
+
+	for range 300 {
+		specs := make(map[int]PackSpec)
+		packCounts := make(map[int]int)
+		var packs []int
+		for _, size := range sizes {
+			specs[size] = PackSpec{Weight: 1 + random.Intn(9), Volume: 1 + random.Intn(9)}
+			packCounts[size] = random.Intn(4)
+			for range packCounts[size] {
+				packs = append(packs, size)
+			}
+		}
+		limits := ParcelLimits{MaxWeight: 10 + random.Intn(10), MaxVolume: 10 + random.Intn(10)}
+
+		plan, err := PlanParcels(packCounts, specs, limits)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		checkParcels(t, plan, packCounts, specs, limits)
+
+		if expected := fewestParcels(packs, specs, limits); plan.TotalParcels != expected || !plan.Optimal {
+			t.Errorf("%v with %v in %s: expected %d parcels, got %d (optimal %v)",
+				packCounts, specs, limits, expected, plan.TotalParcels, plan.Optimal)
+		}
+	}
+}
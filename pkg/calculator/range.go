@@ -0,0 +1,83 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// ErrInvalidRange is returned for ranges of orders CalculateRange does not calculate
+var ErrInvalidRange = errors.New("invalid range")
+
+// CalculateRange calculates the optimal packs of every order from first to last items and passes them to
+// visit in order, stopping at the first error visit returns. The results are those of CalculatePacksWithOptions,
+// but the DP table only depends on the pack sizes and limits, not on the order, so one table covering the
+// largest order serves them all. Orders must be within the safety threshold, less a largest pack.
+func CalculateRange(first, last int, availablePackSizes []int, options Options, visit func(PackResult) error) error {
+	if first <= 0 || last < first {
+		return fmt.Errorf("%w of orders from %d to %d items", ErrInvalidRange, first, last)
+	}
+
+	packSizes, err := preparePackSizes(last, availablePackSizes)
+	if err != nil {
+		return err
+	}
+	if err := options.validate(); err != nil {
+		return err
+	}
+	if last > safetyThreshold-packSizes[0] {
+		return fmt.Errorf("%w: ranges are only available for orders of up to %d items with a largest pack of %d, got %d",
+			ErrInvalidRange, safetyThreshold-packSizes[0], packSizes[0], last)
+	}
+
+	orders := func(yield func(int) bool) {
+		for items := first; items <= last; items++ {
+			if !yield(items) {
+				return
+			}
+		}
+	}
+	return calculateOrders(orders, packSizes, options, visit)
+}
+
+// calculateOrders calculates the optimal packs of orders from one DP table and passes them to visit in the order
+// given. orders is walked twice, so orders the limits rule out fail before any result is passed on.
+// packSizes must be sorted in descending order.
+func calculateOrders(orders iter.Seq[int], packSizes []int, options Options, visit func(PackResult) error) error {
+	// The DP covers every total any order may need, the allowance varies with the order
+	limit, last := 0, 0
+	for items := range orders {
+		p, err := newIntProblem(items, packSizes, options)
+		if err != nil {
+			return err
+		}
+		limit = max(limit, p.limit(options))
+		last = max(last, items)
+	}
+	if last == 0 {
+		return nil
+	}
+
+	p, err := newIntProblem(last, packSizes, options)
+	if err != nil {
+		return err
+	}
+	table := newSolver(p, options).solve(limit)
+
+	for items := range orders {
+		p, err := newIntProblem(items, packSizes, options)
+		if err != nil {
+			return err
+		}
+		fixed, _ := p.fixedPacks()
+
+		total := table.bestTotal(p, options)
+		if total == unreachable {
+			return fmt.Errorf("%w: no total of at least %d items can be packed", ErrInfeasible, items)
+		}
+		if err := visit(newPackResult(items, withFixedPacks(table.reconstruct(total, nil), fixed))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package calculator
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestCalculateRangeMatchesCalculatePacks checks every order of a range against calculating it on its own
+func TestCalculateRangeMatchesCalculatePacks(t *testing.T) {
+	testCases := []struct {
+		name      string
+		packSizes []int
+		options   func(*Options)
+	}{
+		{name: "Default", packSizes: []int{250, 500, 1000, 2000, 5000}},
+		{name: "Smaller packs", packSizes: []int{23, 31, 53}, options: func(o *Options) { o.TieBreak = TieBreakSmallerPacks }},
+		{name: "Distinct before packs", packSizes: []int{3, 5, 7, 11}, options: func(o *Options) { o.DistinctRule = DistinctRuleBeforePacks }},
+		{name: "Under-ship", packSizes: []int{250, 500, 1000}, options: func(o *Options) { o.Fulfilment = FulfilmentUnderShip }},
+		{name: "Tolerance", packSizes: []int{250, 500, 1000}, options: func(o *Options) { o.Tolerance = Tolerance{Percent: 10} }},
+		{name: "Limits", packSizes: []int{250, 500, 1000}, options: func(o *Options) {
+			o.Limits = map[int]PackLimit{250: {Min: 1, Max: 3}, 1000: {Min: 0, Max: 4}}
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options := DefaultOptions()
+			if tc.options != nil {
+				tc.options(&options)
+			}
+
+			first, last := 1, 2000
+			items := first
+			err := CalculateRange(first, last, tc.packSizes, options, func(result PackResult) error {
+				expected, err := CalculatePacksWithOptions(items, tc.packSizes, options)
+				if err != nil {
+					t.Fatalf("%d items: unexpected error: %v", items, err)
+				}
+				if formatPackCounts(result.PackCounts) != formatPackCounts(expected.PackCounts) || result.ExcessItems != expected.ExcessItems ||
+					result.ShortItems != expected.ShortItems {
+					t.Fatalf("%d items: expected %v, got %v", items, expected, result)
+				}
+				items++
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if items != last+1 {
+				t.Errorf("Expected %d results, got %d", last-first+1, items-first)
+			}
+		})
+	}
+}
+
+func TestCalculateRangeErrors(t *testing.T) {
+	visit := func(PackResult) error { return nil }
+
+	if err := CalculateRange(10, 5, []int{250}, DefaultOptions(), visit); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("Expected %v for an empty range, got %v", ErrInvalidRange, err)
+	}
+	if err := CalculateRange(1, safetyThreshold, []int{250}, DefaultOptions(), visit); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("Expected %v for a range beyond the safety threshold, got %v", ErrInvalidRange, err)
+	}
+
+	options := DefaultOptions()
+	options.Limits = map[int]PackLimit{250: {Min: 0, Max: 2}}
+	calls := 0
+	err := CalculateRange(1, 1000, []int{250}, options, func(PackResult) error { calls++; return nil })
+	if !errors.Is(err, ErrInfeasible) || calls != 0 {
+		t.Errorf("Expected %v before any result, got %v after %d results", ErrInfeasible, err, calls)
+	}
+
+	stop := errors.New("stop")
+	calls = 0
+	err = CalculateRange(1, 1000, []int{250}, DefaultOptions(), func(PackResult) error {
+		calls++
+		if calls == 3 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || calls != 3 {
+		t.Errorf("Expected to stop after 3 results, got %v after %d", err, calls)
+	}
+}
@@ -0,0 +1,223 @@
+package calculator
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxRecommendedSizes is the largest pack set RecommendPackSets searches for
+const MaxRecommendedSizes = 10
+
+// maxGeneratedCandidates caps the candidate sizes RecommendPackSets takes from the most frequent order quantities
+const maxGeneratedCandidates = 40
+
+// minBeamWidth is the fewest pack sets RecommendPackSets extends at each step of its search
+const minBeamWidth = 10
+
+// MaxRecommendedOrders is the most orders RecommendPackSets evaluates pack sets with
+const MaxRecommendedOrders = 10000
+
+// MaxRecommendedItems is the largest order RecommendPackSets evaluates pack sets with
+const MaxRecommendedItems = 100000
+
+// maxRecommendationWork caps the DP totals times pack sizes RecommendPackSets covers over all the sets it
+// evaluates, about a second of work. The search stops with the sets evaluated so far once it is spent.
+const maxRecommendationWork = 50 * safetyThreshold
+
+// quantityWork is the DP totals times pack sizes that preparing and reading the result of one order quantity
+// costs about as much as
+const quantityWork = 100
+
+// PackSetMetrics is how well a set of pack sizes packs a set of orders
+type PackSetMetrics struct {
+	PackSizes   []int // Largest first
+	ExcessItems int   // Excess items over all orders
+	ShortItems  int   // Items short over all orders, when under-shipping
+	TotalPacks  int   // Packs over all orders
+	ExactOrders int   // Orders packed without excess or short items
+}
+
+// betterThan reports whether a set packs the orders better: fewer excess or short items, then fewer packs, then
+// fewer sizes to manufacture, then larger sizes
+func (m PackSetMetrics) betterThan(other PackSetMetrics) bool {
+	if m.ExcessItems+m.ShortItems != other.ExcessItems+other.ShortItems {
+		return m.ExcessItems+m.ShortItems < other.ExcessItems+other.ShortItems
+	}
+	if m.TotalPacks != other.TotalPacks {
+		return m.TotalPacks < other.TotalPacks
+	}
+	if len(m.PackSizes) != len(other.PackSizes) {
+		return len(m.PackSizes) < len(other.PackSizes)
+	}
+	return slices.Compare(m.PackSizes, other.PackSizes) > 0
+}
+
+// Recommendation is the pack sets that pack a set of orders best
+type Recommendation struct {
+	Sets       []PackSetMetrics // Best first
+	Orders     int              // Orders evaluated
+	Candidates []int            // Sizes the sets were chosen from, largest first
+	Evaluated  int              // Pack sets evaluated
+	Truncated  bool             // The search stopped early to stay within its work budget
+}
+
+// RecommendPackSets searches for the sets of at most maxSizes pack sizes that pack the orders with the least
+// excess, or shortfall when under-shipping, then the fewest packs, and returns the best top sets. Sets are chosen
+// from the candidate sizes, or from the most frequent order quantities without candidates, as a pack of an order's
+// exact quantity ships it without excess. Every set is evaluated by packing every order with the options, like
+// CalculatePacksWithOptions. The search is a beam search: sets of one size more are built from the best sets of
+// the last step, so it is exhaustive while the beam holds every set and a heuristic beyond. It stops early, with the
+// best sets evaluated so far, once it has covered as many DP totals as maxRecommendationWork allows.
+func RecommendPackSets(orders []int, maxSizes int, candidates []int, top int, options Options) (Recommendation, error) {
+	if len(orders) == 0 {
+		return Recommendation{}, fmt.Errorf("no orders to recommend pack sizes for")
+	}
+	if maxSizes <= 0 || maxSizes > MaxRecommendedSizes {
+		return Recommendation{}, fmt.Errorf("number of pack sizes must be between 1 and %d, got %d", MaxRecommendedSizes, maxSizes)
+	}
+	if top <= 0 {
+		return Recommendation{}, fmt.Errorf("number of recommendations must be greater than 0, got %d", top)
+	}
+	if len(orders) > MaxRecommendedOrders {
+		return Recommendation{}, fmt.Errorf("%w: recommendations are only available for up to %d orders, got %d",
+			ErrInvalidRange, MaxRecommendedOrders, len(orders))
+	}
+	if len(options.Limits) > 0 {
+		return Recommendation{}, fmt.Errorf("%w: pack limits cannot be applied to recommended pack sizes", ErrInvalidLimit)
+	}
+	if err := options.validate(); err != nil {
+		return Recommendation{}, err
+	}
+
+	// Orders of the same quantity pack alike, each distinct quantity is evaluated once and weighed by its frequency
+	frequency := make(map[int]int)
+	for _, itemsOrdered := range orders {
+		if itemsOrdered <= 0 {
+			return Recommendation{}, fmt.Errorf("items of every order must be greater than 0, got %d", itemsOrdered)
+		}
+		if itemsOrdered > MaxRecommendedItems {
+			return Recommendation{}, fmt.Errorf("%w: recommendations are only available for orders of up to %d items, got %d",
+				ErrInvalidRange, MaxRecommendedItems, itemsOrdered)
+		}
+		frequency[itemsOrdered]++
+	}
+	quantities := make([]int, 0, len(frequency))
+	for itemsOrdered := range frequency {
+		quantities = append(quantities, itemsOrdered)
+	}
+	sort.Ints(quantities)
+
+	if len(candidates) == 0 {
+		candidates = frequentQuantities(quantities, frequency, maxGeneratedCandidates)
+	}
+	candidates, err := preparePackSizes(1, candidates)
+	if err != nil {
+		return Recommendation{}, fmt.Errorf("candidate sizes: %w", err)
+	}
+	candidates = slices.Compact(candidates)
+	if largest := quantities[len(quantities)-1]; largest > safetyThreshold-candidates[0] {
+		return Recommendation{}, fmt.Errorf("%w: recommendations are only available for orders of up to %d items with a largest candidate of %d, got %d",
+			ErrInvalidRange, safetyThreshold-candidates[0], candidates[0], largest)
+	}
+
+	recommendation := Recommendation{Orders: len(orders), Candidates: candidates}
+	evaluated := make(map[string]PackSetMetrics)
+
+	// evaluate packs every order with a set of sizes, largest first
+	evaluate := func(packSizes []int) (PackSetMetrics, error) {
+		metrics := PackSetMetrics{PackSizes: packSizes}
+		i := 0
+		err := calculateOrders(slices.Values(quantities), packSizes, options, func(result PackResult) error {
+			n := frequency[quantities[i]]
+			metrics.ExcessItems += n * result.ExcessItems
+			metrics.ShortItems += n * result.ShortItems
+			metrics.TotalPacks += n * result.TotalPacks
+			if result.ExcessItems == 0 && result.ShortItems == 0 {
+				metrics.ExactOrders += n
+			}
+			i++
+			return nil
+		})
+		return metrics, err
+	}
+
+	// work is what evaluating a set costs: a DP over every total of the largest order for each size,
+	// and preparing and reading the result of every quantity
+	work := func(packSizes []int) int {
+		return (quantities[len(quantities)-1]+packSizes[0])*len(packSizes) + quantityWork*len(quantities)
+	}
+
+	beamWidth := max(top, minBeamWidth)
+	beam := [][]int{nil}
+	spent := 0
+search:
+	for range maxSizes {
+		var next []PackSetMetrics
+		for _, packSizes := range beam {
+			for _, size := range candidates {
+				if slices.Contains(packSizes, size) {
+					continue
+				}
+				extended := append(slices.Clone(packSizes), size)
+				sort.Sort(sort.Reverse(sort.IntSlice(extended)))
+				key := packSetKey(extended)
+				if _, ok := evaluated[key]; ok {
+					continue
+				}
+				if spent += work(extended); spent > maxRecommendationWork {
+					recommendation.Truncated = true
+					break search
+				}
+
+				metrics, err := evaluate(extended)
+				if err != nil {
+					return Recommendation{}, err
+				}
+				evaluated[key] = metrics
+				next = append(next, metrics)
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+
+		sort.Slice(next, func(i, j int) bool {
+			return next[i].betterThan(next[j])
+		})
+		beam = beam[:0]
+		for _, metrics := range next[:min(beamWidth, len(next))] {
+			beam = append(beam, metrics.PackSizes)
+		}
+	}
+
+	for _, metrics := range evaluated {
+		recommendation.Sets = append(recommendation.Sets, metrics)
+	}
+	sort.Slice(recommendation.Sets, func(i, j int) bool {
+		return recommendation.Sets[i].betterThan(recommendation.Sets[j])
+	})
+	recommendation.Sets = recommendation.Sets[:min(top, len(recommendation.Sets))]
+	recommendation.Evaluated = len(evaluated)
+	return recommendation, nil
+}
+
+// frequentQuantities returns the n most frequent order quantities, the smaller first among equally frequent ones
+func frequentQuantities(quantities []int, frequency map[int]int, n int) []int {
+	frequent := slices.Clone(quantities)
+	sort.SliceStable(frequent, func(i, j int) bool {
+		return frequency[frequent[i]] > frequency[frequent[j]]
+	})
+	return frequent[:min(n, len(frequent))]
+}
+
+// packSetKey identifies a set of pack sizes sorted largest first
+func packSetKey(packSizes []int) string {
+	parts := make([]string, len(packSizes))
+	for i, size := range packSizes {
+		parts[i] = strconv.Itoa(size)
+	}
+	return strings.Join(parts, ",")
+}
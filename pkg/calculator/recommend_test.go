@@ -0,0 +1,121 @@
+package calculator
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRecommendPackSets(t *testing.T) {
+	var orders []int
+	for size, count := range map[int]int{250: 5, 500: 3, 750: 2, 1000: 1} {
+		for range count {
+			orders = append(orders, size)
+		}
+	}
+
+	recommendation, err := RecommendPackSets(orders, 2, []int{1000, 750, 500, 250}, 3, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []PackSetMetrics{
+		{PackSizes: []int{500, 250}, TotalPacks: 14, ExactOrders: 11},
+		{PackSizes: []int{750, 250}, TotalPacks: 15, ExactOrders: 11},
+		{PackSizes: []int{1000, 250}, TotalPacks: 18, ExactOrders: 11},
+	}
+	if !reflect.DeepEqual(recommendation.Sets, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, recommendation.Sets)
+	}
+	if recommendation.Orders != 11 || recommendation.Evaluated != 4+6 {
+		t.Errorf("Expected 11 orders and 10 sets evaluated, got %d and %d", recommendation.Orders, recommendation.Evaluated)
+	}
+
+	// The metrics match calculating every order with the recommended sizes
+	for _, set := range recommendation.Sets {
+		var metrics PackSetMetrics
+		for _, itemsOrdered := range orders {
+			result, err := CalculatePacksWithOptions(itemsOrdered, set.PackSizes, DefaultOptions())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			metrics.ExcessItems += result.ExcessItems
+			metrics.TotalPacks += result.TotalPacks
+		}
+		if metrics.ExcessItems != set.ExcessItems || metrics.TotalPacks != set.TotalPacks {
+			t.Errorf("%v: expected %d excess in %d packs, got %d in %d",
+				set.PackSizes, metrics.ExcessItems, metrics.TotalPacks, set.ExcessItems, set.TotalPacks)
+		}
+	}
+}
+
+func TestRecommendPackSetsFromOrders(t *testing.T) {
+	orders := []int{300, 300, 300, 700, 1000, 333}
+
+	recommendation, err := RecommendPackSets(orders, 2, nil, 1, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(recommendation.Candidates, []int{1000, 700, 333, 300}) {
+		t.Errorf("Expected the order quantities as candidates, got %v", recommendation.Candidates)
+	}
+	best := recommendation.Sets[0]
+	if !reflect.DeepEqual(best.PackSizes, []int{700, 333}) || best.ExcessItems != 132 || best.TotalPacks != 7 {
+		t.Errorf("Expected 700 and 333 with 132 excess in 7 packs, got %+v", best)
+	}
+}
+
+func TestRecommendPackSetsErrors(t *testing.T) {
+	options := DefaultOptions()
+	options.Limits = map[int]PackLimit{250: {Max: 1}}
+
+	testCases := []struct {
+		name       string
+		orders     []int
+		maxSizes   int
+		candidates []int
+		top        int
+		options    Options
+	}{
+		{"no orders", nil, 2, nil, 1, DefaultOptions()},
+		{"no sizes", []int{250}, 0, nil, 1, DefaultOptions()},
+		{"too many sizes", []int{250}, MaxRecommendedSizes + 1, nil, 1, DefaultOptions()},
+		{"no recommendations", []int{250}, 2, nil, 0, DefaultOptions()},
+		{"pack limits", []int{250}, 2, nil, 1, options},
+		{"empty order", []int{0}, 2, nil, 1, DefaultOptions()},
+		{"invalid candidate", []int{250}, 2, []int{-250}, 1, DefaultOptions()},
+	}
+
+	for _, tc := range testCases {
+		if _, err := RecommendPackSets(tc.orders, tc.maxSizes, tc.candidates, tc.top, tc.options); err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		}
+	}
+}
+
+// TestRecommendPackSetsWorkBudget checks that a search too large for its work budget stops early with the sets
+// evaluated so far, and that orders beyond the limits are rejected
+func TestRecommendPackSetsWorkBudget(t *testing.T) {
+	var orders []int
+	for i := range 2000 {
+		orders = append(orders, 1+i*7919%20000)
+	}
+
+	recommendation, err := RecommendPackSets(orders, 5, nil, 5, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !recommendation.Truncated {
+		t.Errorf("Expected the search to stop early, it evaluated %d sets", recommendation.Evaluated)
+	}
+	if len(recommendation.Sets) != 5 {
+		t.Errorf("Expected 5 sets from the search so far, got %d", len(recommendation.Sets))
+	}
+
+	if _, err := RecommendPackSets([]int{MaxRecommendedItems + 1}, 1, nil, 1, DefaultOptions()); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("Expected %v for an order beyond %d items, got %v", ErrInvalidRange, MaxRecommendedItems, err)
+	}
+	if _, err := RecommendPackSets(make([]int, MaxRecommendedOrders+1), 1, nil, 1, DefaultOptions()); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("Expected %v for more than %d orders, got %v", ErrInvalidRange, MaxRecommendedOrders, err)
+	}
+}
@@ -0,0 +1,131 @@
+package calculator
+
+import (
+	"fmt"
+)
+
+// SimulationTotals adds up the packings of a set of orders
+type SimulationTotals struct {
+	TotalPacks  int
+	TotalItems  int
+	ExcessItems int
+	ShortItems  int
+	ExactOrders int // Orders packed without excess or short items
+}
+
+// add adds the packing of an order to the totals
+func (t *SimulationTotals) add(result PackResult) {
+	t.TotalPacks += result.TotalPacks
+	t.TotalItems += result.TotalItems
+	t.ExcessItems += result.ExcessItems
+	t.ShortItems += result.ShortItems
+	if result.ExcessItems == 0 && result.ShortItems == 0 {
+		t.ExactOrders++
+	}
+}
+
+// OrderComparison is the packing of one order with the current and the candidate pack sizes
+type OrderComparison struct {
+	ItemsOrdered int
+	Current      PackResult
+	Candidate    PackResult
+	ExcessSaved  int  // Excess items the candidate sizes save, negative if they add excess
+	PacksSaved   int  // Packs the candidate sizes save, negative if they need more
+	Changed      bool // Whether the candidate sizes pack the order differently
+}
+
+// Simulation compares packing a set of orders with the current and with candidate pack sizes
+type Simulation struct {
+	CurrentPackSizes   []int             // Largest first
+	CandidatePackSizes []int             // Largest first
+	Orders             []OrderComparison // In the order given
+	Current            SimulationTotals
+	Candidate          SimulationTotals
+
+	ExcessSaved int // Excess items the candidate sizes save over all orders
+	PacksSaved  int // Packs the candidate sizes save over all orders
+	Improved    int // Orders the candidate sizes pack better by the business rules
+	Worsened    int // Orders the candidate sizes pack worse by the business rules
+}
+
+// SimulatePackSets packs every order with the current and the candidate pack sizes, both with the same options,
+// and compares them order by order with Options.Less, packings tied on every business rule counting as neither
+// better nor worse. Orders of the same quantity are calculated once.
+func SimulatePackSets(orders []int, currentPackSizes, candidatePackSizes []int, options Options) (Simulation, error) {
+	if len(orders) == 0 {
+		return Simulation{}, fmt.Errorf("no orders to simulate")
+	}
+	currentPackSizes, err := preparePackSizes(1, currentPackSizes)
+	if err != nil {
+		return Simulation{}, err
+	}
+	candidatePackSizes, err = preparePackSizes(1, candidatePackSizes)
+	if err != nil {
+		return Simulation{}, fmt.Errorf("candidate pack sizes: %w", err)
+	}
+
+	type packings struct{ current, candidate PackResult }
+	calculated := make(map[int]packings)
+
+	simulation := Simulation{
+		CurrentPackSizes:   currentPackSizes,
+		CandidatePackSizes: candidatePackSizes,
+		Orders:             make([]OrderComparison, 0, len(orders)),
+	}
+	for _, itemsOrdered := range orders {
+		if itemsOrdered <= 0 {
+			return Simulation{}, fmt.Errorf("items of every order must be greater than 0, got %d", itemsOrdered)
+		}
+
+		packed, ok := calculated[itemsOrdered]
+		if !ok {
+			current, err := CalculatePacksWithOptions(itemsOrdered, currentPackSizes, options)
+			if err != nil {
+				return Simulation{}, fmt.Errorf("order of %d items with the current pack sizes: %w", itemsOrdered, err)
+			}
+			candidate, err := CalculatePacksWithOptions(itemsOrdered, candidatePackSizes, options)
+			if err != nil {
+				return Simulation{}, fmt.Errorf("order of %d items with the candidate pack sizes: %w", itemsOrdered, err)
+			}
+			packed = packings{current: current, candidate: candidate}
+			calculated[itemsOrdered] = packed
+		}
+
+		simulation.Orders = append(simulation.Orders, OrderComparison{
+			ItemsOrdered: itemsOrdered,
+			Current:      packed.current,
+			Candidate:    packed.candidate,
+			ExcessSaved:  packed.current.ExcessItems - packed.candidate.ExcessItems,
+			PacksSaved:   packed.current.TotalPacks - packed.candidate.TotalPacks,
+			Changed:      !samePacks(packed.current.PackCounts, packed.candidate.PackCounts),
+		})
+		simulation.Current.add(packed.current)
+		simulation.Candidate.add(packed.candidate)
+		switch {
+		case options.Tied(packed.candidate, packed.current):
+		case options.Less(packed.candidate, packed.current):
+			simulation.Improved++
+		case options.Less(packed.current, packed.candidate):
+			simulation.Worsened++
+		}
+	}
+
+	simulation.ExcessSaved = simulation.Current.ExcessItems - simulation.Candidate.ExcessItems
+	simulation.PacksSaved = simulation.Current.TotalPacks - simulation.Candidate.TotalPacks
+	return simulation, nil
+}
+
+// samePacks reports whether two packings hold as many packs of every size
+func samePacks(a, b map[int]int) bool {
+	for size, count := range a {
+		if b[size] != count {
+			return false
+		}
+	}
+	for size, count := range b {
+		if a[size] != count {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package calculator
+
+import (
+	"testing"
+)
+
+func TestSimulatePackSets(t *testing.T) {
+	current := []int{250, 500, 1000, 2000, 5000}
+	candidate := []int{250, 500, 750, 1000, 2000, 5000}
+	orders := []int{751, 750, 1, 751, 12001}
+
+	simulation, err := SimulatePackSets(orders, current, candidate, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		current, candidate string
+		excessSaved        int
+		packsSaved         int
+		changed            bool
+	}{
+		{"1x1000", "1x1000", 0, 0, false},
+		{"1x500 1x250", "1x750", 0, 1, true},
+		{"1x250", "1x250", 0, 0, false},
+		{"1x1000", "1x1000", 0, 0, false},
+		{"2x5000 1x2000 1x250", "2x5000 1x2000 1x250", 0, 0, false},
+	}
+	if len(simulation.Orders) != len(expected) {
+		t.Fatalf("Expected %d orders, got %d", len(expected), len(simulation.Orders))
+	}
+	for i, exp := range expected {
+		order := simulation.Orders[i]
+		if order.ItemsOrdered != orders[i] || formatPackCounts(order.Current.PackCounts) != exp.current ||
+			formatPackCounts(order.Candidate.PackCounts) != exp.candidate ||
+			order.ExcessSaved != exp.excessSaved || order.PacksSaved != exp.packsSaved || order.Changed != exp.changed {
+			t.Errorf("Order %d: expected %s then %s saving %d excess and %d packs, got %s then %s saving %d and %d",
+				orders[i], exp.current, exp.candidate, exp.excessSaved, exp.packsSaved,
+				formatPackCounts(order.Current.PackCounts), formatPackCounts(order.Candidate.PackCounts),
+				order.ExcessSaved, order.PacksSaved)
+		}
+	}
+
+	if simulation.Improved != 1 || simulation.Worsened != 0 || simulation.PacksSaved != 1 || simulation.ExcessSaved != 0 {
+		t.Errorf("Expected 1 improved order saving 1 pack, got %d improved, %d worsened, %d packs and %d excess saved",
+			simulation.Improved, simulation.Worsened, simulation.PacksSaved, simulation.ExcessSaved)
+	}
+	if simulation.Current.ExactOrders != 1 || simulation.Candidate.ExactOrders != 1 ||
+		simulation.Current.TotalPacks != 9 || simulation.Current.ExcessItems != 4*249 {
+		t.Errorf("Unexpected current totals %+v and candidate totals %+v", simulation.Current, simulation.Candidate)
+	}
+
+	// Removing a size makes orders worse
+	simulation, err = SimulatePackSets([]int{500, 1250}, current, []int{250, 1000, 2000, 5000}, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if simulation.Worsened != 1 || simulation.PacksSaved != -1 {
+		t.Errorf("Expected 1 worsened order needing 1 more pack, got %d worsened and %d packs saved",
+			simulation.Worsened, simulation.PacksSaved)
+	}
+
+	if _, err := SimulatePackSets(nil, current, candidate, DefaultOptions()); err == nil {
+		t.Errorf("Expected error without orders")
+	}
+	if _, err := SimulatePackSets([]int{0}, current, candidate, DefaultOptions()); err == nil {
+		t.Errorf("Expected error for an empty order")
+	}
+}
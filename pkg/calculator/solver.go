@@ -0,0 +1,391 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+)
+
+// Algorithms reported in explanations of CalculatePacksWithOptions, and by SelectAlgorithm
+const (
+	AlgorithmExact = "exact-dp"
+	AlgorithmBulk  = "bulk-exact-dp"
+	AlgorithmBig   = "big-bulk-exact-dp" // CalculatePacksBig for orders beyond int
+)
+
+// CalculatePacksWithOptions calculates the optimal packs for an order and resolves ties
+// between equally good packings with the configured policy instead of the DP loop order,
+// so identical inputs always give identical results.
+// Orders within the safety threshold run the exact DP over every total.
+// Larger orders ship the bulk in largest packs first, every optimal packing holds them
+// (see CalculatePacksBig), and run the exact DP on the remainder. When the pack sizes are too
+// fine-grained to bound the bulk (ErrFineGrained), the largest packs ship the order down to what
+// the DP covers and the result is the best packing holding them.
+func CalculatePacksWithOptions(itemsOrdered int, availablePackSizes []int, options Options) (PackResult, error) {
+	return calculatePacksWithOptions(itemsOrdered, availablePackSizes, options, nil)
+}
+
+// SelectAlgorithm returns the algorithm CalculatePacksBig runs for an order, without running it:
+// AlgorithmExact or AlgorithmBulk for orders CalculatePacksWithOptions calculates, AlgorithmBig beyond
+func SelectAlgorithm(itemsOrdered *big.Int, availablePackSizes []int, options Options) (string, error) {
+	if itemsOrdered == nil || itemsOrdered.Sign() <= 0 {
+		return "", fmt.Errorf("items ordered must be positive")
+	}
+	if itemsOrdered.Cmp(big.NewInt(math.MaxInt)) > 0 {
+		return AlgorithmBig, nil
+	}
+
+	packSizes, err := preparePackSizes(int(itemsOrdered.Int64()), availablePackSizes)
+	if err != nil {
+		return "", err
+	}
+	p, err := newIntProblem(int(itemsOrdered.Int64()), packSizes, options)
+	if errors.Is(err, ErrOverflow) {
+		return AlgorithmBig, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if p.bulk.Sign() > 0 {
+		return AlgorithmBulk, nil
+	}
+	return AlgorithmExact, nil
+}
+
+// calculatePacksWithOptions is CalculatePacksWithOptions recording its steps in tr
+func calculatePacksWithOptions(itemsOrdered int, availablePackSizes []int, options Options, tr *tracer) (PackResult, error) {
+	packSizes, err := preparePackSizes(itemsOrdered, availablePackSizes)
+	if err != nil {
+		return PackResult{}, err
+	}
+
+	if err := options.validate(); err != nil {
+		return PackResult{}, err
+	}
+
+	largestPack := packSizes[0]
+	p, err := newIntProblem(itemsOrdered, packSizes, options)
+	if err != nil {
+		return PackResult{}, err
+	}
+	fixed, fixedItems := p.fixedPacks()
+	bulk := int(p.bulk.Int64())
+
+	if bulk > 0 {
+		tr.algorithm(AlgorithmBulk, fmt.Sprintf("%d items is above the safety threshold of %d items, "+
+			"the bulk of the order is shipped in %d packs and the exact DP runs on the remainder",
+			itemsOrdered, safetyThreshold, largestPack))
+	} else {
+		tr.algorithm(AlgorithmExact, fmt.Sprintf("%d items is within the safety threshold of %d items, "+
+			"the exact DP covers every total up to %d", itemsOrdered, safetyThreshold, fixedItems+p.limit(options)-1))
+	}
+	if len(p.fixed) > 0 {
+		tr.step(PhaseLimits, 0, 0, p.target+bulk*largestPack, "Limits: %s, %d items left",
+			options.describeLimits(), p.target+bulk*largestPack)
+	}
+	switch {
+	case bulk > 0 && p.bounded:
+		tr.step(PhaseBulk, largestPack, bulk, p.target, "Bulk: %d × %d packs bring the order within the exact DP, "+
+			"the pack sizes are too fine-grained to prove them part of every optimal packing, %d items left for the DP",
+			bulk, largestPack, p.target)
+	case bulk > 0:
+		tr.step(PhaseBulk, largestPack, bulk, p.target,
+			"Bulk: %d × %d packs are part of every optimal packing, %d items left for the DP", bulk, largestPack, p.target)
+	}
+
+	table := newSolver(p, options).solve(p.limit(options))
+
+	total := table.bestTotal(p, options)
+	if total == unreachable {
+		// newProblem rules out capacities below the target, so this only guards the invariant
+		return PackResult{}, fmt.Errorf("%w: no total of at least %d items can be packed", ErrInfeasible, itemsOrdered)
+	}
+	switch {
+	case options.underShip():
+		tr.step(PhaseDP, 0, 0, total, "Rule 2 (under-ship): %d is the largest total of at most %d items that can be packed exactly, "+
+			"%d items are backordered", total+fixedItems, itemsOrdered, itemsOrdered-total-fixedItems)
+	case p.tolerance > 0:
+		smallest := table.smallestTotal(p.target)
+		tr.step(PhaseDP, 0, 0, total, "Rule 2 with a tolerance of %s: totals from %d to %d items compete on the other rules first, "+
+			"%d is the best of them", options.Tolerance, smallest+fixedItems, max(smallest, p.target+p.tolerance)+fixedItems,
+			total+fixedItems)
+	default:
+		tr.step(PhaseDP, 0, 0, total,
+			"Rule 2: %d is the smallest total of at least %d items that can be packed exactly", total+fixedItems, itemsOrdered)
+	}
+	best := table.costs[total]
+	for _, count := range fixed {
+		best = best.add(count, 1)
+	}
+	tr.step(PhaseDP, 0, 0, total, "%s", options.describeRules(best, total+fixedItems))
+	tr.step(PhaseTieBreak, 0, 0, total, "Tie-break: %s", options.TieBreak.describe())
+
+	packCounts := table.reconstruct(total, tr)
+	return newPackResult(itemsOrdered, withFixedPacks(packCounts, fixed)), nil
+}
+
+// preparePackSizes validates the order and returns a copy of the pack sizes sorted in descending order
+func preparePackSizes(itemsOrdered int, availablePackSizes []int) ([]int, error) {
+	if itemsOrdered <= 0 {
+		return nil, fmt.Errorf("items ordered must be positive")
+	}
+
+	if len(availablePackSizes) == 0 {
+		return nil, fmt.Errorf("no pack sizes available")
+	}
+
+	packSizes := append([]int(nil), availablePackSizes...)
+	sort.Sort(sort.Reverse(sort.IntSlice(packSizes)))
+	if packSizes[len(packSizes)-1] <= 0 {
+		return nil, fmt.Errorf("pack sizes must be positive")
+	}
+
+	return packSizes, nil
+}
+
+// describeRules returns a human-readable description of how rule 3 and the distinct rule
+// settled on the best cost of total
+func (o Options) describeRules(best cost, total int) string {
+	switch o.DistinctRule {
+	case DistinctRuleBeforePacks:
+		return fmt.Sprintf("Distinct sizes before rule 3: %d pack sizes is the fewest that make %d items, "+
+			"in %d packs at the fewest", best.distinct, total, best.packs)
+	case DistinctRuleAfterPacks:
+		return fmt.Sprintf("Rule 3: %d packs is the fewest that make %d items, "+
+			"then %d pack sizes is the fewest among them", best.packs, total, best.distinct)
+	default:
+		return fmt.Sprintf("Rule 3: %d packs is the fewest that make %d items", best.packs, total)
+	}
+}
+
+// describe returns a human-readable description of the tie-break policy
+func (t TieBreak) describe() string {
+	switch t {
+	case TieBreakSmallerPacks:
+		return "among equally good packings the one with the fewest large packs wins"
+	case TieBreakFewerSizes:
+		return "among equally good packings the one with the fewest distinct pack sizes wins, then the one with the most large packs"
+	default:
+		return "among equally good packings the one with the most large packs wins"
+	}
+}
+
+// cost is what the business rules minimise for a packing of a given total
+type cost struct {
+	packs    int // Number of packs, unreachable if the total cannot be packed exactly
+	distinct int // Number of distinct pack sizes
+}
+
+// add returns the cost with extra packs and distinct sizes
+func (c cost) add(packs int, distinct int) cost {
+	return cost{packs: c.packs + packs, distinct: c.distinct + distinct}
+}
+
+// solver runs the exact DP for CalculatePacksWithOptions
+type solver struct {
+	packSizes []int  // Sorted in ascending order, one DP stage per size
+	caps      []int  // Most packs of each size, Unlimited if not capped
+	present   []bool // The size is already in every packing, more packs of it add no distinct size
+	options   Options
+}
+
+// newSolver creates a solver for the target of a problem
+func newSolver(p problem, options Options) *solver {
+	s := &solver{options: options}
+	for index := len(p.packSizes) - 1; index >= 0; index-- {
+		s.packSizes = append(s.packSizes, p.packSizes[index])
+		s.caps = append(s.caps, p.caps[index])
+		s.present = append(s.present, p.present(index))
+	}
+	return s
+}
+
+// less compares two reachable costs in rule order
+func (s *solver) less(a, b cost) bool {
+	return s.options.lessCost(a, b)
+}
+
+// lessCost compares two reachable costs on rule 3 and the distinct rule,
+// then on distinct pack sizes for the fewer sizes tie-break
+func (o Options) lessCost(a, b cost) bool {
+	if o.distinctFirst() && a.distinct != b.distinct {
+		return a.distinct < b.distinct
+	}
+	if a.packs != b.packs {
+		return a.packs < b.packs
+	}
+	if o.countsDistinct() {
+		return a.distinct < b.distinct
+	}
+	return false
+}
+
+// lessByRules compares two reachable costs on rule 3 and the distinct rule only
+func (o Options) lessByRules(a, b cost) bool {
+	if o.distinctFirst() && a.distinct != b.distinct {
+		return a.distinct < b.distinct
+	}
+	if a.packs != b.packs {
+		return a.packs < b.packs
+	}
+	if o.distinctIsRule() {
+		return a.distinct < b.distinct
+	}
+	return false
+}
+
+// better reports whether candidate a beats b, where a holds more packs of the current size than b.
+// When they tie on the business rules, a wins if the policy prefers more packs.
+func (s *solver) better(a, b cost) bool {
+	if a.packs == unreachable {
+		return false
+	}
+	if b.packs == unreachable {
+		return true
+	}
+	if s.less(a, b) {
+		return true
+	}
+	if s.less(b, a) {
+		return false
+	}
+	return s.options.preferMore()
+}
+
+// dpTable is the exact DP over every total below a limit
+type dpTable struct {
+	costs  []cost  // Best cost of each total, packs is unreachable if it cannot be packed exactly
+	stages []stage // Decisions per pack size, smallest size first
+}
+
+// stage records the decisions of the DP for one pack size
+type stage struct {
+	size   int
+	counts []int32 // Packs of this size in the best packing of each total
+}
+
+// solve builds the DP table for totals below limit.
+// Sizes are added one stage at a time, smallest first. A stage picks, for every total v, the best
+// count c of its size on top of the previous stage's packing of v - c×size, with c up to the size's cap.
+// Totals with the same remainder modulo the size form a chain, and a monotone queue over each chain keeps
+// the best candidate of the allowed window, so a stage takes linear time however large the cap.
+// Because the largest size is decided last, ties on the business rules are resolved by the largest pack first.
+func (s *solver) solve(limit int) *dpTable {
+	previous := make([]cost, limit)
+	for i := 1; i < limit; i++ {
+		previous[i] = cost{packs: unreachable}
+	}
+
+	table := &dpTable{}
+	window := make([]int, 0, limit)
+	for index, size := range s.packSizes {
+		distinct := 1
+		if s.present[index] {
+			distinct = 0
+		}
+		maxCount := s.caps[index]
+
+		current := make([]cost, limit)
+		st := stage{size: size, counts: make([]int32, limit)}
+
+		for residue := 0; residue < size && residue < limit; residue++ {
+			// candidate returns the cost of total residue + j×size with the packs of the chain position i
+			// from the previous stage and j - i packs of this size
+			candidate := func(i int, j int) cost {
+				return previous[residue+i*size].add(j-i, distinct)
+			}
+
+			// window holds chain positions from best to worst, window[head] is the best
+			window, head := window[:0], 0
+			for j, v := 0, residue; v < limit; j, v = j+1, v+size {
+				if j > 0 && previous[v-size].packs != unreachable {
+					// Positions in the window hold more packs of this size than j - 1,
+					// drop the ones that no longer beat it
+					for len(window) > head && !s.better(candidate(window[len(window)-1], j), candidate(j-1, j)) {
+						window = window[:len(window)-1]
+					}
+					window = append(window, j-1)
+				}
+				for maxCount != Unlimited && len(window) > head && window[head] < j-maxCount {
+					head++
+				}
+
+				current[v] = previous[v]
+				if len(window) > head {
+					if best := candidate(window[head], j); s.better(best, current[v]) {
+						current[v] = best
+						st.counts[v] = int32(j - window[head])
+					}
+				}
+			}
+		}
+
+		table.stages = append(table.stages, st)
+		previous = current
+	}
+
+	table.costs = previous
+	return table
+}
+
+// smallestTotal returns the smallest total of at least target items that can be packed exactly (rule 2),
+// or unreachable. A table that reaches target + largest pack holds such a total unless the caps rule it out.
+func (t *dpTable) smallestTotal(target int) int {
+	for total := target; total < len(t.costs); total++ {
+		if t.costs[total].packs != unreachable {
+			return total
+		}
+	}
+	return unreachable
+}
+
+// largestTotal returns the largest total of at most target items that can be packed exactly,
+// rule 2 when under-shipping. The empty packing always qualifies.
+func (t *dpTable) largestTotal(target int) int {
+	for total := target; total > 0; total-- {
+		if t.costs[total].packs != unreachable {
+			return total
+		}
+	}
+	return 0
+}
+
+// bestTotal returns the total rule 2 picks for the target of a problem, or unreachable.
+// With an excess tolerance the totals from the smallest one up to the allowance compete on
+// rule 3 and the distinct rule, ties go to the fewest items before the tie-break policy.
+func (t *dpTable) bestTotal(p problem, options Options) int {
+	if options.underShip() {
+		return t.largestTotal(p.target)
+	}
+
+	best := t.smallestTotal(p.target)
+	if best == unreachable {
+		return unreachable
+	}
+	for total := best + 1; total <= p.target+p.allowance; total++ {
+		if t.costs[total].packs != unreachable && options.lessByRules(t.costs[total], t.costs[best]) {
+			best = total
+		}
+	}
+	return best
+}
+
+// reconstruct returns the pack counts of the best packing of total, largest packs first
+func (t *dpTable) reconstruct(total int, tr *tracer) map[int]int {
+	packCounts := make(map[int]int)
+	current := total
+	for index := len(t.stages) - 1; index >= 0; index-- {
+		st := t.stages[index]
+		count := int(st.counts[current])
+		if count == 0 {
+			continue
+		}
+
+		current -= count * st.size
+		packCounts[st.size] = count
+		tr.step(PhaseReconstruct, st.size, count, current, "Reconstruct: %d × %d packs, %d items left", count, st.size, current)
+	}
+	return packCounts
+}
@@ -0,0 +1,90 @@
+package calculator
+
+import (
+	"fmt"
+)
+
+// MaxSuggestionItems is the largest order SuggestQuantities looks around, the DP table covers every total near it
+const MaxSuggestionItems = safetyThreshold
+
+// maxSuggestionChecks caps the candidate quantities SuggestQuantities calculates in each direction,
+// candidates only fail the check when the options rule out the exact packing the table finds
+const maxSuggestionChecks = 50
+
+// Suggestion is an order quantity that packs more efficiently than the one ordered
+type Suggestion struct {
+	ItemsOrdered int
+	Result       PackResult
+	ExcessSaved  int // Excess items of the order avoided, the suggestion has none
+	PacksSaved   int // Packs saved over the order, negative if the suggestion needs more
+}
+
+// Suggestions are the nearest quantities to an order that pack without excess
+type Suggestions struct {
+	Result          PackResult  // Packs of the order as it is
+	Below           *Suggestion // Nearest smaller quantity without excess, nil if there is none
+	Above           *Suggestion // Nearest larger quantity without excess, nil if there is none
+	FewerPacksBelow *Suggestion // Nearest smaller quantity without excess in fewer packs, nil if there is none
+	FewerPacksAbove *Suggestion // Nearest larger quantity without excess in fewer packs, nil if there is none
+}
+
+// SuggestQuantities finds the quantities nearest to an order that pack exactly, with no excess or short items,
+// below and above it, and the nearest that also take fewer packs. Suggestions are calculated with the options
+// like any order. A larger quantity in fewer packs holds at most one largest pack less than the order has packs,
+// so a DP table up to there, or a smallest pack above the order, covers every candidate.
+func SuggestQuantities(itemsOrdered int, availablePackSizes []int, options Options) (Suggestions, error) {
+	if itemsOrdered > MaxSuggestionItems {
+		return Suggestions{}, fmt.Errorf("suggestions are only available for orders of up to %d items", MaxSuggestionItems)
+	}
+
+	result, err := CalculatePacksWithOptions(itemsOrdered, availablePackSizes, options)
+	if err != nil {
+		return Suggestions{}, err
+	}
+	packSizes, err := preparePackSizes(itemsOrdered, availablePackSizes)
+	if err != nil {
+		return Suggestions{}, err
+	}
+
+	smallest, largest := packSizes[len(packSizes)-1], packSizes[0]
+	limit := max(itemsOrdered+smallest, (result.TotalPacks-1)*largest) + 1
+	packs := packsTable(limit, packSizes)
+
+	suggestions := Suggestions{Result: result}
+
+	// suggest calculates a candidate quantity, nil unless it packs exactly in fewer than maxPacks packs
+	suggest := func(items int, maxPacks int) *Suggestion {
+		candidate, err := CalculatePacksWithOptions(items, packSizes, options)
+		if err != nil || candidate.ExcessItems != 0 || candidate.ShortItems != 0 || candidate.TotalPacks >= maxPacks {
+			return nil
+		}
+		return &Suggestion{
+			ItemsOrdered: items,
+			Result:       candidate,
+			ExcessSaved:  result.ExcessItems,
+			PacksSaved:   result.TotalPacks - candidate.TotalPacks,
+		}
+	}
+
+	// search walks the totals from first by step and returns the first exact one in fewer than maxPacks packs
+	search := func(first, step, maxPacks int) *Suggestion {
+		checks := 0
+		for items := first; items > 0 && items < limit && checks < maxSuggestionChecks; items += step {
+			if packs[items] == unreachable || packs[items] >= maxPacks {
+				continue
+			}
+			checks++
+			if suggestion := suggest(items, maxPacks); suggestion != nil {
+				return suggestion
+			}
+		}
+		return nil
+	}
+
+	anyPacks := limit
+	suggestions.Below = search(itemsOrdered-1, -1, anyPacks)
+	suggestions.Above = search(itemsOrdered+1, 1, anyPacks)
+	suggestions.FewerPacksBelow = search(itemsOrdered-1, -1, result.TotalPacks)
+	suggestions.FewerPacksAbove = search(itemsOrdered+1, 1, result.TotalPacks)
+	return suggestions, nil
+}
@@ -0,0 +1,82 @@
+package calculator
+
+import (
+	"testing"
+)
+
+func TestSuggestQuantities(t *testing.T) {
+	packSizes := []int{250, 500, 1000, 2000, 5000}
+
+	testCases := []struct {
+		itemsOrdered    int
+		below           int // 0 for no suggestion
+		above           int
+		fewerPacksBelow int
+		fewerPacksAbove int
+	}{
+		{itemsOrdered: 501, below: 500, above: 750, fewerPacksBelow: 500, fewerPacksAbove: 1000},
+		{itemsOrdered: 12001, below: 12000, above: 12250, fewerPacksBelow: 12000, fewerPacksAbove: 15000},
+		{itemsOrdered: 250, above: 500},
+		{itemsOrdered: 1, above: 250},
+		{itemsOrdered: 750, below: 500, above: 1000, fewerPacksBelow: 500, fewerPacksAbove: 1000},
+	}
+
+	for _, tc := range testCases {
+		suggestions, err := SuggestQuantities(tc.itemsOrdered, packSizes, DefaultOptions())
+		if err != nil {
+			t.Fatalf("%d items: unexpected error: %v", tc.itemsOrdered, err)
+		}
+
+		for _, check := range []struct {
+			name       string
+			suggestion *Suggestion
+			expected   int
+		}{
+			{"below", suggestions.Below, tc.below},
+			{"above", suggestions.Above, tc.above},
+			{"fewer packs below", suggestions.FewerPacksBelow, tc.fewerPacksBelow},
+			{"fewer packs above", suggestions.FewerPacksAbove, tc.fewerPacksAbove},
+		} {
+			got := 0
+			if check.suggestion != nil {
+				got = check.suggestion.ItemsOrdered
+				if check.suggestion.Result.ExcessItems != 0 || check.suggestion.Result.TotalItems != got {
+					t.Errorf("%d items: expected %s suggestion to pack exactly, got %v", tc.itemsOrdered, check.name, check.suggestion.Result)
+				}
+				if check.suggestion.ExcessSaved != suggestions.Result.ExcessItems {
+					t.Errorf("%d items: expected %s suggestion to save %d excess items, got %d",
+						tc.itemsOrdered, check.name, suggestions.Result.ExcessItems, check.suggestion.ExcessSaved)
+				}
+			}
+			if got != check.expected {
+				t.Errorf("%d items: expected %s suggestion %d, got %d", tc.itemsOrdered, check.name, check.expected, got)
+			}
+		}
+		if s := suggestions.FewerPacksAbove; s != nil && s.PacksSaved <= 0 {
+			t.Errorf("%d items: expected fewer packs above to save packs, got %d", tc.itemsOrdered, s.PacksSaved)
+		}
+	}
+}
+
+func TestSuggestQuantitiesWithLimits(t *testing.T) {
+	// 500 packs are off limits, so 1000 is the nearest exact quantity above 501 rather than 750
+	options := DefaultOptions()
+	options.Limits = map[int]PackLimit{500: {Min: 0, Max: 0}}
+
+	suggestions, err := SuggestQuantities(501, []int{250, 500, 1000}, options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if suggestions.Above == nil || suggestions.Above.ItemsOrdered != 750 || suggestions.Above.Result.PackCounts[500] != 0 {
+		t.Errorf("Expected 750 in 250 packs above, got %+v", suggestions.Above)
+	}
+	if suggestions.Below == nil || suggestions.Below.ItemsOrdered != 500 || suggestions.Below.Result.PackCounts[250] != 2 {
+		t.Errorf("Expected 500 in 250 packs below, got %+v", suggestions.Below)
+	}
+}
+
+func TestSuggestQuantitiesTooLarge(t *testing.T) {
+	if _, err := SuggestQuantities(MaxSuggestionItems+1, []int{250}, DefaultOptions()); err == nil {
+		t.Errorf("Expected error above %d items", MaxSuggestionItems)
+	}
+}
@@ -0,0 +1,137 @@
+package calculator
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// tieBreakCases are orders with several packings tied on rules 2 and 3
+var tieBreakCases = []struct {
+	packSizes []int
+	orders    []int
+}{
+	{packSizes: []int{250, 500, 1000, 2000, 5000}, orders: []int{1, 251, 501, 750, 1750, 12001, 2000001}},
+	{packSizes: []int{250, 500, 750, 1000}, orders: []int{1250, 1500, 2250, 3000}},
+	{packSizes: []int{1, 2, 3}, orders: []int{4, 5, 8, 10}},
+	{packSizes: []int{23, 31, 53}, orders: []int{263, 500000, 1500001}},
+}
+
+// optionCombinations returns every combination of tie-break policy and distinct rule
+func optionCombinations() []Options {
+	var combinations []Options
+	for _, distinctRule := range DistinctRules {
+		for _, tieBreak := range TieBreaks {
+			combinations = append(combinations, Options{TieBreak: tieBreak, DistinctRule: distinctRule})
+		}
+	}
+	return combinations
+}
+
+// formatPackCounts formats a packing with pack sizes sorted in descending order
+func formatPackCounts(packCounts map[int]int) string {
+	sizes := make([]int, 0, len(packCounts))
+	for size := range packCounts {
+		sizes = append(sizes, size)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+
+	parts := make([]string, 0, len(sizes))
+	for _, size := range sizes {
+		parts = append(parts, fmt.Sprintf("%dx%d", packCounts[size], size))
+	}
+	return strings.Join(parts, " ")
+}
+
+// TestTieBreakGolden pins the packing chosen by every tie-break policy and distinct rule.
+// Run with -update to regenerate testdata/tie_break.golden after an intended change.
+func TestTieBreakGolden(t *testing.T) {
+	var output strings.Builder
+	for _, options := range optionCombinations() {
+		for _, tc := range tieBreakCases {
+			for _, itemsOrdered := range tc.orders {
+				result, err := CalculatePacksWithOptions(itemsOrdered, tc.packSizes, options)
+				if err != nil {
+					fmt.Fprintf(&output, "%s %s %v %d: error: %v\n", options.TieBreak, options.DistinctRule,
+						tc.packSizes, itemsOrdered, err)
+					continue
+				}
+				fmt.Fprintf(&output, "%s %s %v %d: %s (%d packs, %d items, %d sizes)\n", options.TieBreak, options.DistinctRule,
+					tc.packSizes, itemsOrdered, formatPackCounts(result.PackCounts), result.TotalPacks, result.TotalItems,
+					result.DistinctPackTypes)
+			}
+		}
+	}
+
+	golden := filepath.Join("testdata", "tie_break.golden")
+	if *update {
+		if err := os.WriteFile(golden, []byte(output.String()), 0644); err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+	}
+
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	if output.String() != string(expected) {
+		t.Errorf("Results differ from %s, run with -update if the change is intended\ngot:\n%s", golden, output.String())
+	}
+}
+
+// TestTieBreakMatchesAlternatives checks the chosen packing against every tied optimal packing
+func TestTieBreakMatchesAlternatives(t *testing.T) {
+	for _, options := range optionCombinations() {
+		for _, tc := range tieBreakCases {
+			for _, itemsOrdered := range tc.orders {
+				result, err := CalculatePacksWithOptions(itemsOrdered, tc.packSizes, options)
+				if err != nil {
+					// Pinned by the golden file
+					continue
+				}
+				alternatives, err := CalculateAlternatives(itemsOrdered, tc.packSizes, 0, options)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				if result.DistinctPackTypes != distinctSizes(result.PackCounts) {
+					t.Errorf("%+v, packs %v, %d items: expected %d distinct pack types, got %d",
+						options, tc.packSizes, itemsOrdered, distinctSizes(result.PackCounts), result.DistinctPackTypes)
+				}
+
+				first := alternatives.Optimal[0]
+				if formatPackCounts(first.PackCounts) != formatPackCounts(result.PackCounts) {
+					t.Errorf("%+v, packs %v, %d items: expected %v as the first optimal packing, got %v",
+						options, tc.packSizes, itemsOrdered, result, first)
+				}
+				for _, packing := range alternatives.Optimal {
+					if options.Less(packing, result) {
+						t.Errorf("%+v, packs %v, %d items: %v beats the chosen %v",
+							options, tc.packSizes, itemsOrdered, packing, result)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestParseTieBreak(t *testing.T) {
+	if tieBreak, err := ParseTieBreak(""); err != nil || tieBreak != TieBreakLargerPacks {
+		t.Errorf("Expected the default policy for an empty name, got %q, %v", tieBreak, err)
+	}
+	if tieBreak, err := ParseTieBreak("fewer-sizes"); err != nil || tieBreak != TieBreakFewerSizes {
+		t.Errorf("Expected %q, got %q, %v", TieBreakFewerSizes, tieBreak, err)
+	}
+	if _, err := ParseTieBreak("random"); err == nil {
+		t.Errorf("Expected error for an unknown policy")
+	}
+	if _, err := CalculatePacksWithOptions(10, []int{3}, Options{TieBreak: "random"}); err == nil {
+		t.Errorf("Expected error for unknown options")
+	}
+}
@@ -0,0 +1,103 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidTolerance is returned for a tolerance that is negative, ambiguous or larger than an order supports
+var ErrInvalidTolerance = errors.New("invalid tolerance")
+
+// Tolerance is how many excess items a customer accepts at no charge, either absolute or as a percentage
+// of the order. Packings within the tolerance compete on the other rules before their number of items,
+// so a few more items can save packs. The zero Tolerance is the strict rule 2.
+type Tolerance struct {
+	Items   int     // Excess items accepted, 0 for none
+	Percent float64 // Excess items accepted as a percentage of the order, 0 for none
+}
+
+// ParseTolerance parses a tolerance such as "50" (items) or "5%" (of the order), an empty string is no tolerance
+func ParseTolerance(value string) (Tolerance, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Tolerance{}, nil
+	}
+
+	var tolerance Tolerance
+	if percent, ok := strings.CutSuffix(value, "%"); ok {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+		if err != nil {
+			return Tolerance{}, fmt.Errorf("invalid tolerance %q, expected items such as 50 or a percentage such as 5%%", value)
+		}
+		tolerance.Percent = parsed
+	} else {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return Tolerance{}, fmt.Errorf("invalid tolerance %q, expected items such as 50 or a percentage such as 5%%", value)
+		}
+		tolerance.Items = parsed
+	}
+
+	if err := tolerance.validate(); err != nil {
+		return Tolerance{}, err
+	}
+	return tolerance, nil
+}
+
+// String formats the tolerance the way ParseTolerance reads it
+func (t Tolerance) String() string {
+	if t.Percent != 0 {
+		return strconv.FormatFloat(t.Percent, 'f', -1, 64) + "%"
+	}
+	return strconv.Itoa(t.Items)
+}
+
+// IsZero reports whether the tolerance accepts no excess beyond rule 2
+func (t Tolerance) IsZero() bool {
+	return t.Items == 0 && t.Percent == 0
+}
+
+// validate checks that the tolerance is either absolute or a percentage and not negative
+func (t Tolerance) validate() error {
+	if t.Items < 0 {
+		return fmt.Errorf("%w: tolerance must not be negative, got %d items", ErrInvalidTolerance, t.Items)
+	}
+	if math.IsNaN(t.Percent) || math.IsInf(t.Percent, 0) || t.Percent < 0 {
+		return fmt.Errorf("%w: tolerance must be a non-negative percentage, got %v", ErrInvalidTolerance, t.Percent)
+	}
+	if t.Items != 0 && t.Percent != 0 {
+		return fmt.Errorf("%w: tolerance must be given in items or as a percentage, not both", ErrInvalidTolerance)
+	}
+	return nil
+}
+
+// allowance returns the excess items the tolerance accepts for an order, percentages are rounded down
+func (t Tolerance) allowance(itemsOrdered *big.Int) *big.Int {
+	if t.Percent == 0 {
+		return big.NewInt(int64(t.Items))
+	}
+
+	// The shortest decimal form of the percentage, so 0.1% is exactly one item in a thousand
+	percent, _ := new(big.Rat).SetString(strconv.FormatFloat(t.Percent, 'f', -1, 64))
+	allowance := new(big.Rat).SetInt(itemsOrdered)
+	allowance.Mul(allowance, percent)
+	allowance.Quo(allowance, big.NewRat(100, 1))
+	return new(big.Int).Quo(allowance.Num(), allowance.Denom())
+}
+
+// withinTolerance reports whether a packing ships no more excess items than a non-zero tolerance accepts,
+// so it competes on rule 3 before its number of items
+func (o Options) withinTolerance(result PackResult) bool {
+	if o.Tolerance.IsZero() {
+		return false
+	}
+	if t := o.Tolerance; t.Percent == 0 {
+		return result.ExcessItems <= t.Items
+	}
+	itemsOrdered := result.TotalItems - result.ExcessItems + result.ShortItems
+	return o.Tolerance.allowance(big.NewInt(int64(itemsOrdered))).Cmp(big.NewInt(int64(result.ExcessItems))) >= 0
+}
@@ -0,0 +1,201 @@
+package calculator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTolerance(t *testing.T) {
+	testCases := []struct {
+		value       string
+		expected    Tolerance
+		expectError bool
+	}{
+		{value: "", expected: Tolerance{}},
+		{value: "0", expected: Tolerance{}},
+		{value: "50", expected: Tolerance{Items: 50}},
+		{value: "5%", expected: Tolerance{Percent: 5}},
+		{value: " 2.5 % ", expected: Tolerance{Percent: 2.5}},
+		{value: "-1", expectError: true},
+		{value: "-5%", expectError: true},
+		{value: "five", expectError: true},
+		{value: "5%%", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		tolerance, err := ParseTolerance(tc.value)
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("%q: expected error but got none", tc.value)
+			}
+			continue
+		}
+		if err != nil || tolerance != tc.expected {
+			t.Errorf("%q: expected %+v, got %+v, %v", tc.value, tc.expected, tolerance, err)
+		}
+	}
+
+	options := DefaultOptions()
+	options.Tolerance = Tolerance{Items: 10, Percent: 5}
+	if _, err := CalculatePacksWithOptions(1001, []int{250}, options); !errors.Is(err, ErrInvalidTolerance) {
+		t.Errorf("Expected %v for a tolerance in items and percent, got %v", ErrInvalidTolerance, err)
+	}
+	options.Tolerance = Tolerance{Items: 10}
+	options.Fulfilment = FulfilmentUnderShip
+	if _, err := CalculatePacksWithOptions(1001, []int{250}, options); !errors.Is(err, ErrInvalidTolerance) {
+		t.Errorf("Expected %v for a tolerance when under-shipping, got %v", ErrInvalidTolerance, err)
+	}
+
+	// The DP covers the whole allowance when the largest pack is capped
+	options = DefaultOptions()
+	options.Tolerance = Tolerance{Percent: 50}
+	options.Limits = map[int]PackLimit{500: {Min: 0, Max: 5000}}
+	if _, err := CalculatePacksWithOptions(900000, []int{250, 500}, options); !errors.Is(err, ErrInvalidTolerance) {
+		t.Errorf("Expected %v for a tolerance too large for the order, got %v", ErrInvalidTolerance, err)
+	}
+}
+
+func TestCalculatePacksWithTolerance(t *testing.T) {
+	standardPacks := []int{250, 500, 1000, 2000, 5000}
+
+	testCases := []struct {
+		name           string
+		itemsOrdered   int
+		packSizes      []int
+		tolerance      Tolerance
+		limits         map[int]PackLimit
+		expectedCounts string
+	}{
+		{name: "No tolerance", itemsOrdered: 1001, packSizes: standardPacks, expectedCounts: "1x1000 1x250"},
+		{
+			name:           "Nothing within the tolerance falls back to rule 2",
+			itemsOrdered:   1001,
+			packSizes:      standardPacks,
+			tolerance:      Tolerance{Percent: 5},
+			expectedCounts: "1x1000 1x250",
+		},
+		{
+			name:           "One pack instead of two",
+			itemsOrdered:   1001,
+			packSizes:      standardPacks,
+			tolerance:      Tolerance{Items: 999},
+			expectedCounts: "1x2000",
+		},
+		{
+			name:           "Fewest packs then fewest items",
+			itemsOrdered:   1751,
+			packSizes:      standardPacks,
+			tolerance:      Tolerance{Percent: 15},
+			expectedCounts: "1x2000",
+		},
+		{
+			name:           "Percentage of a large order",
+			itemsOrdered:   9001,
+			packSizes:      []int{1000, 3000, 5000},
+			tolerance:      Tolerance{Percent: 10},
+			expectedCounts: "2x5000",
+		},
+		{
+			name:           "Tolerance with a maximum on the largest pack",
+			itemsOrdered:   9001,
+			packSizes:      []int{1000, 3000, 5000},
+			tolerance:      Tolerance{Percent: 25},
+			limits:         map[int]PackLimit{5000: {Min: 0, Max: 1}},
+			expectedCounts: "1x5000 2x3000",
+		},
+		{
+			name:           "Above the safety threshold",
+			itemsOrdered:   2004001,
+			packSizes:      standardPacks,
+			tolerance:      Tolerance{Percent: 1},
+			expectedCounts: "401x5000",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options := DefaultOptions()
+			options.Tolerance = tc.tolerance
+			options.Limits = tc.limits
+
+			result, err := CalculatePacksWithOptions(tc.itemsOrdered, tc.packSizes, options)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := formatPackCounts(result.PackCounts); got != tc.expectedCounts {
+				t.Errorf("Expected %s, got %s", tc.expectedCounts, got)
+			}
+		})
+	}
+}
+
+// TestToleranceMatchesBruteForce checks the tolerance against trying every packing,
+// and the ranking of the alternatives against Less
+func TestToleranceMatchesBruteForce(t *testing.T) {
+	packSizes := []int{7, 5, 3}
+	tolerances := []Tolerance{{Items: 2}, {Items: 9}, {Percent: 30}}
+	limitSets := []map[int]PackLimit{nil, {7: {Min: 0, Max: 1}}, {3: {Min: 1, Max: Unlimited}}}
+
+	for _, tolerance := range tolerances {
+		for _, limits := range limitSets {
+			for _, options := range optionCombinations() {
+				options.Tolerance = tolerance
+				options.Limits = limits
+				for itemsOrdered := 1; itemsOrdered <= 30; itemsOrdered++ {
+					expected, _ := bestWithinLimits(itemsOrdered, packSizes, options)
+					result, err := CalculatePacksWithOptions(itemsOrdered, packSizes, options)
+					if err != nil {
+						t.Fatalf("%+v, %d items: unexpected error: %v", options, itemsOrdered, err)
+					}
+					if formatPackCounts(result.PackCounts) != formatPackCounts(expected.PackCounts) {
+						t.Errorf("%+v, %d items: expected %v, got %v", options, itemsOrdered, expected, result)
+					}
+
+					alternatives, err := CalculateAlternatives(itemsOrdered, packSizes, 5, options)
+					if err != nil {
+						t.Fatalf("%+v, %d items: unexpected error: %v", options, itemsOrdered, err)
+					}
+					ranked := append(append([]PackResult(nil), alternatives.Optimal...), alternatives.NextBest...)
+					if formatPackCounts(ranked[0].PackCounts) != formatPackCounts(expected.PackCounts) {
+						t.Errorf("%+v, %d items: expected %v as the first optimal packing, got %v",
+							options, itemsOrdered, expected, ranked[0])
+					}
+					for i := 1; i < len(ranked); i++ {
+						if options.Less(ranked[i], ranked[i-1]) {
+							t.Errorf("%+v, %d items: %v is ranked after %v", options, itemsOrdered, ranked[i], ranked[i-1])
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestExplainWithTolerance(t *testing.T) {
+	options := DefaultOptions()
+	options.Tolerance = Tolerance{Items: 999}
+
+	result, explanation, err := ExplainCalculatePacks(1001, []int{250, 500, 1000, 2000, 5000}, options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TotalItems != 2000 {
+		t.Fatalf("Expected 2000 items, got %v", result)
+	}
+
+	found := false
+	for _, alternative := range explanation.RejectedByRule3 {
+		if alternative.TotalItems == 1250 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected 1250 items in 2 packs rejected by rule 3 within the tolerance, got %+v", explanation.RejectedByRule3)
+	}
+
+	options.Tolerance = Tolerance{Items: 10}
+	options.Limits = map[int]PackLimit{5000: {Min: 0, Max: 0}, 2000: {Min: 0, Max: 0}}
+	if _, err := CalculatePacksWithOptions(1001, []int{250, 500, 1000, 2000, 5000}, options); errors.Is(err, ErrInfeasible) {
+		t.Errorf("Expected the remaining unlimited sizes to pack the order, got %v", err)
+	}
+}